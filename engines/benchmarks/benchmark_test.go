@@ -17,6 +17,10 @@
 //   - Starlark: Python-like configuration language
 //   - Extism: WebAssembly module execution (not benchmarked by default)
 //
+// 4. Extism Instance Pool:
+//   - NewInstancePerEval: Instantiates the WASM module for each execution
+//   - InstancePool: Reuses warm instances from the executable's pool
+//
 // To run these benchmarks, use the benchmark.sh script:
 //
 //	./benchmark.sh [pattern] [iterations]
@@ -28,10 +32,18 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/robbyt/go-polyscript"
+	extismCompiler "github.com/robbyt/go-polyscript/engines/extism/compiler"
+	extismEvaluator "github.com/robbyt/go-polyscript/engines/extism/evaluator"
+	"github.com/robbyt/go-polyscript/engines/extism/wasmdata"
 	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
 )
 
 // quietHandler is a slog.Handler that discards all logs
@@ -274,3 +286,101 @@ message = "Hello, " + name + "!"
 
 	// Extism benchmark would be added here once we have a standard WASM file for testing
 }
+
+// newExtismEvaluator compiles the test WASM module with the compiler options, and returns an
+// evaluator for it along with the compiled executable
+func newExtismEvaluator(
+	b *testing.B,
+	opts ...extismCompiler.FunctionalOption,
+) (*extismEvaluator.Evaluator, *extismCompiler.Executable) {
+	b.Helper()
+
+	opts = append(
+		[]extismCompiler.FunctionalOption{
+			extismCompiler.WithEntryPoint(wasmdata.EntrypointGreet),
+			extismCompiler.WithLogHandler(quietHandler),
+		},
+		opts...,
+	)
+	comp, err := extismCompiler.New(opts...)
+	if err != nil {
+		b.Fatalf("Failed to create Extism compiler: %v", err)
+	}
+
+	ldr, err := loader.NewFromBytes(wasmdata.TestModule)
+	if err != nil {
+		b.Fatalf("Failed to create loader: %v", err)
+	}
+
+	unit, err := script.NewExecutableUnit(
+		quietHandler,
+		"",
+		ldr,
+		comp,
+		data.NewStaticProvider(map[string]any{"input": "World"}),
+	)
+	if err != nil {
+		b.Fatalf("Failed to compile WASM module: %v", err)
+	}
+
+	exe, ok := unit.GetContent().(*extismCompiler.Executable)
+	if !ok {
+		b.Fatalf("Unexpected executable type: %T", unit.GetContent())
+	}
+	b.Cleanup(func() {
+		if err := exe.Close(context.Background()); err != nil {
+			b.Errorf("Failed to close executable: %v", err)
+		}
+	})
+
+	return extismEvaluator.New(quietHandler, unit), exe
+}
+
+// BenchmarkExtismInstancePool compares creating a plugin instance for every evaluation with
+// reusing warm instances from a pool:
+// - NewInstancePerEval: the default, instantiates the WASM module on every call
+// - InstancePool: reuses instances kept by the executable's pool
+// - InstancePoolParallel: concurrent callers sharing a pool
+func BenchmarkExtismInstancePool(b *testing.B) {
+	b.Run("NewInstancePerEval", func(b *testing.B) {
+		evaluator, _ := newExtismEvaluator(b)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := evaluator.Eval(b.Context())
+			if err != nil {
+				b.Fatalf("Failed to evaluate WASM module: %v", err)
+			}
+		}
+	})
+
+	b.Run("InstancePool", func(b *testing.B) {
+		evaluator, _ := newExtismEvaluator(b, extismCompiler.WithInstancePool(1, 1, 0))
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := evaluator.Eval(b.Context())
+			if err != nil {
+				b.Fatalf("Failed to evaluate WASM module: %v", err)
+			}
+		}
+	})
+
+	b.Run("InstancePoolParallel", func(b *testing.B) {
+		evaluator, _ := newExtismEvaluator(
+			b,
+			extismCompiler.WithInstancePool(1, runtime.GOMAXPROCS(0), time.Minute),
+		)
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, err := evaluator.Eval(b.Context())
+				if err != nil {
+					b.Errorf("Failed to evaluate WASM module: %v", err)
+					return
+				}
+			}
+		})
+	})
+}
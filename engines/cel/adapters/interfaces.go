@@ -0,0 +1,7 @@
+package adapters
+
+import celLib "github.com/google/cel-go/cel"
+
+type CELExecutable struct {
+	GetCELByteCode func() celLib.Program
+}
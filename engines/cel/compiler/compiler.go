@@ -0,0 +1,157 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	"github.com/robbyt/go-polyscript/engines/cel/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+// interruptCheckFrequency is how many comprehension iterations run between checks for a
+// cancelled context.
+const interruptCheckFrequency = 100
+
+type Compiler struct {
+	variables  []celLib.EnvOption
+	envOptions []celLib.EnvOption
+	outputType *celLib.Type
+	costLimit  uint64
+	logHandler slog.Handler
+	logger     *slog.Logger
+
+	// evalOptions configure the evaluators of the compiled script
+	evalOptions []platform.EvalOption
+}
+
+// New creates a new CEL-specific Compiler instance with the provided options.
+// Expressions always have access to the ctx variable, a map of string to dyn.
+func New(opts ...FunctionalOption) (*Compiler, error) {
+	// Initialize the compiler with an empty struct
+	c := &Compiler{}
+
+	// Apply defaults
+	c.applyDefaults()
+
+	// Apply all options
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("error applying compiler option: %w", err)
+		}
+	}
+
+	// Validate the configuration
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid compiler configuration: %w", err)
+	}
+
+	// Finalize logger setup after all options have been applied
+	c.setupLogger()
+
+	return c, nil
+}
+
+func (c *Compiler) String() string {
+	return "cel.Compiler"
+}
+
+// Compile type-checks the provided expression, and plans a program which can be evaluated
+// many times, concurrently.
+func (c *Compiler) Compile(scriptReader io.ReadCloser) (script.ExecutableContent, error) {
+	if scriptReader == nil {
+		return nil, ErrContentNil
+	}
+
+	scriptBodyBytes, err := io.ReadAll(scriptReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	err = scriptReader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to close reader: %w", err)
+	}
+
+	return c.compile(scriptBodyBytes)
+}
+
+// newEnv creates the CEL environment used to check expressions
+func (c *Compiler) newEnv() (*celLib.Env, error) {
+	opts := make([]celLib.EnvOption, 0, 1+len(c.variables)+len(c.envOptions))
+	opts = append(opts, celLib.Variable(constants.Ctx, celLib.MapType(celLib.StringType, celLib.DynType)))
+	opts = append(opts, c.variables...)
+	opts = append(opts, c.envOptions...)
+	return celLib.NewEnv(opts...)
+}
+
+func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
+	logger := c.logger.WithGroup("compile")
+	if len(scriptBodyBytes) == 0 {
+		logger.Error("Compile called with nil script")
+		return nil, ErrContentNil
+	}
+
+	if strings.TrimSpace(string(scriptBodyBytes)) == "" {
+		return nil, ErrNoInstructions
+	}
+
+	logger.Debug("Starting CEL compilation", "scriptLength", len(scriptBodyBytes))
+
+	env, err := c.newEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel environment: %w", err)
+	}
+
+	ast, err := compile.Compile(env, scriptBodyBytes, c.outputType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	costEstimate, err := env.EstimateCost(ast, noSizeEstimator{})
+	if err != nil {
+		// Cost estimation is informational, so a failure doesn't stop compilation
+		logger.Warn("Failed to estimate expression cost", "error", err)
+	}
+	logger.Debug("Estimated expression cost", "min", costEstimate.Min, "max", costEstimate.Max)
+
+	progOpts := []celLib.ProgramOption{celLib.InterruptCheckFrequency(interruptCheckFrequency)}
+	if c.costLimit > 0 {
+		progOpts = append(progOpts, celLib.CostLimit(c.costLimit))
+	}
+
+	prg, err := env.Program(ast, progOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	if prg == nil {
+		return nil, ErrBytecodeNil
+	}
+
+	celExec := newExecutable(scriptBodyBytes, ast, prg, costEstimate)
+	if celExec == nil {
+		return nil, ErrExecCreationFailed
+	}
+	celExec.evalOptions = c.evalOptions
+
+	logger.Debug("CEL compilation completed", "outputType", ast.OutputType())
+	return celExec, nil
+}
+
+// noSizeEstimator is a checker.CostEstimator with no knowledge of the size of ctx values,
+// so the estimate falls back to the CEL defaults.
+type noSizeEstimator struct{}
+
+func (noSizeEstimator) EstimateSize(checker.AstNode) *checker.SizeEstimate {
+	return nil
+}
+
+func (noSizeEstimator) EstimateCallCost(string, string, *checker.AstNode, []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
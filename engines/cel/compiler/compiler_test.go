@@ -0,0 +1,167 @@
+package compiler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/stretchr/testify/require"
+)
+
+// errorReader implements io.ReadCloser for testing read errors
+type errorReader struct{}
+
+func (e *errorReader) Read(p []byte) (n int, err error) {
+	return 0, errors.New("test error")
+}
+
+func (e *errorReader) Close() error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("basic creation", func(t *testing.T) {
+		comp, err := New(WithLogHandler(slog.NewTextHandler(os.Stdout, nil)))
+		require.NoError(t, err)
+		require.NotNil(t, comp)
+		require.Equal(t, "cel.Compiler", comp.String())
+	})
+
+	t.Run("with nil log handler", func(t *testing.T) {
+		comp, err := New(WithLogHandler(nil))
+		require.Error(t, err)
+		require.Nil(t, comp)
+		require.Contains(t, err.Error(), "log handler cannot be nil")
+	})
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		script   string
+		opts     []FunctionalOption
+		wantType *celLib.Type
+		wantErr  error
+	}{
+		{
+			name:     "boolean policy on ctx",
+			script:   `ctx.user.role == "admin" || ctx.user.id in ctx.allowed`,
+			wantType: celLib.BoolType,
+		},
+		{
+			name:     "typed variable",
+			script:   `age >= 18`,
+			opts:     []FunctionalOption{WithVariable("age", celLib.IntType)},
+			wantType: celLib.BoolType,
+		},
+		{
+			name:    "typed variable mismatch",
+			script:  `age == "eighteen"`,
+			opts:    []FunctionalOption{WithVariable("age", celLib.IntType)},
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:    "undeclared variable",
+			script:  `age >= 18`,
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:     "output type",
+			script:   `size(ctx.items) > 0`,
+			opts:     []FunctionalOption{WithOutputType(celLib.BoolType)},
+			wantType: celLib.BoolType,
+		},
+		{
+			name:    "output type mismatch",
+			script:  `"not a bool"`,
+			opts:    []FunctionalOption{WithOutputType(celLib.BoolType)},
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:     "extension library",
+			script:   `ctx.name.upperAscii()`,
+			opts:     []FunctionalOption{WithEnvOptions(ext.Strings())},
+			wantType: celLib.StringType,
+		},
+		{
+			name:    "syntax error",
+			script:  `ctx.name ==`,
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:    "empty script",
+			script:  ``,
+			wantErr: ErrContentNil,
+		},
+		{
+			name:    "whitespace only",
+			script:  "  \n\t ",
+			wantErr: ErrNoInstructions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			comp, err := New(tt.opts...)
+			require.NoError(t, err)
+
+			exe, err := comp.Compile(io.NopCloser(strings.NewReader(tt.script)))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, exe)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, exe)
+			require.Equal(t, tt.script, exe.GetSource())
+			require.Equal(t, MachineType, exe.GetMachineType())
+			require.Implements(t, (*celLib.Program)(nil), exe.GetByteCode())
+
+			celExe, ok := exe.(*executable)
+			require.True(t, ok)
+			require.Equal(t, tt.wantType, celExe.GetOutputType())
+		})
+	}
+
+	t.Run("cost estimate", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+
+		exe, err := comp.Compile(io.NopCloser(strings.NewReader(`1 + 2 == 3`)))
+		require.NoError(t, err)
+
+		celExe, ok := exe.(*executable)
+		require.True(t, ok)
+		estimate := celExe.GetCostEstimate()
+		require.Positive(t, estimate.Max)
+		require.LessOrEqual(t, estimate.Min, estimate.Max)
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+		exe, err := comp.Compile(nil)
+		require.ErrorIs(t, err, ErrContentNil)
+		require.Nil(t, exe)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+		exe, err := comp.Compile(&errorReader{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read script")
+		require.Nil(t, exe)
+	})
+}
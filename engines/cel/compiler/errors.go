@@ -0,0 +1,11 @@
+package compiler
+
+import "errors"
+
+var (
+	ErrBytecodeNil        = errors.New("cel program is nil")
+	ErrContentNil         = errors.New("cel content is nil")
+	ErrExecCreationFailed = errors.New("unable to create cel executable")
+	ErrNoInstructions     = errors.New("cel expression is empty")
+	ErrValidationFailed   = errors.New("cel expression validation error")
+)
@@ -0,0 +1,76 @@
+package compiler
+
+import (
+	celLib "github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+)
+
+// MachineType is the machine type of CEL executables. It's registered with the file
+// extensions of CEL scripts by the cel package.
+const MachineType machineTypes.Type = "cel"
+
+// executable represents a type-checked CEL expression, and the program planned from it
+type executable struct {
+	scriptBodyBytes []byte
+	ByteCode        celLib.Program
+	ast             *celLib.Ast
+	costEstimate    checker.CostEstimate
+	evalOptions     []platform.EvalOption
+}
+
+func newExecutable(
+	scriptBodyBytes []byte,
+	ast *celLib.Ast,
+	byteCode celLib.Program,
+	costEstimate checker.CostEstimate,
+) *executable {
+	if len(scriptBodyBytes) == 0 || ast == nil || byteCode == nil {
+		return nil
+	}
+
+	return &executable{
+		scriptBodyBytes: scriptBodyBytes,
+		ByteCode:        byteCode,
+		ast:             ast,
+		costEstimate:    costEstimate,
+	}
+}
+
+func (e *executable) GetSource() string {
+	return string(e.scriptBodyBytes)
+}
+
+func (e *executable) GetByteCode() any {
+	return e.ByteCode
+}
+
+func (e *executable) GetCELByteCode() celLib.Program {
+	return e.ByteCode
+}
+
+// GetCELAst returns the checked AST of the expression
+func (e *executable) GetCELAst() *celLib.Ast {
+	return e.ast
+}
+
+// GetOutputType returns the type the expression was checked to produce
+func (e *executable) GetOutputType() *celLib.Type {
+	return e.ast.OutputType()
+}
+
+// GetCostEstimate returns the static cost range of the expression, computed at compile time.
+// Expressions that iterate over ctx data of unknown size have a very large maximum cost.
+func (e *executable) GetCostEstimate() checker.CostEstimate {
+	return e.costEstimate
+}
+
+func (e *executable) GetMachineType() machineTypes.Type {
+	return MachineType
+}
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
+}
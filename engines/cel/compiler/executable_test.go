@@ -0,0 +1,49 @@
+package compiler
+
+import (
+	"testing"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecutable tests the functionality of Executable
+func TestExecutable(t *testing.T) {
+	t.Parallel()
+
+	env, err := celLib.NewEnv()
+	require.NoError(t, err)
+	ast, iss := env.Compile(`1 + 1`)
+	require.NoError(t, iss.Err())
+	prg, err := env.Program(ast)
+	require.NoError(t, err)
+	estimate := checker.CostEstimate{Min: 1, Max: 1}
+
+	t.Run("valid creation", func(t *testing.T) {
+		content := "1 + 1"
+
+		exe := newExecutable([]byte(content), ast, prg, estimate)
+		require.NotNil(t, exe)
+		assert.Equal(t, content, exe.GetSource())
+		assert.Equal(t, prg, exe.GetByteCode())
+		assert.Equal(t, prg, exe.GetCELByteCode())
+		assert.Equal(t, ast, exe.GetCELAst())
+		assert.Equal(t, celLib.IntType, exe.GetOutputType())
+		assert.Equal(t, estimate, exe.GetCostEstimate())
+		assert.Equal(t, MachineType, exe.GetMachineType())
+	})
+
+	t.Run("nil content", func(t *testing.T) {
+		assert.Nil(t, newExecutable(nil, ast, prg, estimate))
+	})
+
+	t.Run("nil ast", func(t *testing.T) {
+		assert.Nil(t, newExecutable([]byte("1"), nil, prg, estimate))
+	})
+
+	t.Run("nil program", func(t *testing.T) {
+		assert.Nil(t, newExecutable([]byte("1"), ast, nil, estimate))
+	})
+}
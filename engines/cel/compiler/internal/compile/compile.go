@@ -0,0 +1,35 @@
+package compile
+
+import (
+	"fmt"
+
+	celLib "github.com/google/cel-go/cel"
+)
+
+// Compile parses and type-checks the expression against the variables declared in env.
+//
+// When outputType is not nil, the expression must evaluate to a value assignable to it.
+// Expressions with a dynamic output type are accepted, since their type is only known at
+// evaluation time.
+func Compile(env *celLib.Env, scriptBodyBytes []byte, outputType *celLib.Type) (*celLib.Ast, error) {
+	if env == nil {
+		return nil, ErrEnvNil
+	}
+	if scriptBodyBytes == nil {
+		return nil, ErrContentNil
+	}
+
+	ast, issues := env.Compile(string(scriptBodyBytes))
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileFailed, issues.Err())
+	}
+
+	if outputType != nil {
+		got := ast.OutputType()
+		if !got.IsExactType(celLib.DynType) && !outputType.IsAssignableType(got) {
+			return nil, fmt.Errorf("%w: expected %s, got %s", ErrOutputTypeMismatch, outputType, got)
+		}
+	}
+
+	return ast, nil
+}
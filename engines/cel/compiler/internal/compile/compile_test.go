@@ -0,0 +1,67 @@
+package compile
+
+import (
+	"testing"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEnv(t *testing.T) *celLib.Env {
+	t.Helper()
+	env, err := celLib.NewEnv(
+		celLib.Variable("ctx", celLib.MapType(celLib.StringType, celLib.DynType)),
+		celLib.Variable("count", celLib.IntType),
+	)
+	require.NoError(t, err)
+	return env
+}
+
+// TestCompileSuccess tests the successful compilation of valid expressions
+func TestCompileSuccess(t *testing.T) {
+	env := newTestEnv(t)
+
+	ast, err := Compile(env, []byte(`count > 1 && ctx.name == "admin"`), celLib.BoolType)
+	require.NoError(t, err)
+	require.NotNil(t, ast)
+	require.Equal(t, celLib.BoolType, ast.OutputType())
+}
+
+// TestCompileDynamicOutput tests that a dynamic result is accepted for any output type
+func TestCompileDynamicOutput(t *testing.T) {
+	ast, err := Compile(newTestEnv(t), []byte(`ctx.enabled`), celLib.BoolType)
+	require.NoError(t, err)
+	require.NotNil(t, ast)
+}
+
+// TestCompileTypeError tests that expressions are checked against the declared variables
+func TestCompileTypeError(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := Compile(env, []byte(`count + "1"`), nil)
+	require.ErrorIs(t, err, ErrCompileFailed)
+
+	_, err = Compile(env, []byte(`undeclared > 1`), nil)
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileOutputTypeMismatch tests that the output type is enforced
+func TestCompileOutputTypeMismatch(t *testing.T) {
+	_, err := Compile(newTestEnv(t), []byte(`count + 1`), celLib.BoolType)
+	require.ErrorIs(t, err, ErrOutputTypeMismatch)
+}
+
+// TestCompileSyntaxError tests the compilation failure due to syntax errors
+func TestCompileSyntaxError(t *testing.T) {
+	_, err := Compile(newTestEnv(t), []byte(`count >`), nil)
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileNilInputs tests the handling of nil content and environment
+func TestCompileNilInputs(t *testing.T) {
+	_, err := Compile(newTestEnv(t), nil, nil)
+	require.ErrorIs(t, err, ErrContentNil)
+
+	_, err = Compile(nil, []byte(`1`), nil)
+	require.ErrorIs(t, err, ErrEnvNil)
+}
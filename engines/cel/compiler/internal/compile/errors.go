@@ -0,0 +1,10 @@
+package compile
+
+import "errors"
+
+var (
+	ErrCompileFailed      = errors.New("failed to compile cel expression")
+	ErrContentNil         = errors.New("cel content is nil")
+	ErrEnvNil             = errors.New("cel environment is nil")
+	ErrOutputTypeMismatch = errors.New("cel expression output type mismatch")
+)
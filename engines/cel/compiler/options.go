@@ -0,0 +1,138 @@
+package compiler
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+)
+
+// FunctionalOption is a function that configures a Compiler instance
+type FunctionalOption func(*Compiler) error
+
+// WithVariable creates an option to declare a typed top-level variable. At evaluation time, the
+// variable is set from the ctx entry with the same name, so expressions can use `name` in
+// place of `ctx.name`, and are type-checked against the declared type when compiled.
+func WithVariable(name string, t *celLib.Type) FunctionalOption {
+	return func(c *Compiler) error {
+		if name == "" {
+			return fmt.Errorf("variable name cannot be empty")
+		}
+		if name == constants.Ctx {
+			return fmt.Errorf("variable name %q is reserved", constants.Ctx)
+		}
+		if t == nil {
+			return fmt.Errorf("type for variable %q cannot be nil", name)
+		}
+		c.variables = append(c.variables, celLib.Variable(name, t))
+		return nil
+	}
+}
+
+// WithOutputType creates an option that requires expressions to produce the given type,
+// for example celLib.BoolType for policy expressions. Expressions with a dynamic type,
+// such as `ctx.enabled`, are still accepted.
+func WithOutputType(t *celLib.Type) FunctionalOption {
+	return func(c *Compiler) error {
+		if t == nil {
+			return fmt.Errorf("output type cannot be nil")
+		}
+		c.outputType = t
+		return nil
+	}
+}
+
+// WithCostLimit creates an option to stop evaluations once their runtime cost exceeds the limit.
+func WithCostLimit(limit uint64) FunctionalOption {
+	return func(c *Compiler) error {
+		if limit == 0 {
+			return fmt.Errorf("cost limit must be greater than zero")
+		}
+		c.costLimit = limit
+		return nil
+	}
+}
+
+// WithEnvOptions creates an option to add CEL environment options, such as custom functions
+// or extension libraries, to the environment used for type-checking.
+func WithEnvOptions(opts ...celLib.EnvOption) FunctionalOption {
+	return func(c *Compiler) error {
+		c.envOptions = append(c.envOptions, opts...)
+		return nil
+	}
+}
+
+// WithEvalOptions creates an option to configure the evaluators of the compiled script, e.g.
+// platform.WithDefaultTimeout. It sets evaluation options through helpers that only take
+// compiler options, such as polyscript.FromCELString. Options given to the evaluator itself
+// take precedence.
+func WithEvalOptions(opts ...platform.EvalOption) FunctionalOption {
+	return func(c *Compiler) error {
+		c.evalOptions = append(c.evalOptions, opts...)
+		return nil
+	}
+}
+
+// WithLogHandler creates an option to set the log handler for CEL compiler.
+// This is the preferred option for logging configuration as it provides
+// more flexibility through the slog.Handler interface.
+func WithLogHandler(handler slog.Handler) FunctionalOption {
+	return func(c *Compiler) error {
+		if handler == nil {
+			return fmt.Errorf("log handler cannot be nil")
+		}
+		c.logHandler = handler
+		// Clear logger if handler is explicitly set
+		c.logger = nil
+		return nil
+	}
+}
+
+// WithLogger creates an option to set a specific logger for CEL compiler.
+// This is less flexible than WithLogHandler but allows users to customize
+// their logging group configuration.
+func WithLogger(logger *slog.Logger) FunctionalOption {
+	return func(c *Compiler) error {
+		if logger == nil {
+			return fmt.Errorf("logger cannot be nil")
+		}
+		c.logger = logger
+		// Clear handler if logger is explicitly set
+		c.logHandler = nil
+		return nil
+	}
+}
+
+// setupLogger configures the logger and handler based on the current state.
+// This is idempotent and can be called multiple times during initialization.
+func (c *Compiler) setupLogger() {
+	if c.logger != nil {
+		// When a logger is explicitly set, extract its handler
+		c.logHandler = c.logger.Handler()
+	} else {
+		// Otherwise use the handler (which might be default or custom) to create the logger
+		c.logHandler, c.logger = helpers.SetupLogger(c.logHandler, "cel", "Compiler")
+	}
+}
+
+// validate checks if the compiler configuration is valid
+func (c *Compiler) validate() error {
+	// Ensure we have either a logger or a handler
+	if c.logHandler == nil && c.logger == nil {
+		return fmt.Errorf("either log handler or logger must be specified")
+	}
+
+	return nil
+}
+
+// applyDefaults sets the default values for a compiler
+func (c *Compiler) applyDefaults() {
+	// Default to stderr for logging if neither handler nor logger specified
+	if c.logHandler == nil && c.logger == nil {
+		c.logHandler = slog.NewTextHandler(os.Stderr, nil)
+	}
+}
@@ -0,0 +1,88 @@
+package compiler
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilerOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default initialization", func(t *testing.T) {
+		c, err := New()
+		require.NoError(t, err)
+		require.NotNil(t, c.logHandler)
+		require.NotNil(t, c.logger)
+		require.Empty(t, c.variables)
+		require.Nil(t, c.outputType)
+		require.Zero(t, c.costLimit)
+	})
+
+	t.Run("WithVariable", func(t *testing.T) {
+		c := &Compiler{}
+		require.NoError(t, WithVariable("age", celLib.IntType)(c))
+		require.Len(t, c.variables, 1)
+
+		require.Error(t, WithVariable("", celLib.IntType)(c))
+		require.Error(t, WithVariable("ctx", celLib.IntType)(c))
+		require.Error(t, WithVariable("age", nil)(c))
+	})
+
+	t.Run("WithOutputType", func(t *testing.T) {
+		c := &Compiler{}
+		require.NoError(t, WithOutputType(celLib.BoolType)(c))
+		require.Equal(t, celLib.BoolType, c.outputType)
+		require.Error(t, WithOutputType(nil)(c))
+	})
+
+	t.Run("WithCostLimit", func(t *testing.T) {
+		c := &Compiler{}
+		require.NoError(t, WithCostLimit(100)(c))
+		require.Equal(t, uint64(100), c.costLimit)
+		require.Error(t, WithCostLimit(0)(c))
+	})
+
+	t.Run("WithEnvOptions", func(t *testing.T) {
+		c := &Compiler{}
+		require.NoError(t, WithEnvOptions(celLib.OptionalTypes())(c))
+		require.Len(t, c.envOptions, 1)
+	})
+
+	t.Run("with explicit log handler", func(t *testing.T) {
+		var buf bytes.Buffer
+		customHandler := slog.NewTextHandler(&buf, nil)
+
+		c, err := New(WithLogHandler(customHandler))
+		require.NoError(t, err)
+		require.Equal(t, customHandler, c.logHandler)
+
+		c.logger.Info("test message")
+		require.Contains(t, buf.String(), "test message")
+	})
+
+	t.Run("with explicit logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		customLogger := slog.New(slog.NewTextHandler(&buf, nil))
+
+		c, err := New(WithLogger(customLogger))
+		require.NoError(t, err)
+		require.Equal(t, customLogger, c.logger)
+		require.Equal(t, customLogger.Handler(), c.logHandler)
+	})
+
+	t.Run("nil logger", func(t *testing.T) {
+		c := &Compiler{}
+		err := WithLogger(nil)(c)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "logger cannot be nil")
+	})
+
+	t.Run("validate without logging", func(t *testing.T) {
+		c := &Compiler{}
+		require.Error(t, c.validate())
+	})
+}
@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/robbyt/go-polyscript/engines/cel/internal"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+// Evaluator is an abstraction layer for evaluating expressions on the CEL engine
+type Evaluator struct {
+	// execUnit contains the compiled program and data provider
+	execUnit *script.ExecutableUnit
+
+	// evalOpts configures evaluation, such as the default timeout
+	evalOpts platform.EvalOptions
+
+	logHandler slog.Handler
+	logger     *slog.Logger
+}
+
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
+func New(
+	handler slog.Handler,
+	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) *Evaluator {
+	handler, logger := helpers.SetupLogger(handler, "cel", "Evaluator")
+
+	return &Evaluator{
+		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
+		logHandler: handler,
+		logger:     logger,
+	}
+}
+
+func (be *Evaluator) String() string {
+	return "cel.Evaluator"
+}
+
+// getDataProvider returns the data provider from the executable unit, or nil if unavailable.
+func (be *Evaluator) getDataProvider() data.Provider {
+	if be.execUnit == nil {
+		return nil
+	}
+	return be.execUnit.GetDataProvider()
+}
+
+// loadInputData retrieves input data using the data provider in the executable unit.
+// Returns a map that will be used as input for the CEL program.
+func (be *Evaluator) loadInputData(ctx context.Context) (map[string]any, error) {
+	return data.LoadInputData(ctx, be.logger.WithGroup("loadInputData"), be.getDataProvider())
+}
+
+// exec evaluates the program with the provided activation
+func (be *Evaluator) exec(
+	ctx context.Context,
+	prg celLib.Program,
+	activation map[string]any,
+) (*execResult, error) {
+	logger := be.logger.WithGroup("exec")
+	startTime := time.Now()
+
+	// ContextEval stops comprehensions when the context is done
+	val, details, err := prg.ContextEval(ctx, activation)
+	execTime := time.Since(startTime)
+
+	if err != nil {
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("cel evaluation error: %w: %w", ctxErr, err)
+		}
+		return nil, fmt.Errorf("cel evaluation error: %w", err)
+	}
+
+	if details != nil && details.ActualCost() != nil {
+		logger.DebugContext(ctx, "evaluation cost", "cost", *details.ActualCost())
+	}
+
+	return newEvalResult(be.logHandler, val, execTime, ""), nil
+}
+
+// Eval evaluates the compiled expression with the data from the provider
+func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	logger := be.logger.WithGroup("Eval")
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
+	if be.execUnit == nil {
+		return nil, fmt.Errorf("executable unit is nil")
+	}
+
+	if be.execUnit.GetContent() == nil {
+		return nil, fmt.Errorf("content is nil")
+	}
+
+	// Get bytecode from executable unit
+	bytecode := be.execUnit.GetContent().GetByteCode()
+	if bytecode == nil {
+		return nil, fmt.Errorf("bytecode is nil")
+	}
+
+	// Get execution ID
+	exeID := be.execUnit.GetID()
+	if exeID == "" {
+		return nil, fmt.Errorf("exeID is empty")
+	}
+	logger = logger.With("exeID", exeID)
+
+	// 1. Type assert to CEL program
+	prg, ok := bytecode.(celLib.Program)
+	if !ok {
+		return nil, fmt.Errorf("invalid bytecode type: expected cel.Program, got %T", bytecode)
+	}
+
+	// 2. Get the raw input data
+	rawInputData, err := be.loadInputData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input data: %w", err)
+	}
+
+	// 3. Convert input data to the activation
+	activation, err := internal.ConvertToCELFormat(rawInputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert input data: %w", err)
+	}
+
+	// 4. Evaluate the program
+	result, err := be.exec(ctx, prg, activation)
+	if err != nil {
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+	logger.DebugContext(ctx, "exec complete", "result", result)
+
+	// 5. Collect results
+	result.scriptExeID = exeID
+	return result, nil
+}
+
+// AddDataToContext implements the data.Setter interface which stores and prepares runtime data
+// which can be eventually passed to the Eval method.
+func (be *Evaluator) AddDataToContext(
+	ctx context.Context,
+	d ...map[string]any,
+) (context.Context, error) {
+	return data.AddDataToContextFromProvider(ctx, be.logger.WithGroup("AddDataToContext"), be.getDataProvider(), d...)
+}
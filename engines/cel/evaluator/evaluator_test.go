@@ -0,0 +1,253 @@
+package evaluator
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/robbyt/go-polyscript/engines/cel/compiler"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/require"
+)
+
+// evalBuilder is a helper function to create a test executable unit and evaluator
+func evalBuilder(
+	t *testing.T,
+	scriptContent string,
+	provider data.Provider,
+	opts ...compiler.FunctionalOption,
+) (*script.ExecutableUnit, *Evaluator) {
+	t.Helper()
+	ldr, err := loader.NewFromString(scriptContent)
+	require.NoError(t, err, "Failed to create new loader")
+
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	comp, err := compiler.New(append([]compiler.FunctionalOption{compiler.WithLogHandler(handler)}, opts...)...)
+	require.NoError(t, err, "Failed to create compiler")
+
+	exe, err := script.NewExecutableUnit(handler, scriptContent, ldr, comp, provider)
+	require.NoError(t, err, "Failed to create new version")
+
+	evaluator := New(handler, exe)
+	require.NotNil(t, evaluator, "Evaluator should not be nil")
+	return exe, evaluator
+}
+
+func TestEvaluator_Eval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		script   string
+		input    map[string]any
+		opts     []compiler.FunctionalOption
+		wantType data.Types
+		want     any
+	}{
+		{
+			name:     "boolean policy",
+			script:   `ctx.user.role == "admin" && ctx.request.Method in ["GET", "HEAD"]`,
+			input:    map[string]any{"user": map[string]any{"role": "admin"}, "request": map[string]any{"Method": "GET"}},
+			wantType: data.BOOL,
+			want:     true,
+		},
+		{
+			name:     "typed variable",
+			script:   `age >= 18`,
+			input:    map[string]any{"age": 21},
+			opts:     []compiler.FunctionalOption{compiler.WithVariable("age", celLib.IntType)},
+			wantType: data.BOOL,
+			want:     true,
+		},
+		{
+			name:     "integer",
+			script:   `ctx.a + ctx.b`,
+			input:    map[string]any{"a": 2, "b": 3},
+			wantType: data.INT,
+			want:     int64(5),
+		},
+		{
+			name:     "float",
+			script:   `double(ctx.a) / 2.0`,
+			input:    map[string]any{"a": 3},
+			wantType: data.FLOAT,
+			want:     1.5,
+		},
+		{
+			name:     "string",
+			script:   `"Hello, " + ctx.name`,
+			input:    map[string]any{"name": "World"},
+			wantType: data.STRING,
+			want:     "Hello, World",
+		},
+		{
+			name:     "list",
+			script:   `ctx.items.filter(x, x > 1)`,
+			input:    map[string]any{"items": []any{1, 2, 3}},
+			wantType: data.LIST,
+			want:     []any{int64(2), int64(3)},
+		},
+		{
+			name:     "map",
+			script:   `{"name": ctx.name, "length": size(ctx.name)}`,
+			input:    map[string]any{"name": "World"},
+			wantType: data.MAP,
+			want:     map[string]any{"name": "World", "length": int64(5)},
+		},
+		{
+			name:     "null",
+			script:   `null`,
+			wantType: data.NONE,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			exe, evaluator := evalBuilder(t, tt.script, data.NewStaticProvider(tt.input), tt.opts...)
+
+			response, err := evaluator.Eval(t.Context())
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			require.Equal(t, tt.wantType, response.Type())
+			require.Equal(t, tt.want, response.Interface())
+			require.Equal(t, exe.GetID(), response.GetScriptExeID())
+		})
+	}
+}
+
+func TestEvaluator_EvalWithContextData(t *testing.T) {
+	t.Parallel()
+
+	_, evaluator := evalBuilder(t, `ctx.greeting + ", " + ctx.name`,
+		data.NewContextProvider(constants.EvalData))
+
+	ctx, err := evaluator.AddDataToContext(t.Context(),
+		map[string]any{"greeting": "Hi"},
+		map[string]any{"name": "Ada"},
+	)
+	require.NoError(t, err)
+
+	response, err := evaluator.Eval(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Hi, Ada", response.Interface())
+}
+
+func TestEvaluator_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "executable unit is nil")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `ctx.missing == 1`, data.NewStaticProvider(nil))
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cel evaluation error")
+	})
+
+	t.Run("unsupported input data", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `true`,
+			data.NewStaticProvider(map[string]any{"bad": make(chan int)}))
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to convert input data")
+	})
+
+	t.Run("cost limit", func(t *testing.T) {
+		items := make([]any, 1000)
+		for i := range items {
+			items[i] = i
+		}
+		_, evaluator := evalBuilder(t, `ctx.items.all(x, ctx.items.exists(y, y == x))`,
+			data.NewStaticProvider(map[string]any{"items": items}),
+			compiler.WithCostLimit(1000),
+		)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cost limit exceeded")
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		items := make([]any, 5000)
+		for i := range items {
+			items[i] = i
+		}
+		_, evaluator := evalBuilder(t, `ctx.items.all(x, ctx.items.all(y, y >= 0))`,
+			data.NewStaticProvider(map[string]any{"items": items}))
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+
+		_, err := evaluator.Eval(ctx)
+		require.Error(t, err)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+	})
+
+	t.Run("default timeout", func(t *testing.T) {
+		items := make([]any, 5000)
+		for i := range items {
+			items[i] = i
+		}
+		exe, _ := evalBuilder(t, `ctx.items.all(x, ctx.items.all(y, y >= 0))`,
+			data.NewStaticProvider(map[string]any{"items": items}))
+		evaluator := New(nil, exe, platform.WithDefaultTimeout(time.Millisecond))
+
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		items := make([]any, 5000)
+		for i := range items {
+			items[i] = i
+		}
+		_, evaluator := evalBuilder(t, `ctx.items.all(x, ctx.items.all(y, y >= 0))`,
+			data.NewStaticProvider(map[string]any{"items": items}))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := evaluator.Eval(ctx)
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrCanceled)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestEvaluator_String(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "cel.Evaluator", New(nil, nil).String())
+}
+
+func TestEvaluator_AddDataToContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.AddDataToContext(t.Context(), map[string]any{"a": 1})
+		require.Error(t, err)
+	})
+
+	t.Run("static provider rejects data", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `true`, data.NewStaticProvider(nil))
+		_, err := evaluator.AddDataToContext(t.Context(), map[string]any{"a": 1})
+		require.ErrorIs(t, err, data.ErrStaticProviderNoRuntimeUpdates)
+	})
+}
@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/robbyt/go-polyscript/engines/cel/internal"
+	"github.com/robbyt/go-polyscript/platform/data"
+)
+
+// execResult is a wrapper around the ref.Val interface
+type execResult struct {
+	ref.Val
+	execTime    time.Duration
+	scriptExeID string
+	logHandler  slog.Handler
+	logger      *slog.Logger
+}
+
+func newEvalResult(
+	handler slog.Handler,
+	obj ref.Val,
+	execTime time.Duration,
+	versionID string,
+) *execResult {
+	if handler == nil {
+		defaultHandler := slog.NewTextHandler(os.Stdout, nil)
+		handler = defaultHandler.WithGroup("cel")
+		// Create a logger from the handler rather than using slog directly
+		defaultLogger := slog.New(handler)
+		defaultLogger.Warn("Handler is nil, using the default logger configuration.")
+	}
+
+	if obj == nil {
+		obj = types.NullValue
+	}
+
+	return &execResult{
+		Val:         obj,
+		execTime:    execTime,
+		scriptExeID: versionID,
+		logHandler:  handler,
+		logger:      slog.New(handler.WithGroup("execResult")),
+	}
+}
+
+func (r *execResult) String() string {
+	return fmt.Sprintf(
+		"ExecResult{Type: %s, Value: %v, ExecTime: %s, ScriptExeID: %s}",
+		r.Type(), r.Val.Value(), r.GetExecTime(), r.GetScriptExeID())
+}
+
+func (r *execResult) Type() data.Types {
+	// Map CEL types to our internal types
+	switch r.Val.(type) {
+	case types.Null:
+		return data.NONE
+	case types.Bool:
+		return data.BOOL
+	case types.Int, types.Uint:
+		return data.INT
+	case types.Double:
+		return data.FLOAT
+	case types.String, types.Bytes, types.Timestamp, types.Duration:
+		return data.STRING
+	case traits.Lister:
+		return data.LIST
+	case traits.Mapper:
+		return data.MAP
+	default:
+		r.logger.Error("Unknown type", "type", r.Val.Type())
+		return data.ERROR
+	}
+}
+
+func (r *execResult) GetScriptExeID() string {
+	return r.scriptExeID
+}
+
+func (r *execResult) GetExecTime() string {
+	return r.execTime.String()
+}
+
+func (r *execResult) Inspect() string {
+	v := r.Interface()
+	switch r.Type() {
+	case data.LIST, data.MAP:
+		jsonBytes, err := json.Marshal(v)
+		if err != nil {
+			r.logger.Error("Failed to marshal value to JSON", "error", err)
+			return fmt.Sprintf("%v", v)
+		}
+		return string(jsonBytes)
+	case data.NONE:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Interface returns the Go native type for the CEL value
+func (r *execResult) Interface() any {
+	v, err := internal.ConvertCELValueToInterface(r.Val)
+	if err != nil {
+		r.logger.Error("Failed to convert CEL value to interface", "error", err)
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,87 @@
+package evaluator
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecResult(t *testing.T) {
+	t.Parallel()
+
+	handler := slog.NewTextHandler(os.Stdout, nil)
+	adapter := types.DefaultTypeAdapter
+
+	tests := []struct {
+		name          string
+		value         any
+		wantType      data.Types
+		wantInterface any
+		wantInspect   string
+	}{
+		{
+			name:          "null",
+			value:         types.NullValue,
+			wantType:      data.NONE,
+			wantInterface: nil,
+			wantInspect:   "null",
+		},
+		{name: "bool", value: true, wantType: data.BOOL, wantInterface: true, wantInspect: "true"},
+		{name: "int", value: int64(42), wantType: data.INT, wantInterface: int64(42), wantInspect: "42"},
+		{name: "uint", value: uint64(7), wantType: data.INT, wantInterface: uint64(7), wantInspect: "7"},
+		{name: "double", value: 1.5, wantType: data.FLOAT, wantInterface: 1.5, wantInspect: "1.5"},
+		{name: "string", value: "hi", wantType: data.STRING, wantInterface: "hi", wantInspect: "hi"},
+		{
+			name:          "duration",
+			value:         time.Minute,
+			wantType:      data.STRING,
+			wantInterface: "1m0s",
+			wantInspect:   "1m0s",
+		},
+		{
+			name:          "list",
+			value:         []any{int64(1), "a"},
+			wantType:      data.LIST,
+			wantInterface: []any{int64(1), "a"},
+			wantInspect:   `[1,"a"]`,
+		},
+		{
+			name:          "map",
+			value:         map[string]any{"key": "value"},
+			wantType:      data.MAP,
+			wantInterface: map[string]any{"key": "value"},
+			wantInspect:   `{"key":"value"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := newEvalResult(handler, adapter.NativeToValue(tt.value), time.Second, "test-id")
+			require.Equal(t, tt.wantType, result.Type())
+			require.Equal(t, tt.wantInterface, result.Interface())
+			require.Equal(t, tt.wantInspect, result.Inspect())
+			require.Equal(t, "test-id", result.GetScriptExeID())
+			require.Equal(t, "1s", result.GetExecTime())
+			require.Contains(t, result.String(), "ExecResult{Type: "+string(tt.wantType))
+		})
+	}
+
+	t.Run("error value", func(t *testing.T) {
+		result := newEvalResult(handler, types.NewErr("boom"), 0, "")
+		require.Equal(t, data.ERROR, result.Type())
+		require.Nil(t, result.Interface())
+	})
+
+	t.Run("nil handler and value", func(t *testing.T) {
+		result := newEvalResult(nil, nil, 0, "")
+		require.NotNil(t, result.logHandler)
+		require.NotNil(t, result.logger)
+		require.Equal(t, data.NONE, result.Type())
+	})
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/robbyt/go-polyscript/platform/constants"
+)
+
+// ConvertToCELFormat builds the activation for an evaluation. The input data is available as the
+// "ctx" variable, and each entry is also available as a top-level variable, which is used by
+// variables declared with the compiler's WithVariable option. Undeclared variables are ignored
+// by CEL.
+func ConvertToCELFormat(inputData map[string]any) (map[string]any, error) {
+	ctxMap := make(map[string]any, len(inputData))
+	activation := make(map[string]any, len(inputData)+1)
+
+	// Convert each input data key-value pair and add to the ctxMap
+	errz := make([]error, 0, len(inputData))
+	for k, v := range inputData {
+		celVal, err := ConvertToCELValue(v)
+		if err != nil {
+			// Collect errors but continue processing
+			errz = append(errz, fmt.Errorf("failed to convert input value for key %q: %w", k, err))
+			continue
+		}
+		ctxMap[k] = celVal
+		activation[k] = celVal
+	}
+
+	// return if there were any errors
+	if len(errz) > 0 {
+		return nil, errors.Join(errz...)
+	}
+
+	activation[constants.Ctx] = ctxMap
+	return activation, nil
+}
+
+// ConvertToCELValue converts a Go value into a value supported by the CEL type adapter
+func ConvertToCELValue(v any) (any, error) {
+	if v == nil {
+		return types.NullValue, nil
+	}
+
+	switch val := v.(type) {
+	case bool, string, []byte, float64, int64, uint64, time.Time, time.Duration:
+		return val, nil
+	case int:
+		return int64(val), nil
+	case int32:
+		return int64(val), nil
+	case float32:
+		return float64(val), nil
+	case *url.URL:
+		return val.String(), nil
+	case []any:
+		list := make([]any, len(val))
+		for i, elem := range val {
+			celVal, err := ConvertToCELValue(elem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert list element: %w", err)
+			}
+			list[i] = celVal
+		}
+		return list, nil
+	case []string:
+		return val, nil
+	case map[string]struct{}:
+		// golang doesn't have a Set, but often a map[string]struct{} is used instead.
+		// This is exposed as a map with each member set to true, so `"key" in set` works.
+		set := make(map[string]any, len(val))
+		for k := range val {
+			set[k] = true
+		}
+		return set, nil
+	case map[string][]string:
+		// Special handling for HTTP headers and query params
+		return val, nil
+	case map[string]any:
+		dict := make(map[string]any, len(val))
+		for k, v := range val {
+			celVal, err := ConvertToCELValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert map value: %w", err)
+			}
+			dict[k] = celVal
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// ConvertCELValueToInterface converts a CEL value to a Go any value.
+// Timestamps and durations are converted to strings, so results can be marshaled to JSON.
+func ConvertCELValueToInterface(v ref.Val) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch val := v.(type) {
+	case types.Null:
+		return nil, nil
+	case types.Bool:
+		return bool(val), nil
+	case types.Int:
+		return int64(val), nil
+	case types.Uint:
+		return uint64(val), nil
+	case types.Double:
+		return float64(val), nil
+	case types.String:
+		return string(val), nil
+	case types.Bytes:
+		return []byte(val), nil
+	case types.Timestamp:
+		return val.Time.Format(time.RFC3339Nano), nil
+	case types.Duration:
+		return val.Duration.String(), nil
+	case *types.Err:
+		return nil, val
+	case traits.Lister:
+		size, ok := val.Size().(types.Int)
+		if !ok {
+			return nil, fmt.Errorf("invalid list size %v", val.Size())
+		}
+		list := make([]any, 0, int(size))
+		for it := val.Iterator(); it.HasNext() == types.True; {
+			elem, err := ConvertCELValueToInterface(it.Next())
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert list element: %w", err)
+			}
+			list = append(list, elem)
+		}
+		return list, nil
+	case traits.Mapper:
+		// Create a string-keyed map for JSON compatibility
+		dict := make(map[string]any)
+		for it := val.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			kStr, err := ConvertCELValueToInterface(key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert map key: %w", err)
+			}
+
+			elem, err := ConvertCELValueToInterface(val.Get(key))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert map value: %w", err)
+			}
+			// Convert non-string keys to strings for JSON compatibility
+			dict[fmt.Sprint(kStr)] = elem
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("unsupported CEL type %s", v.Type())
+	}
+}
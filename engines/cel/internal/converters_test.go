@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToCELFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		u, err := url.Parse("https://example.com")
+		require.NoError(t, err)
+
+		activation, err := ConvertToCELFormat(map[string]any{
+			"name": "World",
+			"age":  21,
+			"url":  u,
+			"set":  map[string]struct{}{"a": {}},
+		})
+		require.NoError(t, err)
+
+		ctxMap, ok := activation["ctx"].(map[string]any)
+		require.True(t, ok)
+		require.Equal(t, "World", ctxMap["name"])
+		require.Equal(t, int64(21), ctxMap["age"])
+		require.Equal(t, "https://example.com", ctxMap["url"])
+		require.Equal(t, map[string]any{"a": true}, ctxMap["set"])
+
+		// entries are also top-level variables
+		require.Equal(t, "World", activation["name"])
+		require.Equal(t, int64(21), activation["age"])
+	})
+
+	t.Run("conversion errors", func(t *testing.T) {
+		_, err := ConvertToCELFormat(map[string]any{"bad": make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `key "bad"`)
+	})
+}
+
+func TestConvertToCELValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{name: "nil", input: nil, want: types.NullValue},
+		{name: "int", input: 1, want: int64(1)},
+		{name: "float32", input: float32(1.5), want: 1.5},
+		{name: "nested list", input: []any{1, []any{"a"}}, want: []any{int64(1), []any{"a"}}},
+		{
+			name:  "nested map",
+			input: map[string]any{"a": map[string]any{"b": 2}},
+			want:  map[string]any{"a": map[string]any{"b": int64(2)}},
+		},
+		{
+			name:  "headers",
+			input: map[string][]string{"Accept": {"text/plain"}},
+			want:  map[string][]string{"Accept": {"text/plain"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ConvertToCELValue(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := ConvertToCELValue(struct{}{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported type")
+
+		_, err = ConvertToCELValue([]any{make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to convert list element")
+	})
+}
+
+func TestConvertCELValueToInterface(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	adapter := types.DefaultTypeAdapter
+
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{name: "null", input: types.NullValue, want: nil},
+		{name: "bool", input: true, want: true},
+		{name: "int", input: int64(-1), want: int64(-1)},
+		{name: "uint", input: uint64(1), want: uint64(1)},
+		{name: "double", input: 2.5, want: 2.5},
+		{name: "string", input: "s", want: "s"},
+		{name: "bytes", input: []byte("b"), want: []byte("b")},
+		{name: "timestamp", input: ts, want: "2024-01-02T03:04:05Z"},
+		{name: "duration", input: 90 * time.Second, want: "1m30s"},
+		{name: "list", input: []any{int64(1), "a"}, want: []any{int64(1), "a"}},
+		{
+			name:  "map",
+			input: map[string]any{"a": []any{true}},
+			want:  map[string]any{"a": []any{true}},
+		},
+		{
+			name:  "int keys",
+			input: map[int64]string{1: "one"},
+			want:  map[string]any{"1": "one"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ConvertCELValueToInterface(adapter.NativeToValue(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		got, err := ConvertCELValueToInterface(nil)
+		require.NoError(t, err)
+		require.Nil(t, got)
+	})
+
+	t.Run("error value", func(t *testing.T) {
+		_, err := ConvertCELValueToInterface(types.NewErr("boom"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "boom")
+	})
+}
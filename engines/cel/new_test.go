@@ -0,0 +1,202 @@
+package cel
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	celLib "github.com/google/cel-go/cel"
+	"github.com/robbyt/go-polyscript/engines/cel/compiler"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCELScript = `ctx.user.role == "admin" || ctx.user.name in ctx.allowed`
+
+// Helper function to create a string loader with test script
+func createTestLoader(t *testing.T) *loader.FromString {
+	t.Helper()
+	stringLoader, err := loader.NewFromString(testCELScript)
+	require.NoError(t, err)
+	require.NotNil(t, stringLoader)
+	return stringLoader
+}
+
+// Helper function to create a mock loader that fails to load the script
+func createFailingLoader(t *testing.T) *loader.MockLoader {
+	t.Helper()
+	mockLoader := new(loader.MockLoader)
+	mockURL, err := url.Parse("file:///test-cel-file.cel")
+	require.NoError(t, err, "Failed to parse URL")
+	mockLoader.On("GetSourceURL").Return(mockURL)
+	mockLoader.On("GetReader").Return(nil, fmt.Errorf("failed to load script"))
+	return mockLoader
+}
+
+func TestFromCELLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		evalInstance, err := FromCELLoader(handler, createTestLoader(t))
+		require.NoError(t, err)
+		require.NotNil(t, evalInstance)
+		assert.Equal(t, "cel.Evaluator", evalInstance.String())
+
+		ctx, err := evalInstance.AddDataToContext(t.Context(), map[string]any{
+			"user":    map[string]any{"role": "viewer", "name": "ada"},
+			"allowed": []any{"ada", "grace"},
+		})
+		require.NoError(t, err)
+		response, err := evalInstance.Eval(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, true, response.Interface())
+	})
+
+	t.Run("with compiler options", func(t *testing.T) {
+		ldr, err := loader.NewFromString(`age >= 18`)
+		require.NoError(t, err)
+
+		_, err = FromCELLoader(nil, ldr)
+		require.ErrorIs(t, err, compiler.ErrValidationFailed)
+
+		evalInstance, err := FromCELLoader(nil, ldr, compiler.WithVariable("age", celLib.IntType))
+		require.NoError(t, err)
+
+		ctx, err := evalInstance.AddDataToContext(t.Context(), map[string]any{"age": 17})
+		require.NoError(t, err)
+		response, err := evalInstance.Eval(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, false, response.Interface())
+	})
+
+	t.Run("error from loader", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		mockLoader := createFailingLoader(t)
+
+		evalInstance, err := FromCELLoader(handler, mockLoader)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.Contains(t, err.Error(), "failed to load script")
+		mockLoader.AssertExpectations(t)
+	})
+}
+
+func TestFromCELLoaderWithData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success with static data", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		staticData := map[string]any{
+			"user":    map[string]any{"role": "admin", "name": "root"},
+			"allowed": []any{},
+		}
+
+		evalInstance, err := FromCELLoaderWithData(handler, createTestLoader(t), staticData)
+		require.NoError(t, err)
+		require.NotNil(t, evalInstance)
+
+		response, err := evalInstance.Eval(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, true, response.Interface())
+	})
+
+	t.Run("error from loader", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		mockLoader := createFailingLoader(t)
+
+		evalInstance, err := FromCELLoaderWithData(handler, mockLoader, nil)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.Contains(t, err.Error(), "failed to load script")
+		mockLoader.AssertExpectations(t)
+	})
+}
+
+func TestNewCompiler(t *testing.T) {
+	t.Parallel()
+
+	comp, err := NewCompiler(
+		compiler.WithLogHandler(slog.NewTextHandler(os.Stdout, nil)),
+		compiler.WithOutputType(celLib.BoolType),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, comp)
+}
+
+func TestNewEvaluator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil provider", func(t *testing.T) {
+		evalInstance, err := NewEvaluator(nil, createTestLoader(t), nil)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		require.Contains(t, err.Error(), "provider is nil")
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		invalidLoader, err := loader.NewFromString(`ctx.name ==`)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			invalidLoader,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.ErrorIs(t, err, compiler.ErrValidationFailed)
+	})
+
+	t.Run("output type mismatch", func(t *testing.T) {
+		ldr, err := loader.NewFromString(`"not a bool"`)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			ldr,
+			data.NewContextProvider(constants.EvalData),
+			compiler.WithOutputType(celLib.BoolType),
+		)
+		require.ErrorIs(t, err, compiler.ErrValidationFailed)
+		require.Nil(t, evalInstance)
+	})
+
+	t.Run("from disk loader", func(t *testing.T) {
+		tempFilePath := filepath.Join(t.TempDir(), "policy.cel")
+		require.NoError(t, os.WriteFile(tempFilePath, []byte(`size(ctx.items) > 1`), 0o644))
+
+		diskLoader, err := loader.NewFromDisk(tempFilePath)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			diskLoader,
+			data.NewStaticProvider(map[string]any{"items": []any{1, 2}}),
+		)
+		require.NoError(t, err)
+
+		response, err := evalInstance.Eval(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, true, response.Interface())
+	})
+}
+
+func TestRegistration(t *testing.T) {
+	t.Parallel()
+
+	e, ok := registry.Lookup(compiler.MachineType)
+	require.True(t, ok)
+	assert.Equal(t, []string{".cel"}, e.Extensions)
+
+	comp, err := registry.NewCompiler(compiler.WithOutputType(celLib.BoolType))
+	require.NoError(t, err)
+	assert.IsType(t, &compiler.Compiler{}, comp)
+}
@@ -0,0 +1,7 @@
+package adapters
+
+import "github.com/expr-lang/expr/vm"
+
+type ExprExecutable struct {
+	GetExprByteCode func() *vm.Program
+}
@@ -0,0 +1,121 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/robbyt/go-polyscript/engines/expr/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+type Compiler struct {
+	variables   map[string]any
+	exprOptions []exprLib.Option
+	logHandler  slog.Handler
+	logger      *slog.Logger
+
+	// evalOptions configure the evaluators of the compiled script
+	evalOptions []platform.EvalOption
+}
+
+// New creates a new expr-specific Compiler instance with the provided options.
+// Expressions always have access to the ctx variable, a map of string to any.
+func New(opts ...FunctionalOption) (*Compiler, error) {
+	// Initialize the compiler with an empty struct
+	c := &Compiler{}
+
+	// Apply defaults
+	c.applyDefaults()
+
+	// Apply all options
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("error applying compiler option: %w", err)
+		}
+	}
+
+	// Validate the configuration
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid compiler configuration: %w", err)
+	}
+
+	// Finalize logger setup after all options have been applied
+	c.setupLogger()
+
+	return c, nil
+}
+
+func (c *Compiler) String() string {
+	return "expr.Compiler"
+}
+
+// Compile type-checks the provided expression, and compiles it into a program which can be
+// run many times, concurrently.
+func (c *Compiler) Compile(scriptReader io.ReadCloser) (script.ExecutableContent, error) {
+	if scriptReader == nil {
+		return nil, ErrContentNil
+	}
+
+	scriptBodyBytes, err := io.ReadAll(scriptReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	err = scriptReader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to close reader: %w", err)
+	}
+
+	return c.compile(scriptBodyBytes)
+}
+
+// newEnv returns the environment used to type-check expressions. The values are only used
+// for their types.
+func (c *Compiler) newEnv() map[string]any {
+	env := make(map[string]any, len(c.variables)+1)
+	maps.Copy(env, c.variables)
+	env[constants.Ctx] = map[string]any{}
+	return env
+}
+
+func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
+	logger := c.logger.WithGroup("compile")
+	if len(scriptBodyBytes) == 0 {
+		logger.Error("Compile called with nil script")
+		return nil, ErrContentNil
+	}
+
+	if strings.TrimSpace(string(scriptBodyBytes)) == "" {
+		return nil, ErrNoInstructions
+	}
+
+	logger.Debug("Starting expr compilation", "scriptLength", len(scriptBodyBytes))
+
+	opts := make([]exprLib.Option, 0, 1+len(c.exprOptions))
+	opts = append(opts, exprLib.Env(c.newEnv()))
+	opts = append(opts, c.exprOptions...)
+
+	program, err := compile.Compile(scriptBodyBytes, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	if program == nil {
+		return nil, ErrBytecodeNil
+	}
+
+	exprExec := newExecutable(scriptBodyBytes, program)
+	if exprExec == nil {
+		return nil, ErrExecCreationFailed
+	}
+	exprExec.evalOptions = c.evalOptions
+
+	logger.Debug("expr compilation completed")
+	return exprExec, nil
+}
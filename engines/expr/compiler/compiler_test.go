@@ -0,0 +1,147 @@
+package compiler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/stretchr/testify/require"
+)
+
+// errorReader implements io.ReadCloser for testing read errors
+type errorReader struct{}
+
+func (e *errorReader) Read(p []byte) (n int, err error) {
+	return 0, errors.New("test error")
+}
+
+func (e *errorReader) Close() error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("basic creation", func(t *testing.T) {
+		comp, err := New(WithLogHandler(slog.NewTextHandler(os.Stdout, nil)))
+		require.NoError(t, err)
+		require.NotNil(t, comp)
+		require.Equal(t, "expr.Compiler", comp.String())
+	})
+
+	t.Run("with nil log handler", func(t *testing.T) {
+		comp, err := New(WithLogHandler(nil))
+		require.Error(t, err)
+		require.Nil(t, comp)
+		require.Contains(t, err.Error(), "log handler cannot be nil")
+	})
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		script  string
+		opts    []FunctionalOption
+		wantErr error
+	}{
+		{
+			name:   "transformation on ctx",
+			script: `{"total": ctx.price * ctx.quantity, "discounted": ctx.quantity > 10}`,
+		},
+		{
+			name:   "typed variable",
+			script: `price * 0.9`,
+			opts:   []FunctionalOption{WithVariable("price", 0.0)},
+		},
+		{
+			name:    "typed variable mismatch",
+			script:  `price + "off"`,
+			opts:    []FunctionalOption{WithVariable("price", 0.0)},
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:    "undeclared variable",
+			script:  `price > 1`,
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:   "bool result",
+			script: `len(ctx.items) > 0`,
+			opts:   []FunctionalOption{WithExprOptions(exprLib.AsBool())},
+		},
+		{
+			name:    "bool result mismatch",
+			script:  `"not a bool"`,
+			opts:    []FunctionalOption{WithExprOptions(exprLib.AsBool())},
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:   "custom function",
+			script: `discount(ctx.price)`,
+			opts: []FunctionalOption{WithExprOptions(exprLib.Function(
+				"discount",
+				func(params ...any) (any, error) { return params[0], nil },
+			))},
+		},
+		{
+			name:    "syntax error",
+			script:  `ctx.name ==`,
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:    "empty script",
+			script:  ``,
+			wantErr: ErrContentNil,
+		},
+		{
+			name:    "whitespace only",
+			script:  "  \n\t ",
+			wantErr: ErrNoInstructions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			comp, err := New(tt.opts...)
+			require.NoError(t, err)
+
+			exe, err := comp.Compile(io.NopCloser(strings.NewReader(tt.script)))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, exe)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, exe)
+			require.Equal(t, tt.script, exe.GetSource())
+			require.Equal(t, MachineType, exe.GetMachineType())
+			require.IsType(t, &vm.Program{}, exe.GetByteCode())
+		})
+	}
+
+	t.Run("nil reader", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+		exe, err := comp.Compile(nil)
+		require.ErrorIs(t, err, ErrContentNil)
+		require.Nil(t, exe)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+		exe, err := comp.Compile(&errorReader{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read script")
+		require.Nil(t, exe)
+	})
+}
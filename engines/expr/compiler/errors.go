@@ -0,0 +1,11 @@
+package compiler
+
+import "errors"
+
+var (
+	ErrBytecodeNil        = errors.New("expr program is nil")
+	ErrContentNil         = errors.New("expr content is nil")
+	ErrExecCreationFailed = errors.New("unable to create expr executable")
+	ErrNoInstructions     = errors.New("expr expression is empty")
+	ErrValidationFailed   = errors.New("expr expression validation error")
+)
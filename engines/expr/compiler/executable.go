@@ -0,0 +1,51 @@
+package compiler
+
+import (
+	"github.com/expr-lang/expr/vm"
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+)
+
+// MachineType is the machine type of expr executables. It's registered with the file
+// extensions of expr scripts by the expr package.
+const MachineType machineTypes.Type = "expr"
+
+// executable represents a type-checked expression, compiled into a program for the expr VM.
+// The program is immutable, so it can be run by many evaluations concurrently.
+type executable struct {
+	scriptBodyBytes []byte
+	ByteCode        *vm.Program
+	evalOptions     []platform.EvalOption
+}
+
+func newExecutable(scriptBodyBytes []byte, byteCode *vm.Program) *executable {
+	if len(scriptBodyBytes) == 0 || byteCode == nil {
+		return nil
+	}
+
+	return &executable{
+		scriptBodyBytes: scriptBodyBytes,
+		ByteCode:        byteCode,
+	}
+}
+
+func (e *executable) GetSource() string {
+	return string(e.scriptBodyBytes)
+}
+
+func (e *executable) GetByteCode() any {
+	return e.ByteCode
+}
+
+func (e *executable) GetExprByteCode() *vm.Program {
+	return e.ByteCode
+}
+
+func (e *executable) GetMachineType() machineTypes.Type {
+	return MachineType
+}
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
+}
@@ -0,0 +1,36 @@
+package compiler
+
+import (
+	"testing"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecutable tests the functionality of Executable
+func TestExecutable(t *testing.T) {
+	t.Parallel()
+
+	program, err := exprLib.Compile(`1 + 1`)
+	require.NoError(t, err)
+
+	t.Run("valid creation", func(t *testing.T) {
+		content := "1 + 1"
+
+		exe := newExecutable([]byte(content), program)
+		require.NotNil(t, exe)
+		assert.Equal(t, content, exe.GetSource())
+		assert.Equal(t, program, exe.GetByteCode())
+		assert.Equal(t, program, exe.GetExprByteCode())
+		assert.Equal(t, MachineType, exe.GetMachineType())
+	})
+
+	t.Run("nil content", func(t *testing.T) {
+		assert.Nil(t, newExecutable(nil, program))
+	})
+
+	t.Run("nil program", func(t *testing.T) {
+		assert.Nil(t, newExecutable([]byte("1"), nil))
+	})
+}
@@ -0,0 +1,24 @@
+package compile
+
+import (
+	"fmt"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Compile parses and type-checks the expression, and compiles it into a program for the expr
+// virtual machine. The options should include an environment, which declares the variables
+// the expression may use.
+func Compile(scriptBodyBytes []byte, opts ...exprLib.Option) (*vm.Program, error) {
+	if scriptBodyBytes == nil {
+		return nil, ErrContentNil
+	}
+
+	program, err := exprLib.Compile(string(scriptBodyBytes), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileFailed, err)
+	}
+
+	return program, nil
+}
@@ -0,0 +1,49 @@
+package compile
+
+import (
+	"testing"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnv() exprLib.Option {
+	return exprLib.Env(map[string]any{
+		"ctx":   map[string]any{},
+		"count": 0,
+	})
+}
+
+// TestCompileSuccess tests the successful compilation of valid expressions
+func TestCompileSuccess(t *testing.T) {
+	program, err := Compile([]byte(`count > 1 && ctx.name == "admin"`), testEnv())
+	require.NoError(t, err)
+	require.NotNil(t, program)
+}
+
+// TestCompileTypeError tests that expressions are checked against the environment
+func TestCompileTypeError(t *testing.T) {
+	_, err := Compile([]byte(`count + "1"`), testEnv())
+	require.ErrorIs(t, err, ErrCompileFailed)
+
+	_, err = Compile([]byte(`undeclared > 1`), testEnv())
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileOutputKind tests that output options are enforced
+func TestCompileOutputKind(t *testing.T) {
+	_, err := Compile([]byte(`count + 1`), testEnv(), exprLib.AsBool())
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileSyntaxError tests the compilation failure due to syntax errors
+func TestCompileSyntaxError(t *testing.T) {
+	_, err := Compile([]byte(`count >`), testEnv())
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileNilContent tests the handling of nil content
+func TestCompileNilContent(t *testing.T) {
+	_, err := Compile(nil, testEnv())
+	require.ErrorIs(t, err, ErrContentNil)
+}
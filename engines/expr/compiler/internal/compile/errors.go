@@ -0,0 +1,8 @@
+package compile
+
+import "errors"
+
+var (
+	ErrCompileFailed = errors.New("failed to compile expr expression")
+	ErrContentNil    = errors.New("expr content is nil")
+)
@@ -0,0 +1,74 @@
+package compiler
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilerOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default initialization", func(t *testing.T) {
+		c, err := New()
+		require.NoError(t, err)
+		require.NotNil(t, c.logHandler)
+		require.NotNil(t, c.logger)
+		require.Empty(t, c.variables)
+		require.Empty(t, c.exprOptions)
+	})
+
+	t.Run("WithVariable", func(t *testing.T) {
+		c := &Compiler{}
+		require.NoError(t, WithVariable("price", 0.0)(c))
+		require.Equal(t, map[string]any{"price": 0.0}, c.variables)
+
+		require.Error(t, WithVariable("", 0)(c))
+		require.Error(t, WithVariable("ctx", 0)(c))
+		require.Error(t, WithVariable("price", nil)(c))
+	})
+
+	t.Run("WithExprOptions", func(t *testing.T) {
+		c := &Compiler{}
+		require.NoError(t, WithExprOptions(exprLib.AsBool())(c))
+		require.Len(t, c.exprOptions, 1)
+		require.Error(t, WithExprOptions(nil)(c))
+	})
+
+	t.Run("with explicit log handler", func(t *testing.T) {
+		var buf bytes.Buffer
+		customHandler := slog.NewTextHandler(&buf, nil)
+
+		c, err := New(WithLogHandler(customHandler))
+		require.NoError(t, err)
+		require.Equal(t, customHandler, c.logHandler)
+
+		c.logger.Info("test message")
+		require.Contains(t, buf.String(), "test message")
+	})
+
+	t.Run("with explicit logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		customLogger := slog.New(slog.NewTextHandler(&buf, nil))
+
+		c, err := New(WithLogger(customLogger))
+		require.NoError(t, err)
+		require.Equal(t, customLogger, c.logger)
+		require.Equal(t, customLogger.Handler(), c.logHandler)
+	})
+
+	t.Run("nil logger", func(t *testing.T) {
+		c := &Compiler{}
+		err := WithLogger(nil)(c)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "logger cannot be nil")
+	})
+
+	t.Run("validate without logging", func(t *testing.T) {
+		c := &Compiler{}
+		require.Error(t, c.validate())
+	})
+}
@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	exprLib "github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/robbyt/go-polyscript/engines/expr/internal"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+// Evaluator is an abstraction layer for evaluating expressions on the expr engine
+type Evaluator struct {
+	// execUnit contains the compiled program and data provider
+	execUnit *script.ExecutableUnit
+
+	// evalOpts configures evaluation, such as the default timeout
+	evalOpts platform.EvalOptions
+
+	logHandler slog.Handler
+	logger     *slog.Logger
+}
+
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
+func New(
+	handler slog.Handler,
+	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) *Evaluator {
+	handler, logger := helpers.SetupLogger(handler, "expr", "Evaluator")
+
+	return &Evaluator{
+		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
+		logHandler: handler,
+		logger:     logger,
+	}
+}
+
+func (be *Evaluator) String() string {
+	return "expr.Evaluator"
+}
+
+// getDataProvider returns the data provider from the executable unit, or nil if unavailable.
+func (be *Evaluator) getDataProvider() data.Provider {
+	if be.execUnit == nil {
+		return nil
+	}
+	return be.execUnit.GetDataProvider()
+}
+
+// loadInputData retrieves input data using the data provider in the executable unit.
+// Returns a map that will be used as input for the expr program.
+func (be *Evaluator) loadInputData(ctx context.Context) (map[string]any, error) {
+	return data.LoadInputData(ctx, be.logger.WithGroup("loadInputData"), be.getDataProvider())
+}
+
+// exec runs the program with the provided environment. The expr VM can't be interrupted, but
+// expressions have no unbounded loops, so the context is only checked before the run starts.
+func (be *Evaluator) exec(
+	ctx context.Context,
+	program *vm.Program,
+	env map[string]any,
+) (*execResult, error) {
+	if err := platform.ContextError(ctx); err != nil {
+		return nil, fmt.Errorf("expr execution error: %w", err)
+	}
+
+	startTime := time.Now()
+	value, err := exprLib.Run(program, env)
+	execTime := time.Since(startTime)
+
+	if err != nil {
+		return nil, fmt.Errorf("expr execution error: %w", err)
+	}
+
+	return newEvalResult(be.logHandler, value, execTime, ""), nil
+}
+
+// Eval evaluates the compiled expression with the data from the provider
+func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	logger := be.logger.WithGroup("Eval")
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
+	if be.execUnit == nil {
+		return nil, fmt.Errorf("executable unit is nil")
+	}
+
+	if be.execUnit.GetContent() == nil {
+		return nil, fmt.Errorf("content is nil")
+	}
+
+	// Get bytecode from executable unit
+	bytecode := be.execUnit.GetContent().GetByteCode()
+	if bytecode == nil {
+		return nil, fmt.Errorf("bytecode is nil")
+	}
+
+	// Get execution ID
+	exeID := be.execUnit.GetID()
+	if exeID == "" {
+		return nil, fmt.Errorf("exeID is empty")
+	}
+	logger = logger.With("exeID", exeID)
+
+	// 1. Type assert to the compiled expr program
+	program, ok := bytecode.(*vm.Program)
+	if !ok {
+		return nil, fmt.Errorf("invalid bytecode type: expected *vm.Program, got %T", bytecode)
+	}
+
+	// 2. Get the raw input data
+	rawInputData, err := be.loadInputData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input data: %w", err)
+	}
+
+	// 3. Build the environment, with the input data available as ctx
+	env := internal.ConvertToExprFormat(rawInputData)
+
+	// 4. Run the program
+	result, err := be.exec(ctx, program, env)
+	if err != nil {
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+	logger.DebugContext(ctx, "exec complete", "result", result)
+
+	// 5. Collect results
+	result.scriptExeID = exeID
+	return result, nil
+}
+
+// AddDataToContext implements the data.Setter interface which stores and prepares runtime data
+// which can be eventually passed to the Eval method.
+func (be *Evaluator) AddDataToContext(
+	ctx context.Context,
+	d ...map[string]any,
+) (context.Context, error) {
+	return data.AddDataToContextFromProvider(ctx, be.logger.WithGroup("AddDataToContext"), be.getDataProvider(), d...)
+}
@@ -0,0 +1,195 @@
+package evaluator
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/engines/expr/compiler"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/require"
+)
+
+// evalBuilder is a helper function to create a test executable unit and evaluator
+func evalBuilder(
+	t *testing.T,
+	scriptContent string,
+	provider data.Provider,
+	opts ...compiler.FunctionalOption,
+) (*script.ExecutableUnit, *Evaluator) {
+	t.Helper()
+	ldr, err := loader.NewFromString(scriptContent)
+	require.NoError(t, err, "Failed to create new loader")
+
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	comp, err := compiler.New(append([]compiler.FunctionalOption{compiler.WithLogHandler(handler)}, opts...)...)
+	require.NoError(t, err, "Failed to create compiler")
+
+	exe, err := script.NewExecutableUnit(handler, scriptContent, ldr, comp, provider)
+	require.NoError(t, err, "Failed to create new version")
+
+	evaluator := New(handler, exe)
+	require.NotNil(t, evaluator, "Evaluator should not be nil")
+	return exe, evaluator
+}
+
+func TestEvaluator_Eval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		script   string
+		input    map[string]any
+		opts     []compiler.FunctionalOption
+		wantType data.Types
+		want     any
+	}{
+		{
+			name:     "boolean rule",
+			script:   `ctx.user.role == "admin" && ctx.method in ["GET", "HEAD"]`,
+			input:    map[string]any{"user": map[string]any{"role": "admin"}, "method": "GET"},
+			wantType: data.BOOL,
+			want:     true,
+		},
+		{
+			name:   "typed variables",
+			script: `price * quantity`,
+			input:  map[string]any{"price": 2.5, "quantity": 4},
+			opts: []compiler.FunctionalOption{
+				compiler.WithVariable("price", 0.0),
+				compiler.WithVariable("quantity", 0),
+			},
+			wantType: data.FLOAT,
+			want:     10.0,
+		},
+		{
+			name:     "integer",
+			script:   `ctx.a + ctx.b`,
+			input:    map[string]any{"a": 2, "b": 3},
+			wantType: data.INT,
+			want:     5,
+		},
+		{
+			name:     "float",
+			script:   `ctx.a / 2`,
+			input:    map[string]any{"a": 3},
+			wantType: data.FLOAT,
+			want:     1.5,
+		},
+		{
+			name:     "string",
+			script:   `"Hello, " + ctx.name`,
+			input:    map[string]any{"name": "World"},
+			wantType: data.STRING,
+			want:     "Hello, World",
+		},
+		{
+			name:     "list",
+			script:   `filter(ctx.items, # > 1)`,
+			input:    map[string]any{"items": []any{1, 2, 3}},
+			wantType: data.LIST,
+			want:     []any{2, 3},
+		},
+		{
+			name:     "map",
+			script:   `{"name": ctx.name, "length": len(ctx.name)}`,
+			input:    map[string]any{"name": "World"},
+			wantType: data.MAP,
+			want:     map[string]any{"name": "World", "length": 5},
+		},
+		{
+			name:     "nil",
+			script:   `nil`,
+			wantType: data.NONE,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			exe, evaluator := evalBuilder(t, tt.script, data.NewStaticProvider(tt.input), tt.opts...)
+
+			response, err := evaluator.Eval(t.Context())
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			require.Equal(t, tt.wantType, response.Type())
+			require.Equal(t, tt.want, response.Interface())
+			require.Equal(t, exe.GetID(), response.GetScriptExeID())
+		})
+	}
+}
+
+func TestEvaluator_EvalWithContextData(t *testing.T) {
+	t.Parallel()
+
+	_, evaluator := evalBuilder(t, `ctx.greeting + ", " + ctx.name`,
+		data.NewContextProvider(constants.EvalData))
+
+	ctx, err := evaluator.AddDataToContext(t.Context(),
+		map[string]any{"greeting": "Hi"},
+		map[string]any{"name": "Ada"},
+	)
+	require.NoError(t, err)
+
+	response, err := evaluator.Eval(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Hi, Ada", response.Interface())
+}
+
+func TestEvaluator_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "executable unit is nil")
+	})
+
+	t.Run("runtime error", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `ctx.items[5]`,
+			data.NewStaticProvider(map[string]any{"items": []any{1}}))
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expr execution error")
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `true`, data.NewStaticProvider(nil))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := evaluator.Eval(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, platform.ErrCanceled)
+	})
+}
+
+func TestEvaluator_String(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "expr.Evaluator", New(nil, nil).String())
+}
+
+func TestEvaluator_AddDataToContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.AddDataToContext(t.Context(), map[string]any{"a": 1})
+		require.Error(t, err)
+	})
+
+	t.Run("static provider rejects data", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `true`, data.NewStaticProvider(nil))
+		_, err := evaluator.AddDataToContext(t.Context(), map[string]any{"a": 1})
+		require.ErrorIs(t, err, data.ErrStaticProviderNoRuntimeUpdates)
+	})
+}
@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/robbyt/go-polyscript/engines/expr/internal"
+	"github.com/robbyt/go-polyscript/platform/data"
+)
+
+// execResult is a wrapper around the Go value returned by the expr VM
+type execResult struct {
+	value       any
+	execTime    time.Duration
+	scriptExeID string
+	logHandler  slog.Handler
+	logger      *slog.Logger
+}
+
+func newEvalResult(
+	handler slog.Handler,
+	value any,
+	execTime time.Duration,
+	versionID string,
+) *execResult {
+	if handler == nil {
+		defaultHandler := slog.NewTextHandler(os.Stdout, nil)
+		handler = defaultHandler.WithGroup("expr")
+		// Create a logger from the handler rather than using slog directly
+		defaultLogger := slog.New(handler)
+		defaultLogger.Warn("Handler is nil, using the default logger configuration.")
+	}
+
+	return &execResult{
+		value:       internal.ConvertExprValueToInterface(value),
+		execTime:    execTime,
+		scriptExeID: versionID,
+		logHandler:  handler,
+		logger:      slog.New(handler.WithGroup("execResult")),
+	}
+}
+
+func (r *execResult) String() string {
+	return fmt.Sprintf(
+		"ExecResult{Type: %s, Value: %v, ExecTime: %s, ScriptExeID: %s}",
+		r.Type(), r.value, r.GetExecTime(), r.GetScriptExeID())
+}
+
+func (r *execResult) Type() data.Types {
+	if r.value == nil {
+		return data.NONE
+	}
+
+	// expr returns plain Go values, including typed slices and maps from the input data
+	v := reflect.ValueOf(r.value)
+	switch v.Kind() {
+	case reflect.Bool:
+		return data.BOOL
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return data.INT
+	case reflect.Float32, reflect.Float64:
+		return data.FLOAT
+	case reflect.String:
+		return data.STRING
+	case reflect.Slice, reflect.Array:
+		return data.LIST
+	case reflect.Map:
+		return data.MAP
+	case reflect.Func:
+		return data.FUNCTION
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return data.NONE
+		}
+		r.logger.Error("Unknown type", "type", fmt.Sprintf("%T", r.value))
+		return data.ERROR
+	default:
+		r.logger.Error("Unknown type", "type", fmt.Sprintf("%T", r.value))
+		return data.ERROR
+	}
+}
+
+func (r *execResult) GetScriptExeID() string {
+	return r.scriptExeID
+}
+
+func (r *execResult) GetExecTime() string {
+	return r.execTime.String()
+}
+
+func (r *execResult) Inspect() string {
+	switch r.Type() {
+	case data.LIST, data.MAP:
+		jsonBytes, err := json.Marshal(r.value)
+		if err != nil {
+			r.logger.Error("Failed to marshal value to JSON", "error", err)
+			return fmt.Sprintf("%v", r.value)
+		}
+		return string(jsonBytes)
+	case data.NONE:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", r.value)
+	}
+}
+
+// Interface returns the Go native value returned by the expression
+func (r *execResult) Interface() any {
+	return r.value
+}
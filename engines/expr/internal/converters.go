@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/robbyt/go-polyscript/platform/constants"
+)
+
+// ConvertToExprFormat builds the environment for an evaluation. The input data is available as
+// the "ctx" variable, and each entry is also available as a top-level variable, which is used by
+// variables declared with the compiler's WithVariable option. Values are passed through
+// unchanged, since expr works with Go values directly.
+func ConvertToExprFormat(inputData map[string]any) map[string]any {
+	ctxMap := make(map[string]any, len(inputData))
+	env := make(map[string]any, len(inputData)+1)
+
+	for k, v := range inputData {
+		ctxMap[k] = v
+		env[k] = v
+	}
+
+	env[constants.Ctx] = ctxMap
+	return env
+}
+
+// ConvertExprValueToInterface converts a value returned by the expr VM into a value which can be
+// marshaled to JSON. Times and durations, e.g. from the now() and duration() builtins, are
+// converted to strings. Other values are returned unchanged.
+func ConvertExprValueToInterface(v any) any {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.String()
+	case []any:
+		list := make([]any, len(val))
+		for i, elem := range val {
+			list[i] = ConvertExprValueToInterface(elem)
+		}
+		return list
+	case map[string]any:
+		dict := make(map[string]any, len(val))
+		for k, elem := range val {
+			dict[k] = ConvertExprValueToInterface(elem)
+		}
+		return dict
+	default:
+		return v
+	}
+}
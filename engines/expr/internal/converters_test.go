@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToExprFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ctx and top-level entries", func(t *testing.T) {
+		input := map[string]any{
+			"name":  "World",
+			"items": []int{1, 2},
+		}
+
+		env := ConvertToExprFormat(input)
+		require.Equal(t, "World", env["name"])
+		require.Equal(t, []int{1, 2}, env["items"])
+		require.Equal(t, input, env["ctx"])
+	})
+
+	t.Run("ctx entry is not overwritten", func(t *testing.T) {
+		env := ConvertToExprFormat(map[string]any{"ctx": "shadowed"})
+		require.Equal(t, map[string]any{"ctx": "shadowed"}, env["ctx"])
+	})
+
+	t.Run("nil input", func(t *testing.T) {
+		env := ConvertToExprFormat(nil)
+		require.Equal(t, map[string]any{"ctx": map[string]any{}}, env)
+	})
+}
+
+func TestConvertExprValueToInterface(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{name: "nil", input: nil, want: nil},
+		{name: "int", input: 42, want: 42},
+		{name: "float", input: 1.5, want: 1.5},
+		{name: "string", input: "hi", want: "hi"},
+		{name: "time", input: ts, want: "2024-05-01T12:30:00Z"},
+		{name: "duration", input: 90 * time.Second, want: "1m30s"},
+		{
+			name:  "nested",
+			input: map[string]any{"at": ts, "list": []any{time.Minute, 1}},
+			want:  map[string]any{"at": "2024-05-01T12:30:00Z", "list": []any{"1m0s", 1}},
+		},
+		{name: "typed slice", input: []int{1, 2}, want: []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, ConvertExprValueToInterface(tt.input))
+		})
+	}
+}
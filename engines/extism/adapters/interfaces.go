@@ -23,3 +23,15 @@ type PluginInstance interface {
 	FunctionExists(name string) bool
 	Close(ctx context.Context) error
 }
+
+// ExportLister is implemented by plugin instances that can list the functions exported by
+// their WASM module.
+type ExportLister interface {
+	ExportedFunctions() []string
+}
+
+// VarClearer is implemented by plugin instances that can clear the Extism variables set by
+// previous calls. The WASM module's memory and globals are not cleared.
+type VarClearer interface {
+	ClearVars()
+}
@@ -7,6 +7,8 @@ package adapters
 import (
 	"context"
 	"crypto/rand"
+	"maps"
+	"slices"
 
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/tetratelabs/wazero"
@@ -92,7 +94,19 @@ func (a *sdkPluginAdapter) FunctionExists(name string) bool {
 	return a.instance.FunctionExists(name)
 }
 
+// ExportedFunctions returns the sorted names of the functions exported by the plugin's module
+func (a *sdkPluginAdapter) ExportedFunctions() []string {
+	return slices.Sorted(maps.Keys(a.instance.Module().ExportedFunctions()))
+}
+
 // Close releases resources associated with the instance
 func (a *sdkPluginAdapter) Close(ctx context.Context) error {
 	return a.instance.Close(ctx)
 }
+
+// ClearVars clears the plugin variables set by previous calls. Extism only resets its own
+// input and output memory between calls, so the module's linear memory, globals, and heap are
+// kept, and a reused instance can still see state from earlier calls.
+func (a *sdkPluginAdapter) ClearVars() {
+	clear(a.instance.Var)
+}
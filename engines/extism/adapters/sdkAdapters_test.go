@@ -17,6 +17,12 @@ func TestInterfaceImplementation(t *testing.T) {
 
 	// Verify pluginInstance interface is implemented by sdkPluginAdapter
 	var _ PluginInstance = (*sdkPluginAdapter)(nil)
+
+	// Verify the plugin adapter can clear its variables for reuse
+	var _ VarClearer = (*sdkPluginAdapter)(nil)
+
+	// Verify the plugin adapter can list its exports
+	var _ ExportLister = (*sdkPluginAdapter)(nil)
 }
 
 // Rather than creating complex mocks, let's focus on making the implementation simpler
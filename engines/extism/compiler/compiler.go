@@ -7,7 +7,9 @@ import (
 	"log/slog"
 
 	extismSDK "github.com/extism/go-sdk"
+	"github.com/robbyt/go-polyscript/engines/extism/adapters"
 	"github.com/robbyt/go-polyscript/engines/extism/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/script"
 )
 
@@ -16,8 +18,12 @@ type Compiler struct {
 	entryPointName string
 	ctx            context.Context
 	options        *compile.Settings
+	poolConfig     *PoolConfig
 	logHandler     slog.Handler
 	logger         *slog.Logger
+
+	// evalOptions configure the evaluators of the compiled script
+	evalOptions []platform.EvalOption
 }
 
 // New creates a new Extism WASM Compiler instance with the provided options.
@@ -113,6 +119,26 @@ func (c *Compiler) Compile(scriptReader io.ReadCloser) (script.ExecutableContent
 	if executable == nil {
 		return nil, ErrExecCreationFailed
 	}
+	executable.evalOptions = c.evalOptions
+
+	// Record the exported functions, so other exports can be called with the same plugin
+	if lister, ok := instance.(adapters.ExportLister); ok {
+		executable.exports = lister.ExportedFunctions()
+	}
+
+	if c.poolConfig != nil {
+		pool, err := NewInstancePool(c.ctx, c.logHandler, plugin, *c.poolConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrExecCreationFailed, err)
+		}
+		executable.pool = pool
+		logger.Debug("Created instance pool",
+			"minSize", c.poolConfig.MinSize,
+			"maxSize", c.poolConfig.MaxSize,
+			"maxUses", c.poolConfig.MaxUses,
+			"stateless", c.poolConfig.Stateless,
+		)
+	}
 
 	logger.Debug("WASM compilation completed")
 	return executable, nil
@@ -9,6 +9,7 @@ import (
 
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/robbyt/go-polyscript/engines/extism/wasmdata"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -123,6 +124,12 @@ func TestCompiler_Compile(t *testing.T) {
 			require.True(t, ok, "Expected *Executable type")
 			assert.Equal(t, wasmBytes, []byte(executable.GetSource()))
 
+			// the module's exports are recorded at compile time
+			assert.Contains(t, executable.GetExports(), wasmdata.EntrypointGreet)
+			assert.Contains(t, executable.GetExports(), wasmdata.EntrypointCountVowels)
+			assert.True(t, executable.HasExport(wasmdata.EntrypointReverseString))
+			assert.False(t, executable.HasExport("missing_function"))
+
 			plugin := executable.GetExtismByteCode()
 			require.NotNil(t, plugin)
 
@@ -188,6 +195,22 @@ func TestCompiler_Compile(t *testing.T) {
 			reader.AssertExpectations(t)
 		})
 
+		t.Run("memory limits", func(t *testing.T) {
+			comp, err := New(
+				WithEntryPoint(wasmdata.EntrypointGreet),
+				WithLogHandler(slog.NewTextHandler(io.Discard, nil)),
+				WithLimits(platform.Limits{MaxMemoryPages: 1024, MaxVarBytes: 1024}),
+			)
+			require.NoError(t, err)
+
+			reader := newMockScriptReaderCloser(wasmdata.TestModule)
+			reader.On("Close").Return(nil)
+
+			execContent, err := comp.Compile(reader)
+			require.NoError(t, err)
+			require.NotNil(t, execContent)
+		})
+
 		t.Run("custom compilation options", func(t *testing.T) {
 			wasmBytes := wasmdata.TestModule
 			entryPoint := "greet"
@@ -289,5 +312,22 @@ func TestCompiler_Compile(t *testing.T) {
 
 			reader.AssertExpectations(t)
 		})
+
+		t.Run("memory limit below module minimum", func(t *testing.T) {
+			comp, err := New(
+				WithEntryPoint(wasmdata.EntrypointGreet),
+				WithLogHandler(slog.NewTextHandler(io.Discard, nil)),
+				WithLimits(platform.Limits{MaxMemoryPages: 1}),
+			)
+			require.NoError(t, err)
+
+			reader := newMockScriptReaderCloser(wasmdata.TestModule)
+			reader.On("Close").Return(nil)
+
+			execContent, err := comp.Compile(reader)
+			require.Error(t, err)
+			require.Nil(t, execContent)
+			require.ErrorIs(t, err, ErrValidationFailed)
+		})
 	})
 }
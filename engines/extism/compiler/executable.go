@@ -3,22 +3,31 @@ package compiler
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
 
 	"github.com/robbyt/go-polyscript/engines/extism/adapters"
 	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
 )
 
 var ErrExecutableClosed = errors.New("executable is closed")
 
+// MachineType is the machine type of Extism executables. It's registered with the file
+// extensions of Extism modules by the extism package.
+const MachineType machineTypes.Type = "extism"
+
 // Executable implements script.ExecutableContent for Extism WASM modules
 type Executable struct {
 	scriptBytes []byte
 	ByteCode    adapters.CompiledPlugin
 	entryPoint  string
+	exports     []string
+	pool        *InstancePool
 	closed      atomic.Bool
 	rwMutex     sync.RWMutex
+	evalOptions []platform.EvalOption
 }
 
 // NewExecutable creates a new Executable instance
@@ -58,7 +67,7 @@ func (e *Executable) GetExtismByteCode() adapters.CompiledPlugin {
 
 // GetMachineType returns the Extism machine type
 func (e *Executable) GetMachineType() machineTypes.Type {
-	return machineTypes.Extism
+	return MachineType
 }
 
 // GetEntryPoint returns the name of the entry point function
@@ -66,13 +75,47 @@ func (e *Executable) GetEntryPoint() string {
 	return e.entryPoint
 }
 
-// Close implements io.Closer
+// GetExports returns the sorted names of the functions exported by the module, or nil when
+// the compiled plugin can't list them
+func (e *Executable) GetExports() []string {
+	return slices.Clone(e.exports)
+}
+
+// HasExport reports whether the module exports the named function. It returns true when the
+// exports are unknown, so the call itself reports a missing function.
+func (e *Executable) HasExport(name string) bool {
+	if e.exports == nil {
+		return true
+	}
+	_, found := slices.BinarySearch(e.exports, name)
+	return found
+}
+
+// GetInstancePool returns the pool of plugin instances, or nil when the module was compiled
+// without WithInstancePool
+func (e *Executable) GetInstancePool() *InstancePool {
+	e.rwMutex.RLock()
+	defer e.rwMutex.RUnlock()
+	return e.pool
+}
+
+// Close implements io.Closer, and closes the instance pool before the compiled plugin
 func (e *Executable) Close(ctx context.Context) error {
 	e.rwMutex.Lock()
 	defer e.rwMutex.Unlock()
 
 	if e.closed.CompareAndSwap(false, true) {
+		if e.pool != nil {
+			if err := e.pool.Close(ctx); err != nil {
+				return err
+			}
+		}
 		return e.ByteCode.Close(ctx)
 	}
 	return nil
 }
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *Executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
+}
@@ -6,7 +6,6 @@ import (
 
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/robbyt/go-polyscript/engines/extism/adapters"
-	machineTypes "github.com/robbyt/go-polyscript/engines/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -83,7 +82,7 @@ func TestExecutable(t *testing.T) {
 			assert.Equal(t, string(wasmBytes), exe.GetSource())
 			assert.Equal(t, mockPlugin, exe.GetByteCode())
 			assert.Equal(t, mockPlugin, exe.GetExtismByteCode())
-			assert.Equal(t, machineTypes.Extism, exe.GetMachineType())
+			assert.Equal(t, MachineType, exe.GetMachineType())
 			assert.Equal(t, entryPoint, exe.GetEntryPoint())
 			assert.False(t, exe.closed.Load())
 		})
@@ -130,7 +129,7 @@ func TestExecutable(t *testing.T) {
 
 		t.Run("GetMachineType", func(t *testing.T) {
 			machineType := exe.GetMachineType()
-			assert.Equal(t, machineTypes.Extism, machineType)
+			assert.Equal(t, MachineType, machineType)
 		})
 
 		t.Run("GetEntryPoint", func(t *testing.T) {
@@ -139,6 +138,33 @@ func TestExecutable(t *testing.T) {
 		})
 	})
 
+	// Test export lookups
+	t.Run("Exports", func(t *testing.T) {
+		wasmBytes := []byte("mock wasm bytes")
+		mockPlugin := new(MockCompiledPlugin)
+
+		t.Run("unknown exports", func(t *testing.T) {
+			exe := NewExecutable(wasmBytes, mockPlugin, "run")
+			require.NotNil(t, exe)
+			assert.Nil(t, exe.GetExports())
+			assert.True(t, exe.HasExport("anything"))
+		})
+
+		t.Run("known exports", func(t *testing.T) {
+			exe := NewExecutable(wasmBytes, mockPlugin, "run")
+			require.NotNil(t, exe)
+			exe.exports = []string{"run", "score", "validate"}
+
+			assert.Equal(t, []string{"run", "score", "validate"}, exe.GetExports())
+			assert.True(t, exe.HasExport("score"))
+			assert.False(t, exe.HasExport("transform"))
+
+			// callers get a copy of the exports
+			exe.GetExports()[0] = "changed"
+			assert.Equal(t, "run", exe.GetExports()[0])
+		})
+	})
+
 	// Test Close functionality (specific to Extism)
 	t.Run("Close", func(t *testing.T) {
 		ctx := t.Context()
@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	extismSDK "github.com/extism/go-sdk"
+	"github.com/robbyt/go-polyscript/platform"
+)
+
+// hostFunctionOutput is written to the plugin's memory after a host function call
+type hostFunctionOutput struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// NewHostFunctions adapts host functions so they can be passed to WithHostFunctions. The WASM
+// module imports each function by name from the "extism:host/user" namespace. A function takes
+// the offset of a block of Extism memory holding a JSON array of arguments, and returns the
+// offset of a block holding a JSON object with either the function's "result", or an "error"
+// message.
+func NewHostFunctions(funcs ...*platform.HostFunction) []extismSDK.HostFunction {
+	hostFuncs := make([]extismSDK.HostFunction, 0, len(funcs))
+	for _, fn := range funcs {
+		hostFuncs = append(hostFuncs, newHostFunction(fn))
+	}
+	return hostFuncs
+}
+
+// newHostFunction adapts one host function to the Extism calling convention
+func newHostFunction(fn *platform.HostFunction) extismSDK.HostFunction {
+	return extismSDK.NewHostFunctionWithStack(
+		fn.Name(),
+		func(ctx context.Context, p *extismSDK.CurrentPlugin, stack []uint64) {
+			output := callHostFunction(ctx, fn, p, stack[0])
+			data, err := json.Marshal(output)
+			if err != nil {
+				// The result came from json.Marshal, so this only fails on a bug
+				panic(fmt.Sprintf("failed to marshal host function output: %v", err))
+			}
+
+			offset, err := p.WriteBytes(data)
+			if err != nil {
+				panic(fmt.Sprintf("failed to write host function output: %v", err))
+			}
+			stack[0] = offset
+		},
+		[]extismSDK.ValueType{extismSDK.ValueTypePTR},
+		[]extismSDK.ValueType{extismSDK.ValueTypePTR},
+	)
+}
+
+// callHostFunction reads the arguments from the plugin's memory, and calls the function
+func callHostFunction(
+	ctx context.Context,
+	fn *platform.HostFunction,
+	p *extismSDK.CurrentPlugin,
+	offset uint64,
+) hostFunctionOutput {
+	input, err := p.ReadBytes(offset)
+	if err != nil {
+		return hostFunctionOutput{Error: fmt.Sprintf("failed to read arguments: %v", err)}
+	}
+
+	result, err := fn.CallJSON(ctx, input)
+	if err != nil {
+		return hostFunctionOutput{Error: err.Error()}
+	}
+	return hostFunctionOutput{Result: result}
+}
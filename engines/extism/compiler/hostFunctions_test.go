@@ -0,0 +1,103 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/engines/extism/adapters"
+	"github.com/robbyt/go-polyscript/engines/extism/wasmdata"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHostFunctions(t *testing.T) {
+	t.Parallel()
+
+	type tenantKey struct{}
+	type sum struct {
+		Tenant string `json:"tenant"`
+		Total  int    `json:"total"`
+	}
+
+	tests := []struct {
+		name  string
+		fn    any
+		input string
+		want  string
+	}{
+		{
+			name: "typed arguments and result",
+			fn: func(ctx context.Context, a, b int) (sum, error) {
+				tenant, _ := ctx.Value(tenantKey{}).(string)
+				return sum{Tenant: tenant, Total: a + b}, nil
+			},
+			input: `[2, 3]`,
+			want:  `{"result":{"tenant":"acme","total":5}}`,
+		},
+		{
+			name: "function error",
+			fn: func(ctx context.Context) error {
+				return errors.New("service unavailable")
+			},
+			input: `[]`,
+			want:  `{"error":"service unavailable"}`,
+		},
+		{
+			name: "wrong number of arguments",
+			fn: func(ctx context.Context, a, b int) (int, error) {
+				return a + b, nil
+			},
+			input: `[1]`,
+			want:  `{"error":"host function \"bridge\" takes 2 arguments, got 1"}`,
+		},
+		{
+			name: "arguments are not an array",
+			fn: func(ctx context.Context, a int) (int, error) {
+				return a, nil
+			},
+			input: `{"a": 1}`,
+			want: `{"error":"arguments for host function \"bridge\" must be a JSON array, ` +
+				`got map[string]interface {}"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bridge, err := platform.NewHostFunction(wasmdata.HostFunctionBridge, tt.fn)
+			require.NoError(t, err)
+
+			comp, err := New(
+				WithEntryPoint(wasmdata.EntrypointCallBridge),
+				WithHostFunctions(NewHostFunctions(bridge)),
+			)
+			require.NoError(t, err)
+
+			reader := newMockScriptReaderCloser(wasmdata.BridgeModule)
+			reader.On("Close").Return(nil)
+			content, err := comp.Compile(reader)
+			require.NoError(t, err)
+
+			exe, ok := content.(*Executable)
+			require.True(t, ok)
+			defer func() { assert.NoError(t, exe.Close(t.Context())) }()
+
+			ctx := context.WithValue(t.Context(), tenantKey{}, "acme")
+			instance, err := exe.GetExtismByteCode().Instance(ctx, adapters.NewPluginInstanceConfig())
+			require.NoError(t, err)
+			defer func() { assert.NoError(t, instance.Close(ctx)) }()
+
+			exit, output, err := instance.CallWithContext(
+				ctx,
+				wasmdata.EntrypointCallBridge,
+				[]byte(tt.input),
+			)
+			require.NoError(t, err)
+			require.Equal(t, uint32(0), exit)
+			assert.JSONEq(t, tt.want, string(output))
+		})
+	}
+}
@@ -45,14 +45,7 @@ func compile(
 		opts = WithDefaultCompileSettings()
 	}
 
-	// Create manifest from wasm bytes
-	manifest := extismSDK.Manifest{
-		Wasm: []extismSDK.Wasm{
-			extismSDK.WasmData{
-				Data: wasmBytes,
-			},
-		},
-	}
+	manifest := newManifest(wasmBytes, opts)
 
 	// Configure the plugin
 	config := extismSDK.PluginConfig{
@@ -69,3 +62,19 @@ func compile(
 	// Wrap the SDK plugin with our adapter
 	return adapters.NewCompiledPluginAdapter(plugin), nil
 }
+
+// newManifest creates the plugin manifest for the WASM bytes and settings
+func newManifest(wasmBytes []byte, opts *Settings) extismSDK.Manifest {
+	return extismSDK.Manifest{
+		Wasm: []extismSDK.Wasm{
+			extismSDK.WasmData{
+				Data: wasmBytes,
+			},
+		},
+		Memory:       opts.Memory,
+		Config:       opts.Config,
+		AllowedHosts: opts.AllowedHosts,
+		AllowedPaths: opts.AllowedPaths,
+		Timeout:      uint64(opts.Timeout.Milliseconds()),
+	}
+}
@@ -312,3 +312,49 @@ func TestCompileWithHostFunctions(t *testing.T) {
 
 	assert.NotNil(t, instance)
 }
+
+func TestNewManifest(t *testing.T) {
+	t.Parallel()
+
+	opts := &Settings{
+		Memory:       &extismSDK.ManifestMemory{MaxPages: 16, MaxHttpResponseBytes: -1, MaxVarBytes: -1},
+		AllowedHosts: []string{"api.example.com"},
+		AllowedPaths: map[string]string{"/srv/data": "/data"},
+		Config:       map[string]string{"greeting": "hi"},
+		Timeout:      1500 * time.Millisecond,
+	}
+
+	manifest := newManifest(wasmdata.TestModule, opts)
+	require.Len(t, manifest.Wasm, 1)
+	assert.Equal(t, opts.Memory, manifest.Memory)
+	assert.Equal(t, opts.AllowedHosts, manifest.AllowedHosts)
+	assert.Equal(t, opts.AllowedPaths, manifest.AllowedPaths)
+	assert.Equal(t, opts.Config, manifest.Config)
+	assert.Equal(t, uint64(1500), manifest.Timeout)
+
+	manifest = newManifest(wasmdata.TestModule, WithDefaultCompileSettings())
+	assert.Nil(t, manifest.Memory)
+	assert.Empty(t, manifest.AllowedHosts)
+	assert.Empty(t, manifest.Config)
+	assert.Zero(t, manifest.Timeout)
+}
+
+func TestCompileWithConfig(t *testing.T) {
+	ctx := t.Context()
+
+	opts := WithDefaultCompileSettings()
+	opts.Config = map[string]string{"greeting": "Hello from config"}
+
+	plugin, err := CompileBytes(ctx, wasmdata.ConfigModule, opts)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, plugin.Close(ctx)) }()
+
+	instance, err := plugin.Instance(ctx, extismSDK.PluginInstanceConfig{})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, instance.Close(ctx)) }()
+
+	exit, output, err := instance.Call(wasmdata.EntrypointGetGreeting, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), exit)
+	assert.Equal(t, "Hello from config", string(output))
+}
@@ -1,6 +1,8 @@
 package compile
 
 import (
+	"time"
+
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/tetratelabs/wazero"
 )
@@ -13,12 +15,22 @@ type Settings struct {
 	RuntimeConfig wazero.RuntimeConfig
 	// HostFunctions are additional host functions to be registered with the plugin
 	HostFunctions []extismSDK.HostFunction
+	// Memory sets the manifest's memory limits, when not nil
+	Memory *extismSDK.ManifestMemory
+	// AllowedHosts are the hosts the plugin can send HTTP requests to, and may use wildcards
+	AllowedHosts []string
+	// AllowedPaths maps host directories to the paths where the plugin can access them
+	AllowedPaths map[string]string
+	// Config holds static key/value pairs that the plugin reads with the Extism config functions
+	Config map[string]string
+	// Timeout bounds each call into the plugin, when greater than zero
+	Timeout time.Duration
 }
 
 // WithDefaultCompileSettings returns the default compilation options
 func WithDefaultCompileSettings() *Settings {
 	return &Settings{
 		EnableWASI:    true,
-		RuntimeConfig: wazero.NewRuntimeConfig(),
+		RuntimeConfig: wazero.NewRuntimeConfig().WithCloseOnContextDone(true),
 	}
 }
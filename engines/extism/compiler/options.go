@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"time"
 
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/robbyt/go-polyscript/engines/extism/compiler/internal/compile"
 	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/tetratelabs/wazero"
 )
 
@@ -26,6 +30,17 @@ func WithEntryPoint(entryPoint string) FunctionalOption {
 	}
 }
 
+// WithEvalOptions creates an option to configure the evaluators of the compiled script, e.g.
+// platform.WithDefaultTimeout. It sets evaluation options through helpers that only take
+// compiler options, such as polyscript.FromExtismFile. Options given to the evaluator itself
+// take precedence.
+func WithEvalOptions(opts ...platform.EvalOption) FunctionalOption {
+	return func(c *Compiler) error {
+		c.evalOptions = append(c.evalOptions, opts...)
+		return nil
+	}
+}
+
 // WithLogHandler creates an option to set the log handler for Extism compiler.
 // This is the preferred option for logging configuration as it provides
 // more flexibility through the slog.Handler interface.
@@ -67,7 +82,9 @@ func WithWASIEnabled(enabled bool) FunctionalOption {
 	}
 }
 
-// WithRuntimeConfig creates an option to set a custom wazero runtime configuration
+// WithRuntimeConfig creates an option to set a custom wazero runtime configuration. The config
+// replaces the default, so it should enable WithCloseOnContextDone for cancellation and
+// deadlines to interrupt running WASM functions.
 func WithRuntimeConfig(config wazero.RuntimeConfig) FunctionalOption {
 	return func(c *Compiler) error {
 		if config == nil {
@@ -92,6 +109,147 @@ func WithHostFunctions(funcs []extismSDK.HostFunction) FunctionalOption {
 	}
 }
 
+// WithLimits creates an option to set resource limits in the plugin manifest. Extism enforces
+// MaxMemoryPages, MaxHTTPResponseBytes and MaxVarBytes; limits left at zero keep the Extism
+// defaults.
+func WithLimits(limits platform.Limits) FunctionalOption {
+	return func(c *Compiler) error {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+		if c.options == nil {
+			c.options = &compile.Settings{}
+		}
+		c.options.Memory = newManifestMemory(limits)
+		return nil
+	}
+}
+
+// WithAllowedHosts creates an option to set the hosts the plugin can send HTTP requests to.
+// Hosts may use wildcards, e.g. "*.example.com". Without allowed hosts, HTTP requests from the
+// plugin are rejected.
+func WithAllowedHosts(hosts ...string) FunctionalOption {
+	return func(c *Compiler) error {
+		for _, host := range hosts {
+			if host == "" {
+				return fmt.Errorf("allowed host cannot be empty")
+			}
+		}
+		if c.options == nil {
+			c.options = &compile.Settings{}
+		}
+		c.options.AllowedHosts = slices.Clone(hosts)
+		return nil
+	}
+}
+
+// WithAllowedPaths creates an option to give the plugin access to host directories. The map
+// keys are paths on the host, and the values are the paths where the plugin sees them. A host
+// path prefixed with "ro:" is mounted read-only. Paths are only available with WASI enabled.
+func WithAllowedPaths(paths map[string]string) FunctionalOption {
+	return func(c *Compiler) error {
+		for hostPath, guestPath := range paths {
+			if hostPath == "" || guestPath == "" {
+				return fmt.Errorf("allowed paths cannot be empty, got %q: %q", hostPath, guestPath)
+			}
+		}
+		if c.options == nil {
+			c.options = &compile.Settings{}
+		}
+		c.options.AllowedPaths = maps.Clone(paths)
+		return nil
+	}
+}
+
+// WithConfig creates an option to set static key/value configuration for the plugin, which it
+// reads with the Extism config functions.
+func WithConfig(config map[string]string) FunctionalOption {
+	return func(c *Compiler) error {
+		if c.options == nil {
+			c.options = &compile.Settings{}
+		}
+		c.options.Config = maps.Clone(config)
+		return nil
+	}
+}
+
+// WithTimeout creates an option to bound every call into the plugin, in addition to any
+// deadline on the context passed to Eval. Extism measures the timeout in milliseconds.
+// A timeout of zero disables it.
+func WithTimeout(timeout time.Duration) FunctionalOption {
+	return func(c *Compiler) error {
+		if timeout < 0 {
+			return fmt.Errorf("timeout cannot be negative, got %s", timeout)
+		}
+		if timeout > 0 && timeout < time.Millisecond {
+			return fmt.Errorf("timeout must be at least 1ms, got %s", timeout)
+		}
+		if c.options == nil {
+			c.options = &compile.Settings{}
+		}
+		c.options.Timeout = timeout
+		return nil
+	}
+}
+
+// newManifestMemory converts limits to the manifest's memory settings, or returns nil when none
+// of the WASM limits are set. Extism treats a zero size as a limit of zero bytes, so unset
+// sizes are passed as -1 to keep the Extism default.
+func newManifestMemory(limits platform.Limits) *extismSDK.ManifestMemory {
+	if limits.MaxMemoryPages == 0 && limits.MaxHTTPResponseBytes == 0 && limits.MaxVarBytes == 0 {
+		return nil
+	}
+
+	memory := &extismSDK.ManifestMemory{
+		MaxPages:             limits.MaxMemoryPages,
+		MaxHttpResponseBytes: limits.MaxHTTPResponseBytes,
+		MaxVarBytes:          limits.MaxVarBytes,
+	}
+	if memory.MaxHttpResponseBytes == 0 {
+		memory.MaxHttpResponseBytes = -1
+	}
+	if memory.MaxVarBytes == 0 {
+		memory.MaxVarBytes = -1
+	}
+	return memory
+}
+
+// WithInstancePool creates an option to keep a pool of plugin instances for each compiled
+// module, instead of instantiating the module on every evaluation. minSize instances are
+// created when the module is compiled, at most maxSize instances exist at once, and idle
+// instances above minSize are closed after idleTimeout. An idleTimeout of zero keeps idle
+// instances until the executable is closed. Each instance serves one call, so a plugin that
+// keeps state in memory never sees an earlier call's state, and a replacement is created in the
+// background to keep minSize instances warm. Use WithInstancePoolConfig with PoolConfig.Stateless
+// or PoolConfig.MaxUses to reuse instances across calls, which is only safe for stateless plugins.
+func WithInstancePool(minSize, maxSize int, idleTimeout time.Duration) FunctionalOption {
+	return func(c *Compiler) error {
+		config := PoolConfig{
+			MinSize:     minSize,
+			MaxSize:     maxSize,
+			IdleTimeout: idleTimeout,
+		}
+		if err := config.validate(); err != nil {
+			return err
+		}
+		c.poolConfig = &config
+		return nil
+	}
+}
+
+// WithInstancePoolConfig creates an option to keep a pool of plugin instances for each compiled
+// module, like WithInstancePool, with every setting of PoolConfig, including how many calls an
+// instance serves before it's replaced.
+func WithInstancePoolConfig(config PoolConfig) FunctionalOption {
+	return func(c *Compiler) error {
+		if err := config.validate(); err != nil {
+			return err
+		}
+		c.poolConfig = &config
+		return nil
+	}
+}
+
 // WithContext creates an option to set a custom context for the Extism compiler.
 func WithContext(ctx context.Context) FunctionalOption {
 	return func(c *Compiler) error {
@@ -120,9 +278,11 @@ func (c *Compiler) applyDefaults() {
 		c.options = &compile.Settings{}
 	}
 
-	// Set default runtime config if not already set
+	// Set default runtime config if not already set. Closing the module when the context is
+	// done lets cancellation and deadlines interrupt a running WASM function, such as an
+	// infinite loop, instead of waiting for it to return.
 	if c.options.RuntimeConfig == nil {
-		c.options.RuntimeConfig = wazero.NewRuntimeConfig()
+		c.options.RuntimeConfig = wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
 	}
 
 	// Set default host functions if not already set
@@ -5,9 +5,11 @@ import (
 	"context"
 	"log/slog"
 	"testing"
+	"time"
 
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/robbyt/go-polyscript/engines/extism/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/stretchr/testify/require"
 	"github.com/tetratelabs/wazero"
@@ -352,6 +354,176 @@ func TestCompilerOptions_Options(t *testing.T) {
 			require.Contains(t, err.Error(), "context cannot be nil")
 		})
 	})
+
+	// WithLimits tests
+	t.Run("WithLimits", func(t *testing.T) {
+		t.Run("memory limits", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithLimits(platform.Limits{MaxMemoryPages: 16, MaxVarBytes: 1024})(c)
+
+			require.NoError(t, err)
+			require.Equal(t, &extismSDK.ManifestMemory{
+				MaxPages:             16,
+				MaxHttpResponseBytes: -1,
+				MaxVarBytes:          1024,
+			}, c.options.Memory)
+		})
+
+		t.Run("no WASM limits", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithLimits(platform.Limits{MaxSteps: 100})(c)
+
+			require.NoError(t, err)
+			require.Nil(t, c.options.Memory)
+		})
+
+		t.Run("invalid limits", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithLimits(platform.Limits{MaxVarBytes: -1})(c)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid limits")
+		})
+	})
+
+	// WithAllowedHosts tests
+	t.Run("WithAllowedHosts", func(t *testing.T) {
+		t.Run("valid hosts", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			hosts := []string{"api.example.com", "*.example.org"}
+			err := WithAllowedHosts(hosts...)(c)
+
+			require.NoError(t, err)
+			require.Equal(t, hosts, c.options.AllowedHosts)
+
+			// the option keeps its own copy
+			hosts[0] = "changed"
+			require.Equal(t, "api.example.com", c.options.AllowedHosts[0])
+		})
+
+		t.Run("empty host", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithAllowedHosts("api.example.com", "")(c)
+
+			require.Error(t, err)
+			require.Nil(t, c.options.AllowedHosts)
+		})
+	})
+
+	// WithAllowedPaths tests
+	t.Run("WithAllowedPaths", func(t *testing.T) {
+		t.Run("valid paths", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			paths := map[string]string{"/srv/data": "/data", "ro:/etc/app": "/config"}
+			err := WithAllowedPaths(paths)(c)
+
+			require.NoError(t, err)
+			require.Equal(t, paths, c.options.AllowedPaths)
+		})
+
+		t.Run("empty guest path", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithAllowedPaths(map[string]string{"/srv/data": ""})(c)
+
+			require.Error(t, err)
+			require.Nil(t, c.options.AllowedPaths)
+		})
+	})
+
+	// WithConfig tests
+	t.Run("WithConfig", func(t *testing.T) {
+		c := &Compiler{}
+		c.applyDefaults()
+		config := map[string]string{"region": "eu-west-1"}
+		err := WithConfig(config)(c)
+
+		require.NoError(t, err)
+		require.Equal(t, config, c.options.Config)
+
+		config["region"] = "changed"
+		require.Equal(t, "eu-west-1", c.options.Config["region"])
+	})
+
+	// WithTimeout tests
+	t.Run("WithTimeout", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			timeout time.Duration
+			wantErr bool
+		}{
+			{name: "valid timeout", timeout: time.Second},
+			{name: "disabled", timeout: 0},
+			{name: "negative", timeout: -time.Second, wantErr: true},
+			{name: "below a millisecond", timeout: time.Microsecond, wantErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c := &Compiler{}
+				c.applyDefaults()
+				err := WithTimeout(tt.timeout)(c)
+
+				if tt.wantErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.timeout, c.options.Timeout)
+			})
+		}
+	})
+
+	// WithInstancePool tests
+	t.Run("WithInstancePool", func(t *testing.T) {
+		t.Run("valid pool", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithInstancePool(1, 4, time.Minute)(c)
+
+			require.NoError(t, err)
+			require.NotNil(t, c.poolConfig)
+			require.Equal(t, PoolConfig{MinSize: 1, MaxSize: 4, IdleTimeout: time.Minute}, *c.poolConfig)
+		})
+
+		t.Run("invalid sizes", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithInstancePool(4, 1, 0)(c)
+
+			require.Error(t, err)
+			require.Nil(t, c.poolConfig)
+		})
+	})
+
+	// WithInstancePoolConfig tests
+	t.Run("WithInstancePoolConfig", func(t *testing.T) {
+		t.Run("valid pool", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			config := PoolConfig{MinSize: 1, MaxSize: 4, MaxUses: 100}
+			err := WithInstancePoolConfig(config)(c)
+
+			require.NoError(t, err)
+			require.NotNil(t, c.poolConfig)
+			require.Equal(t, config, *c.poolConfig)
+		})
+
+		t.Run("invalid max uses", func(t *testing.T) {
+			c := &Compiler{}
+			c.applyDefaults()
+			err := WithInstancePoolConfig(PoolConfig{MaxSize: 1, MaxUses: -1})(c)
+
+			require.Error(t, err)
+			require.Nil(t, c.poolConfig)
+		})
+	})
 }
 
 // TestCompilerOptions_SetupLogger tests the setupLogger method
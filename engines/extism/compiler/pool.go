@@ -0,0 +1,353 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robbyt/go-polyscript/engines/extism/adapters"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+)
+
+var ErrPoolClosed = errors.New("instance pool is closed")
+
+// PoolConfig configures the pool of plugin instances kept by an Executable
+type PoolConfig struct {
+	// MinSize is the number of idle instances created up front, and kept warm after eviction.
+	MinSize int
+
+	// MaxSize is the maximum number of instances, idle or in use. Callers wait for an
+	// instance to be released when the pool is at its maximum.
+	MaxSize int
+
+	// IdleTimeout is how long an instance above MinSize can stay idle before it's closed.
+	// Zero disables idle eviction.
+	IdleTimeout time.Duration
+
+	// MaxUses is how many calls an instance serves before it's closed, and replaced by a new
+	// instance. A reused instance keeps the module's linear memory, globals, and heap from
+	// earlier calls, so it's only safe for plugins that don't keep state between calls. When
+	// zero, every call gets a fresh instance, unless Stateless is set.
+	MaxUses int
+
+	// Stateless declares that the plugin doesn't keep state in memory between calls, so a zero
+	// MaxUses reuses instances until they're evicted. A plugin that does keep state, such as a
+	// counter in a global, would see the state of earlier calls, which can belong to other
+	// callers.
+	Stateless bool
+}
+
+// maxUses returns how many calls an instance serves, or zero when there's no limit
+func (c PoolConfig) maxUses() int {
+	switch {
+	case c.MaxUses > 0:
+		return c.MaxUses
+	case c.Stateless:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// validate checks that the pool sizes are consistent
+func (c PoolConfig) validate() error {
+	if c.MaxSize < 1 {
+		return fmt.Errorf("pool max size must be at least 1, got %d", c.MaxSize)
+	}
+	if c.MinSize < 0 {
+		return fmt.Errorf("pool min size cannot be negative, got %d", c.MinSize)
+	}
+	if c.MinSize > c.MaxSize {
+		return fmt.Errorf("pool min size %d is larger than max size %d", c.MinSize, c.MaxSize)
+	}
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("pool idle timeout cannot be negative")
+	}
+	if c.MaxUses < 0 {
+		return fmt.Errorf("pool max uses cannot be negative, got %d", c.MaxUses)
+	}
+	return nil
+}
+
+// idleInstance is a pooled instance, and when it was released
+type idleInstance struct {
+	instance adapters.PluginInstance
+	since    time.Time
+}
+
+// InstancePool keeps pre-instantiated plugin instances for an Executable, so each evaluation
+// doesn't pay the cost of instantiating the WASM module. An instance is used by one caller at
+// a time. By default, an instance is closed after one call, and a new one is created in the
+// background to keep MinSize instances warm. Reusing instances is set by PoolConfig.MaxUses and
+// PoolConfig.Stateless; a reused instance's Extism variables are cleared before it's returned
+// to the pool, but the module's memory is kept until the instance is replaced.
+type InstancePool struct {
+	plugin adapters.CompiledPlugin
+	config PoolConfig
+	logger *slog.Logger
+
+	// slots limits the number of live instances to MaxSize
+	slots chan struct{}
+
+	// mu guards the idle instances, the use counts, and the closed flag
+	mu     sync.Mutex
+	idle   []idleInstance
+	uses   map[adapters.PluginInstance]int
+	closed bool
+
+	stopEviction context.CancelFunc
+	evictionDone chan struct{}
+
+	// replenishing tracks the instances being created to replace closed ones
+	replenishing sync.WaitGroup
+}
+
+// NewInstancePool creates a pool for the compiled plugin, and creates MinSize instances
+func NewInstancePool(
+	ctx context.Context,
+	handler slog.Handler,
+	plugin adapters.CompiledPlugin,
+	config PoolConfig,
+) (*InstancePool, error) {
+	if plugin == nil {
+		return nil, fmt.Errorf("compiled plugin is nil")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	_, logger := helpers.SetupLogger(handler, "extism", "InstancePool")
+	p := &InstancePool{
+		plugin: plugin,
+		config: config,
+		logger: logger,
+		slots:  make(chan struct{}, config.MaxSize),
+		idle:   make([]idleInstance, 0, config.MaxSize),
+		uses:   make(map[adapters.PluginInstance]int, config.MaxSize),
+	}
+
+	for range config.MinSize {
+		instance, err := p.newInstance(ctx)
+		if err != nil {
+			p.closeIdle(ctx)
+			return nil, fmt.Errorf("failed to warm instance pool: %w", err)
+		}
+		p.idle = append(p.idle, idleInstance{instance: instance, since: time.Now()})
+	}
+
+	if config.IdleTimeout > 0 {
+		evictCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		p.stopEviction = cancel
+		p.evictionDone = make(chan struct{})
+		go p.runEviction(evictCtx)
+	}
+
+	return p, nil
+}
+
+// newInstance creates a new plugin instance
+func (p *InstancePool) newInstance(ctx context.Context) (adapters.PluginInstance, error) {
+	return p.plugin.Instance(ctx, adapters.NewPluginInstanceConfig())
+}
+
+// Get returns an idle instance, or creates one when none are idle. When MaxSize instances are
+// in use, Get waits until one is released or the context is done.
+func (p *InstancePool) Get(ctx context.Context) (adapters.PluginInstance, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a plugin instance: %w", ctx.Err())
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.slots
+		return nil, ErrPoolClosed
+	}
+	if n := len(p.idle); n > 0 {
+		// Use the most recently released instance, so older ones can be evicted
+		entry := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return entry.instance, nil
+	}
+	p.mu.Unlock()
+
+	instance, err := p.newInstance(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, fmt.Errorf("failed to create plugin instance: %w", err)
+	}
+	return instance, nil
+}
+
+// Put clears the instance's variables, and returns it to the pool. Instances that have served
+// MaxUses calls, or that are returned after the pool is closed, are closed instead.
+func (p *InstancePool) Put(ctx context.Context, instance adapters.PluginInstance) {
+	if instance == nil {
+		return
+	}
+	defer func() { <-p.slots }()
+
+	if c, ok := instance.(adapters.VarClearer); ok {
+		c.ClearVars()
+	}
+
+	p.mu.Lock()
+	p.uses[instance]++
+	uses := p.uses[instance]
+	maxUses := p.config.maxUses()
+	recycle := maxUses > 0 && uses >= maxUses
+	if p.closed || recycle {
+		delete(p.uses, instance)
+		closed := p.closed
+		p.mu.Unlock()
+		p.closeInstance(ctx, instance)
+		if !closed {
+			p.logger.Debug("Closed plugin instance after its last use", "uses", uses)
+			p.replenish(ctx)
+		}
+		return
+	}
+	p.idle = append(p.idle, idleInstance{instance: instance, since: time.Now()})
+	p.mu.Unlock()
+}
+
+// replenish creates an instance in the background to replace one that was closed, when fewer
+// than MinSize instances are idle
+func (p *InstancePool) replenish(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || len(p.idle) >= p.config.MinSize {
+		return
+	}
+
+	p.replenishing.Add(1)
+	go func() {
+		defer p.replenishing.Done()
+		ctx := context.WithoutCancel(ctx)
+		instance, err := p.newInstance(ctx)
+		if err != nil {
+			p.logger.Warn("Failed to replace plugin instance", "error", err)
+			return
+		}
+
+		p.mu.Lock()
+		if p.closed || len(p.idle) >= p.config.MinSize {
+			p.mu.Unlock()
+			p.closeInstance(ctx, instance)
+			return
+		}
+		p.idle = append(p.idle, idleInstance{instance: instance, since: time.Now()})
+		p.mu.Unlock()
+	}()
+}
+
+// Discard closes an instance that shouldn't be reused, such as one that failed or was
+// interrupted during a call, and frees its place in the pool.
+func (p *InstancePool) Discard(ctx context.Context, instance adapters.PluginInstance) {
+	if instance == nil {
+		return
+	}
+	defer func() { <-p.slots }()
+
+	p.mu.Lock()
+	delete(p.uses, instance)
+	p.mu.Unlock()
+	p.closeInstance(ctx, instance)
+}
+
+// Idle returns the number of idle instances in the pool
+func (p *InstancePool) Idle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle)
+}
+
+// InUse returns the number of instances currently checked out of the pool
+func (p *InstancePool) InUse() int {
+	return len(p.slots)
+}
+
+// Close closes the idle instances, and stops idle eviction. Instances still in use are closed
+// when they're returned.
+func (p *InstancePool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	if p.stopEviction != nil {
+		p.stopEviction()
+		<-p.evictionDone
+	}
+	p.replenishing.Wait()
+	p.closeIdle(ctx)
+	return nil
+}
+
+// closeIdle closes and removes all idle instances
+func (p *InstancePool) closeIdle(ctx context.Context) {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	for _, entry := range idle {
+		delete(p.uses, entry.instance)
+	}
+	p.mu.Unlock()
+
+	for _, entry := range idle {
+		p.closeInstance(ctx, entry.instance)
+	}
+}
+
+// closeInstance closes an instance, and logs any error
+func (p *InstancePool) closeInstance(ctx context.Context, instance adapters.PluginInstance) {
+	if err := instance.Close(ctx); err != nil {
+		p.logger.Warn("Failed to close Extism plugin instance", "error", err)
+	}
+}
+
+// runEviction periodically closes instances that have been idle longer than IdleTimeout
+func (p *InstancePool) runEviction(ctx context.Context) {
+	defer close(p.evictionDone)
+
+	ticker := time.NewTicker(max(p.config.IdleTimeout/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.evictIdle(ctx, now)
+		}
+	}
+}
+
+// evictIdle closes instances idle since before the timeout, keeping at least MinSize
+func (p *InstancePool) evictIdle(ctx context.Context, now time.Time) {
+	p.mu.Lock()
+	var expired []adapters.PluginInstance
+	// The idle slice is ordered by release time, so the oldest instances are first
+	for len(p.idle) > p.config.MinSize && now.Sub(p.idle[0].since) >= p.config.IdleTimeout {
+		expired = append(expired, p.idle[0].instance)
+		delete(p.uses, p.idle[0].instance)
+		p.idle = p.idle[1:]
+	}
+	p.mu.Unlock()
+
+	for _, instance := range expired {
+		p.closeInstance(ctx, instance)
+	}
+	if len(expired) > 0 {
+		p.logger.Debug("Evicted idle plugin instances", "count", len(expired))
+	}
+}
@@ -0,0 +1,383 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	extismSDK "github.com/extism/go-sdk"
+	"github.com/robbyt/go-polyscript/engines/extism/adapters"
+	"github.com/robbyt/go-polyscript/engines/extism/wasmdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInstance is a plugin instance that records cleared variables and closes
+type fakeInstance struct {
+	clears atomic.Int32
+	closed atomic.Bool
+}
+
+func (f *fakeInstance) Call(name string, data []byte) (uint32, []byte, error) {
+	return 0, data, nil
+}
+
+func (f *fakeInstance) CallWithContext(
+	ctx context.Context,
+	name string,
+	data []byte,
+) (uint32, []byte, error) {
+	return 0, data, nil
+}
+
+func (f *fakeInstance) FunctionExists(name string) bool { return true }
+
+func (f *fakeInstance) Close(ctx context.Context) error {
+	f.closed.Store(true)
+	return nil
+}
+
+func (f *fakeInstance) ClearVars() {
+	f.clears.Add(1)
+}
+
+// fakePlugin is a compiled plugin that creates fakeInstances
+type fakePlugin struct {
+	mu        sync.Mutex
+	instances []*fakeInstance
+	err       error
+}
+
+func (f *fakePlugin) Instance(
+	ctx context.Context,
+	config extismSDK.PluginInstanceConfig,
+) (adapters.PluginInstance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	instance := &fakeInstance{}
+	f.instances = append(f.instances, instance)
+	return instance, nil
+}
+
+func (f *fakePlugin) Close(ctx context.Context) error { return nil }
+
+func (f *fakePlugin) created() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.instances)
+}
+
+func TestPoolConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  PoolConfig
+		wantErr bool
+	}{
+		{name: "valid", config: PoolConfig{MinSize: 1, MaxSize: 4, IdleTimeout: time.Minute}},
+		{name: "no warm instances", config: PoolConfig{MaxSize: 1}},
+		{name: "zero max size", config: PoolConfig{MaxSize: 0}, wantErr: true},
+		{name: "negative min size", config: PoolConfig{MinSize: -1, MaxSize: 1}, wantErr: true},
+		{name: "min larger than max", config: PoolConfig{MinSize: 3, MaxSize: 2}, wantErr: true},
+		{
+			name:    "negative idle timeout",
+			config:  PoolConfig{MaxSize: 1, IdleTimeout: -time.Second},
+			wantErr: true,
+		},
+		{name: "fresh instance per call", config: PoolConfig{MaxSize: 1, MaxUses: 1}},
+		{name: "negative max uses", config: PoolConfig{MaxSize: 1, MaxUses: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.config.validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInstancePool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("warms min size instances", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MinSize: 2, MaxSize: 4})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		assert.Equal(t, 2, plugin.created())
+		assert.Equal(t, 2, pool.Idle())
+	})
+
+	t.Run("reuses released instances and clears their variables", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		config := PoolConfig{MaxSize: 2, Stateless: true}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, config)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		first, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, pool.InUse())
+		pool.Put(t.Context(), first)
+		assert.Equal(t, 0, pool.InUse())
+
+		second, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, plugin.created())
+
+		fake, ok := first.(*fakeInstance)
+		require.True(t, ok)
+		assert.Equal(t, int32(1), fake.clears.Load())
+		pool.Put(t.Context(), second)
+	})
+
+	t.Run("waits for a released instance at max size", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MaxSize: 1, Stateless: true})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		held, err := pool.Get(t.Context())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err = pool.Get(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		got := make(chan adapters.PluginInstance)
+		go func() {
+			instance, err := pool.Get(t.Context())
+			assert.NoError(t, err)
+			got <- instance
+		}()
+
+		pool.Put(t.Context(), held)
+		select {
+		case instance := <-got:
+			assert.Same(t, held, instance)
+			pool.Put(t.Context(), instance)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a released instance")
+		}
+		assert.Equal(t, 1, plugin.created())
+	})
+
+	t.Run("discarded instances are closed and replaced", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MaxSize: 1})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		first, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		pool.Discard(t.Context(), first)
+
+		fake, ok := first.(*fakeInstance)
+		require.True(t, ok)
+		assert.True(t, fake.closed.Load())
+
+		second, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		pool.Put(t.Context(), second)
+	})
+
+	t.Run("instances are replaced after max uses", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(
+			t.Context(),
+			nil,
+			plugin,
+			PoolConfig{MaxSize: 1, MaxUses: 2},
+		)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		var used []adapters.PluginInstance
+		for range 4 {
+			instance, err := pool.Get(t.Context())
+			require.NoError(t, err)
+			used = append(used, instance)
+			pool.Put(t.Context(), instance)
+		}
+
+		// each instance serves two calls, then is closed and replaced
+		assert.Same(t, used[0], used[1])
+		assert.NotSame(t, used[1], used[2])
+		assert.Same(t, used[2], used[3])
+		assert.Equal(t, 2, plugin.created())
+		for _, instance := range plugin.instances {
+			assert.True(t, instance.closed.Load())
+		}
+		assert.Equal(t, 0, pool.Idle())
+		assert.Equal(t, 0, pool.InUse())
+	})
+
+	t.Run("by default every call gets a fresh instance", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MaxSize: 2})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		first, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		pool.Put(t.Context(), first)
+		second, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		pool.Put(t.Context(), second)
+
+		assert.NotSame(t, first, second)
+		assert.Equal(t, 2, plugin.created())
+	})
+
+	t.Run("replaces used instances to keep min size warm", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MinSize: 1, MaxSize: 1})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		first, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 0, pool.Idle())
+		pool.Put(t.Context(), first)
+
+		fake, ok := first.(*fakeInstance)
+		require.True(t, ok)
+		assert.True(t, fake.closed.Load())
+		require.Eventually(t, func() bool { return pool.Idle() == 1 }, time.Second, 5*time.Millisecond)
+
+		second, err := pool.Get(t.Context())
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		assert.Equal(t, 2, plugin.created())
+		pool.Put(t.Context(), second)
+	})
+
+	t.Run("evicts idle instances above min size", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(
+			t.Context(),
+			nil,
+			plugin,
+			PoolConfig{MinSize: 1, MaxSize: 3, IdleTimeout: 10 * time.Millisecond, Stateless: true},
+		)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, pool.Close(t.Context())) }()
+
+		var held []adapters.PluginInstance
+		for range 3 {
+			instance, err := pool.Get(t.Context())
+			require.NoError(t, err)
+			held = append(held, instance)
+		}
+		for _, instance := range held {
+			pool.Put(t.Context(), instance)
+		}
+		assert.Equal(t, 3, pool.Idle())
+
+		require.Eventually(t, func() bool { return pool.Idle() == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		plugin := &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MinSize: 1, MaxSize: 2})
+		require.NoError(t, err)
+
+		inUse, err := pool.Get(t.Context())
+		require.NoError(t, err)
+
+		require.NoError(t, pool.Close(t.Context()))
+		require.NoError(t, pool.Close(t.Context()))
+
+		_, err = pool.Get(t.Context())
+		require.ErrorIs(t, err, ErrPoolClosed)
+
+		// instances returned after close are closed
+		pool.Put(t.Context(), inUse)
+		for _, instance := range plugin.instances {
+			assert.True(t, instance.closed.Load())
+		}
+	})
+
+	t.Run("instance creation fails", func(t *testing.T) {
+		plugin := &fakePlugin{err: errors.New("instantiate failed")}
+		_, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MinSize: 1, MaxSize: 1})
+		require.Error(t, err)
+
+		plugin = &fakePlugin{}
+		pool, err := NewInstancePool(t.Context(), nil, plugin, PoolConfig{MaxSize: 1})
+		require.NoError(t, err)
+		plugin.err = errors.New("instantiate failed")
+
+		_, err = pool.Get(t.Context())
+		require.Error(t, err)
+		// the failed creation doesn't hold a slot
+		assert.Equal(t, 0, pool.InUse())
+	})
+
+	t.Run("nil plugin", func(t *testing.T) {
+		_, err := NewInstancePool(t.Context(), nil, nil, PoolConfig{MaxSize: 1})
+		require.Error(t, err)
+	})
+}
+
+func TestCompiler_CompileWithInstancePool(t *testing.T) {
+	t.Parallel()
+
+	comp, err := New(
+		WithEntryPoint(wasmdata.EntrypointGreet),
+		WithInstancePool(1, 2, time.Minute),
+	)
+	require.NoError(t, err)
+
+	reader := newMockScriptReaderCloser(wasmdata.TestModule)
+	reader.On("Close").Return(nil)
+	content, err := comp.Compile(reader)
+	require.NoError(t, err)
+
+	exe, ok := content.(*Executable)
+	require.True(t, ok)
+	pool := exe.GetInstancePool()
+	require.NotNil(t, pool)
+	assert.Equal(t, 1, pool.Idle())
+
+	for _, name := range []string{"First", "Second"} {
+		instance, err := pool.Get(t.Context())
+		require.NoError(t, err)
+
+		exit, output, err := instance.CallWithContext(
+			t.Context(),
+			wasmdata.EntrypointGreet,
+			[]byte(`{"input":"`+name+`"}`),
+		)
+		require.NoError(t, err)
+		require.Equal(t, uint32(0), exit)
+
+		var result struct {
+			Greeting string `json:"greeting"`
+		}
+		require.NoError(t, json.Unmarshal(output, &result))
+		assert.Equal(t, "Hello, "+name+"!", result.Greeting)
+		pool.Put(t.Context(), instance)
+	}
+
+	require.NoError(t, exe.Close(t.Context()))
+	_, err = pool.Get(t.Context())
+	require.ErrorIs(t, err, ErrPoolClosed)
+}
@@ -0,0 +1,6 @@
+package evaluator
+
+import "errors"
+
+// ErrExportNotFound is returned when calling a function that the WASM module doesn't export
+var ErrExportNotFound = errors.New("export not found")
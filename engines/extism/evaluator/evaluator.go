@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -21,19 +22,35 @@ import (
 // Evaluator executes compiled WASM modules with provided runtime data
 type Evaluator struct {
 	execUnit   *script.ExecutableUnit
+	evalOpts   platform.EvalOptions
 	logHandler slog.Handler
 	logger     *slog.Logger
 }
 
-// New creates a new Evaluator object
+// ExportEvaluator is a platform.Evaluator that can also call the other functions exported by
+// the WASM module, without compiling the module again for each one.
+type ExportEvaluator interface {
+	platform.Evaluator
+
+	// Exports returns the sorted names of the functions exported by the module.
+	Exports() ([]string, error)
+
+	// EvalExport calls the named export with the input data from the context.
+	EvalExport(ctx context.Context, name string) (platform.EvaluatorResponse, error)
+}
+
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
 func New(
 	handler slog.Handler,
 	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
 ) *Evaluator {
 	handler, logger := helpers.SetupLogger(handler, "extism", "Evaluator")
 
 	return &Evaluator{
 		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
 		logHandler: handler,
 		logger:     logger,
 	}
@@ -71,8 +88,20 @@ func execHelper(
 	exit, output, err := instance.CallWithContext(ctx, entryPoint, inputJSON)
 	execTime := time.Since(startTime)
 	if err != nil {
-		if ctx.Err() != nil {
-			return nil, execTime, fmt.Errorf("execution cancelled: %w", ctx.Err())
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			if errors.Is(ctxErr, platform.ErrTimeout) {
+				return nil, execTime, fmt.Errorf("execution timed out after %s: %w", execTime, ctxErr)
+			}
+			return nil, execTime, fmt.Errorf("execution cancelled after %s: %w", execTime, ctxErr)
+		}
+		// The manifest timeout ends the call without the caller's context being done
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, execTime, fmt.Errorf(
+				"execution timed out after %s: %w: %w",
+				execTime,
+				platform.ErrTimeout,
+				err,
+			)
 		}
 		return nil, execTime, fmt.Errorf("execution failed: %w", err)
 	}
@@ -129,11 +158,91 @@ func (be *Evaluator) exec(
 	return newEvalResult(be.logHandler, result, execTime, ""), nil
 }
 
-// Eval implements evaluation.Evaluator
+// execPooled runs the entry point on an instance from the pool, instead of creating a new
+// instance. Instances that fail during a call are discarded rather than reused.
+func (be *Evaluator) execPooled(
+	ctx context.Context,
+	pool *compiler.InstancePool,
+	entryPoint string,
+	inputJSON []byte,
+) (*execResult, error) {
+	logger := be.logger.WithGroup("execPooled")
+
+	instance, err := pool.Get(ctx)
+	if err != nil {
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("failed to get plugin instance from pool: %w: %w", ctxErr, err)
+		}
+		return nil, fmt.Errorf("failed to get plugin instance from pool: %w", err)
+	}
+
+	result, execTime, err := execHelper(ctx, logger, instance, entryPoint, inputJSON)
+	if err != nil {
+		pool.Discard(ctx, instance)
+		return nil, fmt.Errorf("extism execution error: %w", err)
+	}
+	pool.Put(ctx, instance)
+	return newEvalResult(be.logHandler, result, execTime, ""), nil
+}
+
+// Eval implements evaluation.Evaluator, and calls the entry point chosen when the module was
+// compiled.
 // TODO: Some error paths in this method are hard to test with the current design
 // Consider adding more integration tests to cover these paths.
 func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
-	logger := be.logger.WithGroup("Eval")
+	return be.evalFunction(ctx, be.logger.WithGroup("Eval"), "")
+}
+
+// EvalExport calls the named function exported by the module, instead of the entry point. The
+// input data is loaded from the context the same way as Eval, and the call uses the same
+// compiled plugin and instance pool.
+func (be *Evaluator) EvalExport(
+	ctx context.Context,
+	name string,
+) (platform.EvaluatorResponse, error) {
+	if name == "" {
+		return nil, fmt.Errorf("export name is empty")
+	}
+	return be.evalFunction(ctx, be.logger.WithGroup("EvalExport").With("export", name), name)
+}
+
+// Exports returns the sorted names of the functions exported by the module
+func (be *Evaluator) Exports() ([]string, error) {
+	wasmExe, err := be.getExecutable()
+	if err != nil {
+		return nil, err
+	}
+	return wasmExe.GetExports(), nil
+}
+
+// getExecutable returns the compiled WASM executable from the executable unit
+func (be *Evaluator) getExecutable() (*compiler.Executable, error) {
+	if be.execUnit == nil {
+		return nil, fmt.Errorf("executable unit is nil")
+	}
+	if be.execUnit.GetContent() == nil {
+		return nil, fmt.Errorf("content is nil")
+	}
+	wasmExe, ok := be.execUnit.GetContent().(*compiler.Executable)
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid executable type: expected *Executable, got %T",
+			be.execUnit.GetContent(),
+		)
+	}
+	return wasmExe, nil
+}
+
+// evalFunction loads the input data, and calls the named function in the module. An empty name
+// calls the entry point.
+func (be *Evaluator) evalFunction(
+	ctx context.Context,
+	logger *slog.Logger,
+	functionName string,
+) (platform.EvaluatorResponse, error) {
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
 	if be.execUnit == nil {
 		return nil, fmt.Errorf("executable unit is nil")
 	}
@@ -168,6 +277,13 @@ func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, erro
 		return nil, fmt.Errorf("compiled plugin is nil")
 	}
 
+	if functionName == "" {
+		functionName = wasmExe.GetEntryPoint()
+	}
+	if !wasmExe.HasExport(functionName) {
+		return nil, fmt.Errorf("%w: %s", ErrExportNotFound, functionName)
+	}
+
 	// 2. Get the raw input data
 	rawInputData, err := be.loadInputData(ctx)
 	if err != nil {
@@ -180,13 +296,18 @@ func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, erro
 		return nil, fmt.Errorf("failed to marshal input data: %w", err)
 	}
 
-	// 4. Execute the program
-	result, err := be.exec(
-		ctx, plugin,
-		wasmExe.GetEntryPoint(),
-		adapters.NewPluginInstanceConfig(),
-		runtimeData,
-	)
+	// 4. Execute the program, on a pooled instance when the executable has a pool
+	var result *execResult
+	if pool := wasmExe.GetInstancePool(); pool != nil {
+		result, err = be.execPooled(ctx, pool, functionName, runtimeData)
+	} else {
+		result, err = be.exec(
+			ctx, plugin,
+			functionName,
+			adapters.NewPluginInstanceConfig(),
+			runtimeData,
+		)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("exec error: %w", err)
 	}
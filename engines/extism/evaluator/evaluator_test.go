@@ -3,18 +3,24 @@ package evaluator
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	extismSDK "github.com/extism/go-sdk"
 	"github.com/robbyt/go-polyscript/engines/extism/adapters"
 	"github.com/robbyt/go-polyscript/engines/extism/compiler"
 	"github.com/robbyt/go-polyscript/engines/extism/internal"
+	"github.com/robbyt/go-polyscript/engines/extism/wasmdata"
 	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/robbyt/go-polyscript/platform/data"
 	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -313,7 +319,7 @@ func TestEvaluator_Evaluate(t *testing.T) {
 		// Test nil bytecode
 		t.Run("nil bytecode", func(t *testing.T) {
 			mockContent := &mockExecutableContent{
-				machineType: machineTypes.Extism,
+				machineType: compiler.MachineType,
 				source:      "invalid wasm",
 				bytecode:    nil, // Nil bytecode will cause error
 			}
@@ -339,7 +345,7 @@ func TestEvaluator_Evaluate(t *testing.T) {
 		// Test invalid content type
 		t.Run("invalid content type", func(t *testing.T) {
 			mockContent := &mockExecutableContent{
-				machineType: machineTypes.Extism,
+				machineType: compiler.MachineType,
 				source:      "invalid wasm",
 				bytecode:    []byte{0x00}, // Not a valid WASM plugin
 			}
@@ -401,6 +407,7 @@ func TestEvaluator_Evaluate(t *testing.T) {
 			require.Error(t, err, "Expected cancellation error")
 			assert.Nil(t, result)
 			assert.Contains(t, err.Error(), "execution")
+			require.ErrorIs(t, err, platform.ErrCanceled)
 
 			// Instance should have been called
 			mockPlugin.AssertCalled(t, "Instance", mock.Anything, mock.Anything)
@@ -729,3 +736,291 @@ func TestEvaluator_AddDataToContext(t *testing.T) {
 		})
 	}
 }
+
+// TestEvaluator_InstancePool tests evaluating a module compiled with an instance pool
+func TestEvaluator_InstancePool(t *testing.T) {
+	t.Parallel()
+
+	comp, err := compiler.New(
+		compiler.WithEntryPoint(wasmdata.EntrypointGreet),
+		compiler.WithInstancePool(1, 2, time.Minute),
+	)
+	require.NoError(t, err)
+
+	ldr, err := loader.NewFromBytes(wasmdata.TestModule)
+	require.NoError(t, err)
+
+	exe, err := script.NewExecutableUnit(
+		nil,
+		"",
+		ldr,
+		comp,
+		data.NewContextProvider(constants.EvalData),
+	)
+	require.NoError(t, err)
+
+	wasmExe, ok := exe.GetContent().(*compiler.Executable)
+	require.True(t, ok)
+	pool := wasmExe.GetInstancePool()
+	require.NotNil(t, pool)
+	defer func() { require.NoError(t, wasmExe.Close(t.Context())) }()
+
+	evaluator := New(nil, exe)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("caller-%d", i)
+			ctx, err := evaluator.AddDataToContext(t.Context(), map[string]any{"input": name})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			response, err := evaluator.Eval(ctx)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, map[string]any{"greeting": "Hello, " + name + "!"}, response.Interface())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 0, pool.InUse())
+	assert.LessOrEqual(t, pool.Idle(), 2)
+}
+
+// TestEvaluator_RunawayModule tests that a WASM function that never returns is interrupted by
+// the context deadline or the manifest timeout, with and without an instance pool
+func TestEvaluator_RunawayModule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []compiler.FunctionalOption
+		// evalTimeout is the deadline of the context passed to Eval, when not zero
+		evalTimeout time.Duration
+	}{
+		{name: "new instance per eval", evalTimeout: 50 * time.Millisecond},
+		{
+			name:        "instance pool",
+			opts:        []compiler.FunctionalOption{compiler.WithInstancePool(1, 1, 0)},
+			evalTimeout: 50 * time.Millisecond,
+		},
+		{
+			name: "manifest timeout",
+			opts: []compiler.FunctionalOption{compiler.WithTimeout(50 * time.Millisecond)},
+		},
+		{
+			name: "manifest timeout with instance pool",
+			opts: []compiler.FunctionalOption{
+				compiler.WithTimeout(50 * time.Millisecond),
+				compiler.WithInstancePool(1, 1, 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append(
+				[]compiler.FunctionalOption{compiler.WithEntryPoint(wasmdata.EntrypointInfiniteLoop)},
+				tt.opts...,
+			)
+			comp, err := compiler.New(opts...)
+			require.NoError(t, err)
+
+			ldr, err := loader.NewFromBytes(wasmdata.LoopModule)
+			require.NoError(t, err)
+
+			exe, err := script.NewExecutableUnit(
+				nil,
+				"",
+				ldr,
+				comp,
+				data.NewContextProvider(constants.EvalData),
+			)
+			require.NoError(t, err)
+
+			wasmExe, ok := exe.GetContent().(*compiler.Executable)
+			require.True(t, ok)
+			defer func() { require.NoError(t, wasmExe.Close(t.Context())) }()
+
+			evaluator := New(nil, exe)
+
+			// evaluate twice, so a pooled instance that was interrupted isn't reused
+			for range 2 {
+				ctx, cancel := context.WithCancel(t.Context())
+				if tt.evalTimeout > 0 {
+					ctx, cancel = context.WithTimeout(t.Context(), tt.evalTimeout)
+				}
+				start := time.Now()
+				_, err = evaluator.Eval(ctx)
+				cancel()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, platform.ErrTimeout)
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				require.NotErrorIs(t, err, platform.ErrCanceled)
+				require.NotContains(t, err.Error(), "cancel")
+				require.Less(t, time.Since(start), 5*time.Second)
+			}
+
+			if pool := wasmExe.GetInstancePool(); pool != nil {
+				assert.Equal(t, 0, pool.InUse())
+			}
+		})
+	}
+}
+
+// TestEvaluator_StatefulModule tests that a plugin keeping a counter in a global only sees the
+// earlier calls' state when the instance pool is declared stateless
+func TestEvaluator_StatefulModule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []compiler.FunctionalOption
+		// wantReuse is whether the second call sees the state of the first
+		wantReuse bool
+	}{
+		{name: "new instance per eval"},
+		{
+			name: "instance pool",
+			opts: []compiler.FunctionalOption{compiler.WithInstancePool(1, 1, 0)},
+		},
+		{
+			name: "stateless instance pool",
+			opts: []compiler.FunctionalOption{
+				compiler.WithInstancePoolConfig(compiler.PoolConfig{MaxSize: 1, Stateless: true}),
+			},
+			wantReuse: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append(
+				[]compiler.FunctionalOption{compiler.WithEntryPoint(wasmdata.EntrypointCountCalls)},
+				tt.opts...,
+			)
+			comp, err := compiler.New(opts...)
+			require.NoError(t, err)
+
+			ldr, err := loader.NewFromBytes(wasmdata.CounterModule)
+			require.NoError(t, err)
+
+			exe, err := script.NewExecutableUnit(
+				nil,
+				"",
+				ldr,
+				comp,
+				data.NewContextProvider(constants.EvalData),
+			)
+			require.NoError(t, err)
+
+			wasmExe, ok := exe.GetContent().(*compiler.Executable)
+			require.True(t, ok)
+			defer func() { require.NoError(t, wasmExe.Close(t.Context())) }()
+
+			evaluator := New(nil, exe)
+
+			// the module exits with the number of earlier calls on the same instance
+			_, err = evaluator.Eval(t.Context())
+			require.NoError(t, err)
+
+			_, err = evaluator.Eval(t.Context())
+			if tt.wantReuse {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "non-zero exit code: 1")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// TestEvaluator_EvalExport tests calling several exports of one compiled module
+func TestEvaluator_EvalExport(t *testing.T) {
+	t.Parallel()
+
+	var _ ExportEvaluator = (*Evaluator)(nil)
+
+	tests := []struct {
+		name string
+		opts []compiler.FunctionalOption
+	}{
+		{name: "new instance per eval"},
+		{name: "instance pool", opts: []compiler.FunctionalOption{compiler.WithInstancePool(1, 2, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append(
+				[]compiler.FunctionalOption{compiler.WithEntryPoint(wasmdata.EntrypointGreet)},
+				tt.opts...,
+			)
+			comp, err := compiler.New(opts...)
+			require.NoError(t, err)
+
+			ldr, err := loader.NewFromBytes(wasmdata.TestModule)
+			require.NoError(t, err)
+
+			exe, err := script.NewExecutableUnit(
+				nil,
+				"",
+				ldr,
+				comp,
+				data.NewContextProvider(constants.EvalData),
+			)
+			require.NoError(t, err)
+
+			wasmExe, ok := exe.GetContent().(*compiler.Executable)
+			require.True(t, ok)
+			defer func() { require.NoError(t, wasmExe.Close(t.Context())) }()
+
+			evaluator := New(nil, exe)
+
+			exports, err := evaluator.Exports()
+			require.NoError(t, err)
+			assert.Contains(t, exports, wasmdata.EntrypointGreet)
+			assert.Contains(t, exports, wasmdata.EntrypointCountVowels)
+			assert.Contains(t, exports, wasmdata.EntrypointReverseString)
+
+			ctx, err := evaluator.AddDataToContext(t.Context(), map[string]any{"input": "Hello World"})
+			require.NoError(t, err)
+
+			// the entry point is still used by Eval
+			response, err := evaluator.Eval(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"greeting": "Hello, Hello World!"}, response.Interface())
+
+			response, err = evaluator.EvalExport(ctx, wasmdata.EntrypointCountVowels)
+			require.NoError(t, err)
+			result, ok := response.Interface().(map[string]any)
+			require.True(t, ok)
+			assert.EqualValues(t, 3, result["count"])
+			assert.Equal(t, exe.GetID(), response.GetScriptExeID())
+
+			_, err = evaluator.EvalExport(ctx, "missing_function")
+			require.ErrorIs(t, err, ErrExportNotFound)
+
+			_, err = evaluator.EvalExport(ctx, "")
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.Exports()
+		require.Error(t, err)
+		_, err = evaluator.EvalExport(t.Context(), wasmdata.EntrypointGreet)
+		require.Error(t, err)
+	})
+}
@@ -18,18 +18,21 @@ import (
 // - l: loader implementation for loading the WASM content
 // - logHandler: logger handler for logging
 // - entryPoint: entry point for the WASM module (which function to call in the WASM file)
+// - opts: optional compiler options, such as WithLimits or WithInstancePool
 //
 // Returns an evaluator, which implements the evaluation.Evaluator interface.
 func FromExtismLoader(
 	logHandler slog.Handler,
 	ldr loader.Loader,
 	entryPoint string,
+	opts ...compiler.FunctionalOption,
 ) (*evaluator.Evaluator, error) {
 	return NewEvaluator(
 		logHandler,
 		ldr,
 		data.NewContextProvider(constants.EvalData),
 		entryPoint,
+		opts...,
 	)
 }
 
@@ -40,6 +43,7 @@ func FromExtismLoader(
 // - staticData: map of initial static data to be passed to the WASM module
 // - logHandler: logger handler for logging
 // - entryPoint: entry point for the WASM module (which function to call in the WASM file)
+// - opts: optional compiler options, such as WithLimits or WithInstancePool
 //
 // Returns an evaluator, which implements the evaluation.Evaluator interface.
 func FromExtismLoaderWithData(
@@ -47,6 +51,7 @@ func FromExtismLoaderWithData(
 	ldr loader.Loader,
 	staticData map[string]any,
 	entryPoint string,
+	opts ...compiler.FunctionalOption,
 ) (*evaluator.Evaluator, error) {
 	staticProvider := data.NewStaticProvider(staticData)
 	dynamicProvider := data.NewContextProvider(constants.EvalData)
@@ -57,6 +62,7 @@ func FromExtismLoaderWithData(
 		ldr,
 		compositeProvider,
 		entryPoint,
+		opts...,
 	)
 }
 
@@ -73,12 +79,14 @@ func NewEvaluator(
 	ldr loader.Loader,
 	dataProvider data.Provider,
 	entryPoint string,
+	opts ...compiler.FunctionalOption,
 ) (*evaluator.Evaluator, error) {
 	if dataProvider == nil {
 		return nil, fmt.Errorf("provider is nil")
 	}
 
-	compiler, err := NewCompiler(compiler.WithEntryPoint(entryPoint))
+	compilerOpts := append([]compiler.FunctionalOption{compiler.WithEntryPoint(entryPoint)}, opts...)
+	compiler, err := NewCompiler(compilerOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Extism compiler: %w", err)
 	}
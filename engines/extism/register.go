@@ -0,0 +1,61 @@
+package extism
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/robbyt/go-polyscript/engines/extism/compiler"
+	"github.com/robbyt/go-polyscript/engines/extism/evaluator"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
+)
+
+func init() {
+	registry.MustRegister(registry.Engine{
+		Type:               compiler.MachineType,
+		Extensions:         []string{".wasm"},
+		NewCompiler:        newCompilerFromOptions,
+		NewEvaluator:       newEvaluatorFromUnit,
+		NewBundleEvaluator: newEvaluatorFromBundle,
+	})
+}
+
+// newCompilerFromOptions is the registry.CompilerFactory for Extism. It only accepts
+// compiler.FunctionalOption values.
+func newCompilerFromOptions(opts ...any) (script.Compiler, error) {
+	extismOpts, ok := registry.MatchOptions[compiler.FunctionalOption](opts)
+	if !ok {
+		return nil, registry.ErrOptionsNotSupported
+	}
+
+	c, err := compiler.New(extismOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Extism compiler: %w", err)
+	}
+	return c, nil
+}
+
+// newEvaluatorFromUnit is the registry.EvaluatorFactory for Extism.
+func newEvaluatorFromUnit(
+	handler slog.Handler,
+	unit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) platform.Evaluator {
+	return evaluator.New(handler, unit, opts...)
+}
+
+// newEvaluatorFromBundle is the registry.BundleEvaluatorFactory for Extism. The
+// manifest must name the exported function to call.
+func newEvaluatorFromBundle(handler slog.Handler, b *bundle.Bundle) (platform.Evaluator, error) {
+	m := b.Manifest()
+	if m.Function == "" {
+		return nil, fmt.Errorf("%w: Extism bundles must name a function", bundle.ErrInvalidBundle)
+	}
+	e, err := FromExtismLoaderWithData(handler, b.Loader(), m.StaticData, m.Function)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
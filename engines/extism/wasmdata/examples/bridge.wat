@@ -0,0 +1,14 @@
+;; bridge.wat is a module that passes its input to the "bridge" host function, and returns the
+;; host function's output. It's used to test host functions registered with the plugin.
+;;
+;; Build with: wat2wasm examples/bridge.wat -o bridge.wasm
+(module
+  (import "extism:host/env" "input_offset" (func $input_offset (result i64)))
+  (import "extism:host/env" "length" (func $length (param i64) (result i64)))
+  (import "extism:host/env" "output_set" (func $output_set (param i64 i64)))
+  (import "extism:host/user" "bridge" (func $bridge (param i64) (result i64)))
+  (func (export "call_bridge") (result i32)
+    (local $result i64)
+    (local.set $result (call $bridge (call $input_offset)))
+    (call $output_set (local.get $result) (call $length (local.get $result)))
+    (i32.const 0)))
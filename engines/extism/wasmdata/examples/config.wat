@@ -0,0 +1,23 @@
+;; config.wat is a module that returns the value of the "greeting" key from the plugin's
+;; manifest config, or exits with code 1 when the key isn't set. It's used to test that
+;; config values reach the plugin.
+;;
+;; Build with: wat2wasm examples/config.wat -o config.wasm
+(module
+  (import "extism:host/env" "alloc" (func $alloc (param i64) (result i64)))
+  (import "extism:host/env" "store_u64" (func $store_u64 (param i64 i64)))
+  (import "extism:host/env" "config_get" (func $config_get (param i64) (result i64)))
+  (import "extism:host/env" "length" (func $length (param i64) (result i64)))
+  (import "extism:host/env" "output_set" (func $output_set (param i64 i64)))
+  (func (export "get_greeting") (result i32)
+    (local $key i64)
+    (local $value i64)
+    ;; write the 8 byte key "greeting" to Extism memory
+    (local.set $key (call $alloc (i64.const 8)))
+    (call $store_u64 (local.get $key) (i64.const 0x676e697465657267))
+    (local.set $value (call $config_get (local.get $key)))
+    (if (result i32) (i64.eqz (local.get $value))
+      (then (i32.const 1))
+      (else
+        (call $output_set (local.get $value) (call $length (local.get $value)))
+        (i32.const 0)))))
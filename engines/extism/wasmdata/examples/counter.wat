@@ -0,0 +1,11 @@
+;; counter.wat is a module that keeps a count of its calls in a global, and returns the number
+;; of earlier calls as its exit code. A fresh instance exits with 0, and an instance that is
+;; reused exits with the number of calls it has already served. It's used to test that pooled
+;; instances don't share state between calls.
+;;
+;; Build with: wat2wasm examples/counter.wat -o counter.wasm
+(module
+  (global $calls (mut i32) (i32.const 0))
+  (func (export "count_calls") (result i32)
+    (global.set $calls (i32.add (global.get $calls) (i32.const 1)))
+    (i32.sub (global.get $calls) (i32.const 1))))
@@ -0,0 +1,9 @@
+;; loop.wat is a module whose only function never returns. It's used to test that
+;; cancellation and deadlines interrupt running WASM code.
+;;
+;; Build with: wat2wasm examples/loop.wat -o loop.wasm
+(module
+  (func (export "infinite_loop") (result i32)
+    (loop $forever
+      (br $forever))
+    (i32.const 0)))
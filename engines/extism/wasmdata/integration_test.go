@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -350,3 +351,186 @@ func TestExtismWasmIntegration(t *testing.T) {
 		assert.Equal(t, "dlroW olleH", reverseResult["reversed"])
 	})
 }
+
+func TestLoopModule(t *testing.T) {
+	t.Parallel()
+
+	manifest := extismSDK.Manifest{
+		Wasm: []extismSDK.Wasm{
+			extismSDK.WasmData{
+				Data: LoopModule,
+			},
+		},
+	}
+
+	ctx := t.Context()
+	config := extismSDK.PluginConfig{
+		// Without close on context done, the loop would never be interrupted
+		RuntimeConfig: wazero.NewRuntimeConfig().WithCloseOnContextDone(true),
+	}
+
+	plugin, err := extismSDK.NewCompiledPlugin(ctx, manifest, config, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, plugin.Close(ctx))
+	}()
+
+	instance, err := plugin.Instance(ctx, extismSDK.PluginInstanceConfig{})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, instance.Close(ctx))
+	}()
+	require.True(t, instance.FunctionExists(EntrypointInfiniteLoop))
+
+	callCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := instance.CallWithContext(callCtx, EntrypointInfiniteLoop, nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		require.ErrorIs(t, callCtx.Err(), context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("infinite loop was not interrupted by the context deadline")
+	}
+}
+
+func TestCounterModule(t *testing.T) {
+	t.Parallel()
+
+	manifest := extismSDK.Manifest{
+		Wasm: []extismSDK.Wasm{
+			extismSDK.WasmData{
+				Data: CounterModule,
+			},
+		},
+	}
+
+	ctx := t.Context()
+	plugin, err := extismSDK.NewCompiledPlugin(ctx, manifest, extismSDK.PluginConfig{}, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, plugin.Close(ctx))
+	}()
+
+	instance, err := plugin.Instance(ctx, extismSDK.PluginInstanceConfig{})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, instance.Close(ctx))
+	}()
+	require.True(t, instance.FunctionExists(EntrypointCountCalls))
+
+	// the exit code is the number of earlier calls on the same instance
+	for want := range uint32(3) {
+		exit, _, err := instance.Call(EntrypointCountCalls, nil)
+		require.NoError(t, err)
+		assert.Equal(t, want, exit)
+	}
+}
+
+func TestConfigModule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   map[string]string
+		wantExit uint32
+		want     string
+	}{
+		{name: "key set", config: map[string]string{"greeting": "Hello from config"}, want: "Hello from config"},
+		{name: "key missing", config: map[string]string{"other": "value"}, wantExit: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			manifest := extismSDK.Manifest{
+				Wasm: []extismSDK.Wasm{
+					extismSDK.WasmData{
+						Data: ConfigModule,
+					},
+				},
+				Config: tt.config,
+			}
+
+			plugin, err := extismSDK.NewCompiledPlugin(ctx, manifest, extismSDK.PluginConfig{}, nil)
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, plugin.Close(ctx))
+			}()
+
+			instance, err := plugin.Instance(ctx, extismSDK.PluginInstanceConfig{})
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, instance.Close(ctx))
+			}()
+
+			exit, output, err := instance.Call(EntrypointGetGreeting, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantExit, exit)
+			if tt.wantExit == 0 {
+				require.Equal(t, tt.want, string(output))
+			}
+		})
+	}
+}
+
+func TestBridgeModule(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	// the host function upper-cases the bytes it's given
+	bridge := extismSDK.NewHostFunctionWithStack(
+		HostFunctionBridge,
+		func(ctx context.Context, p *extismSDK.CurrentPlugin, stack []uint64) {
+			input, err := p.ReadString(stack[0])
+			if err != nil {
+				panic(err)
+			}
+			offset, err := p.WriteString(strings.ToUpper(input))
+			if err != nil {
+				panic(err)
+			}
+			stack[0] = offset
+		},
+		[]extismSDK.ValueType{extismSDK.ValueTypePTR},
+		[]extismSDK.ValueType{extismSDK.ValueTypePTR},
+	)
+
+	manifest := extismSDK.Manifest{
+		Wasm: []extismSDK.Wasm{
+			extismSDK.WasmData{
+				Data: BridgeModule,
+			},
+		},
+	}
+
+	plugin, err := extismSDK.NewCompiledPlugin(
+		ctx,
+		manifest,
+		extismSDK.PluginConfig{},
+		[]extismSDK.HostFunction{bridge},
+	)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, plugin.Close(ctx))
+	}()
+
+	instance, err := plugin.Instance(ctx, extismSDK.PluginInstanceConfig{})
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, instance.Close(ctx))
+	}()
+
+	exit, output, err := instance.Call(EntrypointCallBridge, []byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), exit)
+	require.Equal(t, "HELLO", string(output))
+}
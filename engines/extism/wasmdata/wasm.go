@@ -9,6 +9,47 @@ import _ "embed"
 //go:embed main.wasm
 var TestModule []byte
 
+// LoopModule contains a WASM module whose only function loops forever. It's compiled from
+// examples/loop.wat, and is used to test that cancellation and deadlines interrupt execution.
+//
+//go:embed loop.wasm
+var LoopModule []byte
+
+// EntrypointInfiniteLoop is the function exported by LoopModule. It never returns.
+const EntrypointInfiniteLoop = "infinite_loop"
+
+// ConfigModule contains a WASM module that returns a value from its manifest config. It's
+// compiled from examples/config.wat.
+//
+//go:embed config.wasm
+var ConfigModule []byte
+
+// EntrypointGetGreeting is the function exported by ConfigModule. It returns the value of the
+// "greeting" config key, and exits with code 1 when the key isn't set.
+const EntrypointGetGreeting = "get_greeting"
+
+// BridgeModule contains a WASM module that passes its input to a host function named "bridge",
+// and returns the host function's output. It's compiled from examples/bridge.wat.
+//
+//go:embed bridge.wasm
+var BridgeModule []byte
+
+// EntrypointCallBridge is the function exported by BridgeModule
+const EntrypointCallBridge = "call_bridge"
+
+// HostFunctionBridge is the name of the host function imported by BridgeModule, from the
+// "extism:host/user" namespace. It takes and returns the offset of a block of Extism memory.
+const HostFunctionBridge = "bridge"
+
+// CounterModule contains a WASM module that counts its calls in a global, and exits with the
+// number of earlier calls to the same instance. It's compiled from examples/counter.wat.
+//
+//go:embed counter.wasm
+var CounterModule []byte
+
+// EntrypointCountCalls is the function exported by CounterModule
+const EntrypointCountCalls = "count_calls"
+
 // Entrypoint constants for the embedded WASM module.
 // These correspond to the exported functions from the WASM module.
 const (
@@ -0,0 +1,7 @@
+package adapters
+
+import "github.com/dop251/goja"
+
+type JavaScriptExecutable struct {
+	GetJavaScriptByteCode func() *goja.Program
+}
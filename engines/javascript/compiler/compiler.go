@@ -0,0 +1,104 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/robbyt/go-polyscript/engines/javascript/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+type Compiler struct {
+	strict     bool
+	logHandler slog.Handler
+	logger     *slog.Logger
+
+	// evalOptions configure the evaluators of the compiled script
+	evalOptions []platform.EvalOption
+}
+
+// New creates a new JavaScript-specific Compiler instance with the provided options.
+// Scripts are compiled into goja programs, which can be run by many runtimes concurrently.
+func New(opts ...FunctionalOption) (*Compiler, error) {
+	// Initialize the compiler with an empty struct
+	c := &Compiler{}
+
+	// Apply defaults
+	c.applyDefaults()
+
+	// Apply all options
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("error applying compiler option: %w", err)
+		}
+	}
+
+	// Validate the configuration
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid compiler configuration: %w", err)
+	}
+
+	// Finalize logger setup after all options have been applied
+	c.setupLogger()
+
+	return c, nil
+}
+
+func (c *Compiler) String() string {
+	return "javascript.Compiler"
+}
+
+// Compile turns the provided script content into a runnable goja program.
+func (c *Compiler) Compile(scriptReader io.ReadCloser) (script.ExecutableContent, error) {
+	if scriptReader == nil {
+		return nil, ErrContentNil
+	}
+
+	scriptBodyBytes, err := io.ReadAll(scriptReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	err = scriptReader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to close reader: %w", err)
+	}
+
+	return c.compile(scriptBodyBytes)
+}
+
+func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
+	logger := c.logger.WithGroup("compile")
+	if len(scriptBodyBytes) == 0 {
+		logger.Error("Compile called with nil script")
+		return nil, ErrContentNil
+	}
+
+	if strings.TrimSpace(string(scriptBodyBytes)) == "" {
+		return nil, ErrNoInstructions
+	}
+
+	logger.Debug("Starting JavaScript compilation",
+		"scriptLength", len(scriptBodyBytes), "strict", c.strict)
+
+	prog, err := compile.Compile(scriptBodyBytes, c.strict)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	if prog == nil {
+		return nil, ErrBytecodeNil
+	}
+
+	jsExec := newExecutable(scriptBodyBytes, prog)
+	if jsExec == nil {
+		return nil, ErrExecCreationFailed
+	}
+	jsExec.evalOptions = c.evalOptions
+
+	logger.Debug("JavaScript compilation completed")
+	return jsExec, nil
+}
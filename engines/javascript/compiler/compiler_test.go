@@ -0,0 +1,134 @@
+package compiler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/require"
+)
+
+// errorReader implements io.ReadCloser for testing read errors
+type errorReader struct{}
+
+func (e *errorReader) Read(p []byte) (n int, err error) {
+	return 0, errors.New("test error")
+}
+
+func (e *errorReader) Close() error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("basic creation", func(t *testing.T) {
+		comp, err := New(WithLogHandler(slog.NewTextHandler(os.Stdout, nil)))
+		require.NoError(t, err)
+		require.NotNil(t, comp)
+		require.Equal(t, "javascript.Compiler", comp.String())
+	})
+
+	t.Run("with strict mode", func(t *testing.T) {
+		comp, err := New(WithStrictMode(true))
+		require.NoError(t, err)
+		require.True(t, comp.strict)
+	})
+
+	t.Run("with nil log handler", func(t *testing.T) {
+		comp, err := New(WithLogHandler(nil))
+		require.Error(t, err)
+		require.Nil(t, comp)
+		require.Contains(t, err.Error(), "log handler cannot be nil")
+	})
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		script  string
+		strict  bool
+		wantErr error
+	}{
+		{
+			name:   "expression",
+			script: `1 + 2`,
+		},
+		{
+			name: "uses ctx global",
+			script: `
+				const name = ctx.name || "World";
+				"Hello, " + name;
+			`,
+		},
+		{
+			name:   "function result",
+			script: `(function() { return 42; })`,
+		},
+		{
+			name:    "syntax error",
+			script:  `function( {`,
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:    "strict mode rejects with statement",
+			script:  `with (ctx) { name }`,
+			strict:  true,
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:    "empty script",
+			script:  ``,
+			wantErr: ErrContentNil,
+		},
+		{
+			name:    "whitespace only",
+			script:  "  \n\t ",
+			wantErr: ErrNoInstructions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			comp, err := New(WithStrictMode(tt.strict))
+			require.NoError(t, err)
+
+			exe, err := comp.Compile(io.NopCloser(strings.NewReader(tt.script)))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, exe)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, exe)
+			require.Equal(t, tt.script, exe.GetSource())
+			require.Equal(t, MachineType, exe.GetMachineType())
+			require.IsType(t, &goja.Program{}, exe.GetByteCode())
+		})
+	}
+
+	t.Run("nil reader", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+		exe, err := comp.Compile(nil)
+		require.ErrorIs(t, err, ErrContentNil)
+		require.Nil(t, exe)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		comp, err := New()
+		require.NoError(t, err)
+		exe, err := comp.Compile(&errorReader{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read script")
+		require.Nil(t, exe)
+	})
+}
@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	"github.com/dop251/goja"
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+)
+
+// MachineType is the machine type of JavaScript executables. It's registered with the file
+// extensions of JavaScript scripts by the javascript package.
+const MachineType machineTypes.Type = "javascript"
+
+// executable represents a compiled JavaScript program
+type executable struct {
+	scriptBodyBytes []byte
+	ByteCode        *goja.Program
+	evalOptions     []platform.EvalOption
+}
+
+func newExecutable(scriptBodyBytes []byte, byteCode *goja.Program) *executable {
+	if len(scriptBodyBytes) == 0 || byteCode == nil {
+		return nil
+	}
+
+	return &executable{
+		scriptBodyBytes: scriptBodyBytes,
+		ByteCode:        byteCode,
+	}
+}
+
+func (e *executable) GetSource() string {
+	return string(e.scriptBodyBytes)
+}
+
+func (e *executable) GetByteCode() any {
+	return e.ByteCode
+}
+
+func (e *executable) GetJavaScriptByteCode() *goja.Program {
+	return e.ByteCode
+}
+
+func (e *executable) GetMachineType() machineTypes.Type {
+	return MachineType
+}
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
+}
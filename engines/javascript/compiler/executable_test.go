@@ -0,0 +1,34 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecutable tests the functionality of Executable
+func TestExecutable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid creation", func(t *testing.T) {
+		content := "1 + 1"
+		bytecode := &goja.Program{}
+
+		exe := newExecutable([]byte(content), bytecode)
+		require.NotNil(t, exe)
+		assert.Equal(t, content, exe.GetSource())
+		assert.Equal(t, bytecode, exe.GetByteCode())
+		assert.Equal(t, bytecode, exe.GetJavaScriptByteCode())
+		assert.Equal(t, MachineType, exe.GetMachineType())
+	})
+
+	t.Run("nil content", func(t *testing.T) {
+		assert.Nil(t, newExecutable(nil, &goja.Program{}))
+	})
+
+	t.Run("nil bytecode", func(t *testing.T) {
+		assert.Nil(t, newExecutable([]byte("1"), nil))
+	})
+}
@@ -0,0 +1,22 @@
+package compile
+
+import (
+	"fmt"
+
+	"github.com/dop251/goja"
+)
+
+// Compile parses and compiles the script content into a reusable goja program.
+// The program is not bound to a runtime, so it can be shared by concurrent evaluations.
+func Compile(scriptBodyBytes []byte, strict bool) (*goja.Program, error) {
+	if scriptBodyBytes == nil {
+		return nil, ErrContentNil
+	}
+
+	prog, err := goja.Compile("", string(scriptBodyBytes), strict)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileFailed, err)
+	}
+
+	return prog, nil
+}
@@ -0,0 +1,50 @@
+package compile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompileSuccess tests the successful compilation of valid script content
+func TestCompileSuccess(t *testing.T) {
+	prog, err := Compile([]byte(`const x = 1 + 1; x`), false)
+	require.NoError(t, err)
+	require.NotNil(t, prog)
+}
+
+// TestCompileUndeclaredGlobal tests that globals injected at eval time do not fail compilation
+func TestCompileUndeclaredGlobal(t *testing.T) {
+	prog, err := Compile([]byte(`ctx.name`), false)
+	require.NoError(t, err)
+	require.NotNil(t, prog)
+}
+
+// TestCompileSyntaxError tests the compilation failure due to syntax errors
+func TestCompileSyntaxError(t *testing.T) {
+	prog, err := Compile([]byte(`function (`), false)
+	require.Error(t, err)
+	require.Nil(t, prog)
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileStrictMode tests that strict mode rejects sloppy-mode only syntax
+func TestCompileStrictMode(t *testing.T) {
+	script := []byte(`with (ctx) { name }`)
+
+	prog, err := Compile(script, false)
+	require.NoError(t, err)
+	require.NotNil(t, prog)
+
+	prog, err = Compile(script, true)
+	require.ErrorIs(t, err, ErrCompileFailed)
+	require.Nil(t, prog)
+}
+
+// TestCompileNilContent tests the handling of nil script content
+func TestCompileNilContent(t *testing.T) {
+	prog, err := Compile(nil, false)
+	require.Error(t, err)
+	require.Nil(t, prog)
+	require.ErrorIs(t, err, ErrContentNil)
+}
@@ -0,0 +1,8 @@
+package compile
+
+import "errors"
+
+var (
+	ErrCompileFailed = errors.New("failed to compile javascript")
+	ErrContentNil    = errors.New("javascript content is nil")
+)
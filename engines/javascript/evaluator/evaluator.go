@@ -0,0 +1,200 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/robbyt/go-polyscript/engines/javascript/internal"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+// Evaluator is an abstraction layer for evaluating code on the JavaScript (goja) engine
+type Evaluator struct {
+	// ctxKey is the variable name used to access input data inside the script (e.g., "ctx")
+	ctxKey string
+
+	// execUnit contains the compiled script and data provider
+	execUnit *script.ExecutableUnit
+
+	// evalOpts configures evaluation, such as the default timeout
+	evalOpts platform.EvalOptions
+
+	logHandler slog.Handler
+	logger     *slog.Logger
+}
+
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
+func New(
+	handler slog.Handler,
+	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) *Evaluator {
+	handler, logger := helpers.SetupLogger(handler, "javascript", "Evaluator")
+
+	return &Evaluator{
+		ctxKey:     constants.Ctx,
+		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
+		logHandler: handler,
+		logger:     logger,
+	}
+}
+
+func (be *Evaluator) String() string {
+	return "javascript.Evaluator"
+}
+
+// getDataProvider returns the data provider from the executable unit, or nil if unavailable.
+func (be *Evaluator) getDataProvider() data.Provider {
+	if be.execUnit == nil {
+		return nil
+	}
+	return be.execUnit.GetDataProvider()
+}
+
+// loadInputData retrieves input data using the data provider in the executable unit.
+// Returns a map that will be used as input for the JavaScript runtime.
+func (be *Evaluator) loadInputData(ctx context.Context) (map[string]any, error) {
+	return data.LoadInputData(ctx, be.logger.WithGroup("loadInputData"), be.getDataProvider())
+}
+
+// newRuntime creates a goja runtime for a single evaluation, with the console and ctx
+// globals populated. Runtimes are not safe for concurrent use, so one is created per Eval.
+func (be *Evaluator) newRuntime(
+	ctx context.Context,
+	inputData map[string]any,
+) (*goja.Runtime, error) {
+	vm := goja.New()
+	// Map Go struct fields and methods to lowerCamelCase names, matching JavaScript style
+	vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
+
+	if err := internal.SetupConsole(ctx, vm, be.logger.WithGroup("console")); err != nil {
+		return nil, fmt.Errorf("failed to setup console: %w", err)
+	}
+
+	ctxObj, err := internal.ConvertToJavaScriptFormat(vm, inputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert input data: %w", err)
+	}
+
+	if err := vm.Set(be.ctxKey, ctxObj); err != nil {
+		return nil, fmt.Errorf("failed to set %s global: %w", be.ctxKey, err)
+	}
+
+	return vm, nil
+}
+
+// exec runs the program in the runtime, and calls the result when the script evaluates
+// to a function.
+func (be *Evaluator) exec(
+	ctx context.Context,
+	vm *goja.Runtime,
+	prog *goja.Program,
+) (*execResult, error) {
+	logger := be.logger.WithGroup("exec")
+	startTime := time.Now()
+
+	// Interrupt the runtime when the context is done, using AfterFunc to avoid a goroutine
+	// leak when the context is never cancelled (e.g., context.Background())
+	stop := context.AfterFunc(ctx, func() {
+		vm.Interrupt(ctx.Err())
+	})
+	defer stop()
+
+	value, err := vm.RunProgram(prog)
+	if err != nil {
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("javascript execution error: %w: %w", ctxErr, err)
+		}
+		return nil, fmt.Errorf("javascript execution error: %w", err)
+	}
+
+	// Handle callable results (functions)
+	if fn, ok := goja.AssertFunction(value); ok {
+		logger.DebugContext(ctx, "script returned a function, calling it")
+		value, err = fn(goja.Undefined())
+		if err != nil {
+			if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+				return nil, fmt.Errorf("error calling function: %w: %w", ctxErr, err)
+			}
+			return nil, fmt.Errorf("error calling function: %w", err)
+		}
+	}
+
+	execTime := time.Since(startTime)
+	return newEvalResult(be.logHandler, internal.ConvertJavaScriptValueToInterface(value), execTime, ""), nil
+}
+
+// Eval evaluates the loaded program and passes the provided data into the JavaScript runtime
+func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	logger := be.logger.WithGroup("Eval")
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
+	if be.execUnit == nil {
+		return nil, fmt.Errorf("executable unit is nil")
+	}
+
+	if be.execUnit.GetContent() == nil {
+		return nil, fmt.Errorf("content is nil")
+	}
+
+	// Get bytecode from executable unit
+	bytecode := be.execUnit.GetContent().GetByteCode()
+	if bytecode == nil {
+		return nil, fmt.Errorf("bytecode is nil")
+	}
+
+	// Get execution ID
+	exeID := be.execUnit.GetID()
+	if exeID == "" {
+		return nil, fmt.Errorf("exeID is empty")
+	}
+	logger = logger.With("exeID", exeID)
+
+	// 1. Type assert to goja program
+	prog, ok := bytecode.(*goja.Program)
+	if !ok {
+		return nil, fmt.Errorf("invalid bytecode type: expected *goja.Program, got %T", bytecode)
+	}
+
+	// 2. Get the raw input data
+	rawInputData, err := be.loadInputData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input data: %w", err)
+	}
+
+	// 3. Create a runtime with the input data set as globals
+	vm, err := be.newRuntime(ctx, rawInputData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Execute the program
+	result, err := be.exec(ctx, vm, prog)
+	if err != nil {
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+	logger.DebugContext(ctx, "exec complete", "result", result)
+
+	// 5. Collect results
+	result.scriptExeID = exeID
+	return result, nil
+}
+
+// AddDataToContext implements the data.Setter interface which stores and prepares runtime data
+// which can be eventually passed to the Eval method.
+func (be *Evaluator) AddDataToContext(
+	ctx context.Context,
+	d ...map[string]any,
+) (context.Context, error) {
+	return data.AddDataToContextFromProvider(ctx, be.logger.WithGroup("AddDataToContext"), be.getDataProvider(), d...)
+}
@@ -0,0 +1,222 @@
+package evaluator
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/robbyt/go-polyscript/engines/javascript/compiler"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/require"
+)
+
+// evalBuilder is a helper function to create a test executable unit and evaluator
+func evalBuilder(
+	t *testing.T,
+	scriptContent string,
+	provider data.Provider,
+) (*script.ExecutableUnit, *Evaluator) {
+	t.Helper()
+	ldr, err := loader.NewFromString(scriptContent)
+	require.NoError(t, err, "Failed to create new loader")
+
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	comp, err := compiler.New(compiler.WithLogHandler(handler))
+	require.NoError(t, err, "Failed to create compiler")
+
+	exe, err := script.NewExecutableUnit(handler, scriptContent, ldr, comp, provider)
+	require.NoError(t, err, "Failed to create new version")
+
+	evaluator := New(handler, exe)
+	require.NotNil(t, evaluator, "Evaluator should not be nil")
+	return exe, evaluator
+}
+
+func TestEvaluator_Eval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		script   string
+		input    map[string]any
+		wantType data.Types
+		want     any
+	}{
+		{
+			name:     "greeting from ctx",
+			script:   `"Hello, " + ctx.name`,
+			input:    map[string]any{"name": "World"},
+			wantType: data.STRING,
+			want:     "Hello, World",
+		},
+		{
+			name:     "integer",
+			script:   `ctx.a + ctx.b`,
+			input:    map[string]any{"a": 2, "b": 3},
+			wantType: data.INT,
+			want:     int64(5),
+		},
+		{
+			name:     "float",
+			script:   `ctx.a / 2`,
+			input:    map[string]any{"a": 3},
+			wantType: data.FLOAT,
+			want:     1.5,
+		},
+		{
+			name:     "boolean",
+			script:   `ctx.list.includes("b")`,
+			input:    map[string]any{"list": []any{"a", "b"}},
+			wantType: data.BOOL,
+			want:     true,
+		},
+		{
+			name: "object",
+			script: `
+				const req = ctx.request;
+				({ method: req.Method, tags: ["x", 1] })
+			`,
+			input: map[string]any{
+				"request": map[string]any{"Method": "GET"},
+			},
+			wantType: data.MAP,
+			want:     map[string]any{"method": "GET", "tags": []any{"x", int64(1)}},
+		},
+		{
+			name:     "function result is called",
+			script:   `(function() { return ctx.value * 2; })`,
+			input:    map[string]any{"value": 21},
+			wantType: data.INT,
+			want:     int64(42),
+		},
+		{
+			name:     "undefined result",
+			script:   `let x = 1;`,
+			wantType: data.NONE,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			provider := data.NewStaticProvider(tt.input)
+			exe, evaluator := evalBuilder(t, tt.script, provider)
+
+			response, err := evaluator.Eval(t.Context())
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			require.Equal(t, tt.wantType, response.Type())
+			require.Equal(t, tt.want, response.Interface())
+			require.Equal(t, exe.GetID(), response.GetScriptExeID())
+		})
+	}
+}
+
+func TestEvaluator_EvalWithContextData(t *testing.T) {
+	t.Parallel()
+
+	_, evaluator := evalBuilder(t, `ctx.greeting + ", " + ctx.name`,
+		data.NewContextProvider(constants.EvalData))
+
+	ctx, err := evaluator.AddDataToContext(t.Context(),
+		map[string]any{"greeting": "Hi"},
+		map[string]any{"name": "Ada"},
+	)
+	require.NoError(t, err)
+
+	response, err := evaluator.Eval(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Hi, Ada", response.Interface())
+}
+
+func TestEvaluator_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "executable unit is nil")
+	})
+
+	t.Run("runtime exception", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `throw new Error("boom")`, data.NewStaticProvider(nil))
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "javascript execution error")
+		require.Contains(t, err.Error(), "boom")
+	})
+
+	t.Run("unsupported input data", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `1`,
+			data.NewStaticProvider(map[string]any{"bad": make(chan int)}))
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to convert input data")
+	})
+
+	t.Run("context cancellation interrupts script", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `while (true) {}`, data.NewStaticProvider(nil))
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := evaluator.Eval(ctx)
+		require.Error(t, err)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+	})
+
+	t.Run("default timeout interrupts script", func(t *testing.T) {
+		exe, _ := evalBuilder(t, `while (true) {}`, data.NewStaticProvider(nil))
+		evaluator := New(nil, exe, platform.WithDefaultTimeout(50*time.Millisecond))
+
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled context interrupts script", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `while (true) {}`, data.NewStaticProvider(nil))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		defer cancel()
+
+		_, err := evaluator.Eval(ctx)
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrCanceled)
+		require.NotErrorIs(t, err, platform.ErrTimeout)
+	})
+}
+
+func TestEvaluator_String(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "javascript.Evaluator", New(nil, nil).String())
+}
+
+func TestEvaluator_AddDataToContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil executable unit", func(t *testing.T) {
+		evaluator := New(nil, nil)
+		_, err := evaluator.AddDataToContext(t.Context(), map[string]any{"a": 1})
+		require.Error(t, err)
+	})
+
+	t.Run("static provider rejects data", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, `1`, data.NewStaticProvider(nil))
+		_, err := evaluator.AddDataToContext(t.Context(), map[string]any{"a": 1})
+		require.Error(t, err)
+		require.ErrorIs(t, err, data.ErrStaticProviderNoRuntimeUpdates)
+	})
+}
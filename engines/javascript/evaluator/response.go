@@ -0,0 +1,104 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/robbyt/go-polyscript/platform/data"
+)
+
+// execResult holds the Go value exported from the JavaScript runtime
+type execResult struct {
+	value       any
+	execTime    time.Duration
+	scriptExeID string
+	logHandler  slog.Handler
+	logger      *slog.Logger
+}
+
+func newEvalResult(
+	handler slog.Handler,
+	value any,
+	execTime time.Duration,
+	versionID string,
+) *execResult {
+	if handler == nil {
+		defaultHandler := slog.NewTextHandler(os.Stdout, nil)
+		handler = defaultHandler.WithGroup("javascript")
+		// Create a logger from the handler rather than using slog directly
+		defaultLogger := slog.New(handler)
+		defaultLogger.Warn("Handler is nil, using the default logger configuration.")
+	}
+
+	return &execResult{
+		value:       value,
+		execTime:    execTime,
+		scriptExeID: versionID,
+		logHandler:  handler,
+		logger:      slog.New(handler.WithGroup("execResult")),
+	}
+}
+
+func (r *execResult) String() string {
+	return fmt.Sprintf(
+		"ExecResult{Type: %s, Value: %v, ExecTime: %s, ScriptExeID: %s}",
+		r.Type(), r.value, r.GetExecTime(), r.GetScriptExeID())
+}
+
+func (r *execResult) Type() data.Types {
+	// Map the exported JavaScript values to our internal types
+	switch r.value.(type) {
+	case nil:
+		return data.NONE
+	case bool:
+		return data.BOOL
+	case int, int32, int64:
+		return data.INT
+	case float32, float64:
+		return data.FLOAT
+	case string:
+		return data.STRING
+	case []any:
+		return data.LIST
+	case map[string]any:
+		return data.MAP
+	case func(goja.FunctionCall) goja.Value:
+		return data.FUNCTION
+	default:
+		r.logger.Error("Unknown type", "type", fmt.Sprintf("%T", r.value))
+		return data.ERROR
+	}
+}
+
+func (r *execResult) GetScriptExeID() string {
+	return r.scriptExeID
+}
+
+func (r *execResult) GetExecTime() string {
+	return r.execTime.String()
+}
+
+func (r *execResult) Inspect() string {
+	switch r.Type() {
+	case data.LIST, data.MAP:
+		jsonBytes, err := json.Marshal(r.value)
+		if err != nil {
+			r.logger.Error("Failed to marshal value to JSON", "error", err)
+			return fmt.Sprintf("%v", r.value)
+		}
+		return string(jsonBytes)
+	case data.NONE:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", r.value)
+	}
+}
+
+// Interface returns the Go native value exported from the JavaScript runtime
+func (r *execResult) Interface() any {
+	return r.value
+}
@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecResult(t *testing.T) {
+	t.Parallel()
+
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	tests := []struct {
+		name        string
+		value       any
+		wantType    data.Types
+		wantInspect string
+	}{
+		{name: "nil", value: nil, wantType: data.NONE, wantInspect: "null"},
+		{name: "bool", value: true, wantType: data.BOOL, wantInspect: "true"},
+		{name: "int", value: int64(42), wantType: data.INT, wantInspect: "42"},
+		{name: "float", value: 1.5, wantType: data.FLOAT, wantInspect: "1.5"},
+		{name: "string", value: "hello", wantType: data.STRING, wantInspect: "hello"},
+		{name: "list", value: []any{int64(1), "a"}, wantType: data.LIST, wantInspect: `[1,"a"]`},
+		{
+			name:        "map",
+			value:       map[string]any{"key": "value"},
+			wantType:    data.MAP,
+			wantInspect: `{"key":"value"}`,
+		},
+		{
+			name:     "function",
+			value:    func(goja.FunctionCall) goja.Value { return nil },
+			wantType: data.FUNCTION,
+		},
+		{name: "unknown", value: struct{}{}, wantType: data.ERROR, wantInspect: "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := newEvalResult(handler, tt.value, time.Second, "test-id")
+			require.Equal(t, tt.wantType, result.Type())
+			require.Equal(t, "test-id", result.GetScriptExeID())
+			require.Equal(t, "1s", result.GetExecTime())
+			if tt.wantType != data.FUNCTION {
+				require.Equal(t, tt.value, result.Interface())
+				require.Equal(t, tt.wantInspect, result.Inspect())
+			}
+			require.Contains(t, result.String(), "ExecResult{Type: "+string(tt.wantType))
+		})
+	}
+
+	t.Run("nil handler", func(t *testing.T) {
+		result := newEvalResult(nil, "value", 0, "")
+		require.NotNil(t, result.logHandler)
+		require.NotNil(t, result.logger)
+	})
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// SetupConsole adds a minimal "console" object to the runtime. Messages written with
+// console.log, console.info, console.warn, console.error, and console.debug are sent to
+// the logger at the matching level.
+func SetupConsole(ctx context.Context, vm *goja.Runtime, logger *slog.Logger) error {
+	console := vm.NewObject()
+
+	levels := map[string]slog.Level{
+		"log":   slog.LevelInfo,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"debug": slog.LevelDebug,
+	}
+	for name, level := range levels {
+		fn := func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				parts = append(parts, arg.String())
+			}
+			logger.Log(ctx, level, strings.Join(parts, " "), "source", "console."+name)
+			return goja.Undefined()
+		}
+		if err := console.Set(name, fn); err != nil {
+			return fmt.Errorf("failed to set console.%s: %w", name, err)
+		}
+	}
+
+	return vm.Set("console", console)
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupConsole(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	vm := goja.New()
+	require.NoError(t, SetupConsole(t.Context(), vm, logger))
+
+	_, err := vm.RunString(`
+		console.log("hello", 1, true);
+		console.warn("careful");
+		console.error("broken");
+		console.debug("details");
+	`)
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, `level=INFO msg="hello 1 true" source=console.log`)
+	require.Contains(t, out, `level=WARN msg=careful source=console.warn`)
+	require.Contains(t, out, `level=ERROR msg=broken source=console.error`)
+	require.Contains(t, out, `level=DEBUG msg=details source=console.debug`)
+}
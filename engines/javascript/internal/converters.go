@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/dop251/goja"
+)
+
+// ConvertToJavaScriptFormat converts a Go map into a JavaScript object, which is exposed to
+// scripts as the "ctx" global. Every value is copied into a new JavaScript value, so scripts
+// cannot modify the Go data they were given.
+func ConvertToJavaScriptFormat(vm *goja.Runtime, inputData map[string]any) (*goja.Object, error) {
+	if vm == nil {
+		return nil, fmt.Errorf("javascript runtime is nil")
+	}
+
+	ctxObj := vm.NewObject()
+
+	// Convert each input data key-value pair and add to the ctx object
+	errz := make([]error, 0, len(inputData))
+	for k, v := range inputData {
+		jsVal, err := ConvertToJavaScriptValue(vm, v)
+		if err != nil {
+			// Collect errors but continue processing
+			errz = append(errz, fmt.Errorf("failed to convert input value for key %q: %w", k, err))
+			continue
+		}
+		if err := ctxObj.Set(k, jsVal); err != nil {
+			errz = append(errz, fmt.Errorf("failed to set ctx key %q: %w", k, err))
+			continue
+		}
+	}
+
+	// return if there were any errors
+	if len(errz) > 0 {
+		return nil, errors.Join(errz...)
+	}
+
+	return ctxObj, nil
+}
+
+// ConvertToJavaScriptValue converts a Go value to a JavaScript value owned by the runtime
+func ConvertToJavaScriptValue(vm *goja.Runtime, v any) (goja.Value, error) {
+	if v == nil {
+		return goja.Null(), nil
+	}
+
+	switch val := v.(type) {
+	case bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return vm.ToValue(val), nil
+	case *url.URL:
+		return vm.ToValue(val.String()), nil
+	case []any:
+		elements := make([]any, len(val))
+		for i, elem := range val {
+			jsVal, err := ConvertToJavaScriptValue(vm, elem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert list element: %w", err)
+			}
+			elements[i] = jsVal
+		}
+		return vm.NewArray(elements...), nil
+	case []string:
+		elements := make([]any, len(val))
+		for i, elem := range val {
+			elements[i] = elem
+		}
+		return vm.NewArray(elements...), nil
+	case map[string]struct{}:
+		// golang doesn't have a Set, but often a map[string]struct{} is used instead.
+		// This is exposed as an object with each member set to true.
+		obj := vm.NewObject()
+		for k := range val {
+			if err := obj.Set(k, true); err != nil {
+				return nil, fmt.Errorf("failed to set object key: %w", err)
+			}
+		}
+		return obj, nil
+	case map[string][]string:
+		// Special handling for HTTP headers and query params
+		obj := vm.NewObject()
+		for k, values := range val {
+			elements := make([]any, len(values))
+			for i, v := range values {
+				elements[i] = v
+			}
+			if err := obj.Set(k, vm.NewArray(elements...)); err != nil {
+				return nil, fmt.Errorf("failed to set object key: %w", err)
+			}
+		}
+		return obj, nil
+	case map[string]any:
+		obj := vm.NewObject()
+		for k, v := range val {
+			jsVal, err := ConvertToJavaScriptValue(vm, v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert object value: %w", err)
+			}
+			if err := obj.Set(k, jsVal); err != nil {
+				return nil, fmt.Errorf("failed to set object key: %w", err)
+			}
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// ConvertJavaScriptValueToInterface converts a JavaScript value to a Go any value.
+// Objects become map[string]any, arrays become []any, and integral numbers become int64.
+func ConvertJavaScriptValueToInterface(v goja.Value) any {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil
+	}
+	return v.Export()
+}
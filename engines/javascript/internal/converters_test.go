@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertToJavaScriptValue(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("https://example.com/path?q=1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{name: "nil", input: nil, want: nil},
+		{name: "bool", input: true, want: true},
+		{name: "int", input: 42, want: int64(42)},
+		{name: "int64", input: int64(-7), want: int64(-7)},
+		{name: "float", input: 3.5, want: 3.5},
+		{name: "string", input: "hello", want: "hello"},
+		{name: "url", input: u, want: "https://example.com/path?q=1"},
+		{name: "list", input: []any{1, "two", false}, want: []any{int64(1), "two", false}},
+		{name: "string list", input: []string{"a", "b"}, want: []any{"a", "b"}},
+		{
+			name:  "set",
+			input: map[string]struct{}{"x": {}},
+			want:  map[string]any{"x": true},
+		},
+		{
+			name:  "headers",
+			input: map[string][]string{"Accept": {"text/plain", "application/json"}},
+			want:  map[string]any{"Accept": []any{"text/plain", "application/json"}},
+		},
+		{
+			name:  "nested map",
+			input: map[string]any{"outer": map[string]any{"inner": []any{1.5}}},
+			want:  map[string]any{"outer": map[string]any{"inner": []any{1.5}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			vm := goja.New()
+			val, err := ConvertToJavaScriptValue(vm, tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ConvertJavaScriptValueToInterface(val))
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		vm := goja.New()
+		_, err := ConvertToJavaScriptValue(vm, struct{}{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported type")
+
+		_, err = ConvertToJavaScriptValue(vm, []any{make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to convert list element")
+	})
+}
+
+func TestConvertToJavaScriptFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		vm := goja.New()
+		input := map[string]any{
+			"name":   "World",
+			"config": map[string]any{"retries": 3},
+		}
+		ctxObj, err := ConvertToJavaScriptFormat(vm, input)
+		require.NoError(t, err)
+		require.NoError(t, vm.Set("ctx", ctxObj))
+
+		val, err := vm.RunString(`ctx.name + ":" + ctx.config.retries`)
+		require.NoError(t, err)
+		require.Equal(t, "World:3", val.String())
+	})
+
+	t.Run("script changes do not modify input", func(t *testing.T) {
+		vm := goja.New()
+		config := map[string]any{"retries": 3}
+		ctxObj, err := ConvertToJavaScriptFormat(vm, map[string]any{"config": config})
+		require.NoError(t, err)
+		require.NoError(t, vm.Set("ctx", ctxObj))
+
+		_, err = vm.RunString(`ctx.config.retries = 10; ctx.config.added = true;`)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"retries": 3}, config)
+	})
+
+	t.Run("conversion errors", func(t *testing.T) {
+		vm := goja.New()
+		_, err := ConvertToJavaScriptFormat(vm, map[string]any{"bad": make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `key "bad"`)
+	})
+
+	t.Run("nil runtime", func(t *testing.T) {
+		_, err := ConvertToJavaScriptFormat(nil, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestConvertJavaScriptValueToInterface(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, ConvertJavaScriptValueToInterface(nil))
+	require.Nil(t, ConvertJavaScriptValueToInterface(goja.Undefined()))
+	require.Nil(t, ConvertJavaScriptValueToInterface(goja.Null()))
+
+	vm := goja.New()
+	val, err := vm.RunString(`({a: 1, b: [true, "x"], c: 1.5})`)
+	require.NoError(t, err)
+	require.Equal(t,
+		map[string]any{"a": int64(1), "b": []any{true, "x"}, "c": 1.5},
+		ConvertJavaScriptValueToInterface(val),
+	)
+}
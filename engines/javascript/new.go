@@ -0,0 +1,106 @@
+package javascript
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/robbyt/go-polyscript/engines/javascript/compiler"
+	"github.com/robbyt/go-polyscript/engines/javascript/evaluator"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+)
+
+// FromJavaScriptLoader creates a JavaScript evaluator from a loader with dynamic data only (ContextProvider)
+//
+// Input parameters:
+// - logHandler: logger handler for logging
+// - ldr: loader implementation for loading the JavaScript script content
+// - opts: optional compiler options, such as WithEvalOptions
+//
+// Returns an evaluator, which implements the evaluation.Evaluator interface.
+func FromJavaScriptLoader(
+	logHandler slog.Handler,
+	ldr loader.Loader,
+	opts ...compiler.FunctionalOption,
+) (*evaluator.Evaluator, error) {
+	return NewEvaluator(
+		logHandler,
+		ldr,
+		data.NewContextProvider(constants.EvalData),
+		opts...,
+	)
+}
+
+// FromJavaScriptLoaderWithData creates a JavaScript evaluator with both static and dynamic data capabilities.
+//
+// Input parameters:
+// - logHandler: logger handler for logging
+// - ldr: loader implementation for loading the JavaScript script content
+// - staticData: map of initial static data to be passed to the script
+// - opts: optional compiler options, such as WithEvalOptions
+//
+// Returns an evaluator, which implements the evaluation.Evaluator interface.
+func FromJavaScriptLoaderWithData(
+	logHandler slog.Handler,
+	ldr loader.Loader,
+	staticData map[string]any,
+	opts ...compiler.FunctionalOption,
+) (*evaluator.Evaluator, error) {
+	staticProvider := data.NewStaticProvider(staticData)
+	dynamicProvider := data.NewContextProvider(constants.EvalData)
+	compositeProvider := data.NewCompositeProvider(staticProvider, dynamicProvider)
+
+	// Create the evaluator
+	return NewEvaluator(
+		logHandler,
+		ldr,
+		compositeProvider,
+		opts...,
+	)
+}
+
+// NewCompiler creates a new JavaScript compiler using the functional options pattern.
+// Returns a compiler implementing the script.Compiler interface.
+func NewCompiler(opts ...compiler.FunctionalOption) (*compiler.Compiler, error) {
+	return compiler.New(opts...)
+}
+
+// NewEvaluator creates a JavaScript evaluator with bytecode loaded, and ready for execution.
+// Returns a Evaluator, which implements the evaluation.Evaluator interface.
+func NewEvaluator(
+	logHandler slog.Handler,
+	ldr loader.Loader,
+	dataProvider data.Provider,
+	opts ...compiler.FunctionalOption,
+) (*evaluator.Evaluator, error) {
+	if dataProvider == nil {
+		return nil, fmt.Errorf("provider is nil")
+	}
+
+	compiler, err := NewCompiler(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JavaScript compiler: %w", err)
+	}
+
+	execUnitID := ""
+	sourceURL := ldr.GetSourceURL()
+	if sourceURL != nil {
+		execUnitID = sourceURL.String()
+	}
+
+	// Create executable unit (to compile and prepare the script)
+	execUnit, err := script.NewExecutableUnit(
+		logHandler,
+		execUnitID,
+		ldr,
+		compiler,
+		dataProvider,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluator.New(logHandler, execUnit), nil
+}
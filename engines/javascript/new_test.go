@@ -0,0 +1,173 @@
+package javascript
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/engines/javascript/compiler"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJavaScriptScript = `
+// Simple JavaScript script that logs a message
+console.log("Hello from JavaScript");
+
+function greet(name) {
+	return "Hello, " + name;
+}
+
+greet(ctx.name || "World");
+`
+
+// Helper function to create a string loader with test script
+func createTestLoader(t *testing.T) *loader.FromString {
+	t.Helper()
+	stringLoader, err := loader.NewFromString(testJavaScriptScript)
+	require.NoError(t, err)
+	require.NotNil(t, stringLoader)
+	return stringLoader
+}
+
+// Helper function to create a mock loader that fails to load the script
+func createFailingLoader(t *testing.T) *loader.MockLoader {
+	t.Helper()
+	mockLoader := new(loader.MockLoader)
+	mockURL, err := url.Parse("file:///test-javascript-file.js")
+	require.NoError(t, err, "Failed to parse URL")
+	mockLoader.On("GetSourceURL").Return(mockURL)
+	mockLoader.On("GetReader").Return(nil, fmt.Errorf("failed to load script"))
+	return mockLoader
+}
+
+func TestFromJavaScriptLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		evalInstance, err := FromJavaScriptLoader(handler, createTestLoader(t))
+		require.NoError(t, err)
+		require.NotNil(t, evalInstance)
+		assert.Equal(t, "javascript.Evaluator", evalInstance.String())
+
+		ctx, err := evalInstance.AddDataToContext(t.Context(), map[string]any{"name": "Ada"})
+		require.NoError(t, err)
+		response, err := evalInstance.Eval(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, Ada", response.Interface())
+	})
+
+	t.Run("error from loader", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		mockLoader := createFailingLoader(t)
+
+		evalInstance, err := FromJavaScriptLoader(handler, mockLoader)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.Contains(t, err.Error(), "failed to load script")
+		mockLoader.AssertExpectations(t)
+	})
+}
+
+func TestFromJavaScriptLoaderWithData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success with static data", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		staticData := map[string]any{"name": "Static"}
+
+		evalInstance, err := FromJavaScriptLoaderWithData(handler, createTestLoader(t), staticData)
+		require.NoError(t, err)
+		require.NotNil(t, evalInstance)
+
+		response, err := evalInstance.Eval(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, Static", response.Interface())
+	})
+
+	t.Run("error from loader", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		mockLoader := createFailingLoader(t)
+
+		evalInstance, err := FromJavaScriptLoaderWithData(handler, mockLoader, nil)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.Contains(t, err.Error(), "failed to load script")
+		mockLoader.AssertExpectations(t)
+	})
+}
+
+func TestNewCompiler(t *testing.T) {
+	t.Parallel()
+
+	comp, err := NewCompiler(
+		compiler.WithLogHandler(slog.NewTextHandler(os.Stdout, nil)),
+		compiler.WithStrictMode(true),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, comp)
+}
+
+func TestNewEvaluator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil provider", func(t *testing.T) {
+		evalInstance, err := NewEvaluator(nil, createTestLoader(t), nil)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		require.Contains(t, err.Error(), "provider is nil")
+	})
+
+	t.Run("invalid script syntax", func(t *testing.T) {
+		invalidLoader, err := loader.NewFromString(`function ( {`)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			invalidLoader,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.ErrorIs(t, err, compiler.ErrValidationFailed)
+	})
+
+	t.Run("from disk loader", func(t *testing.T) {
+		tempFilePath := filepath.Join(t.TempDir(), "test.js")
+		require.NoError(t, os.WriteFile(tempFilePath, []byte(testJavaScriptScript), 0o644))
+
+		diskLoader, err := loader.NewFromDisk(tempFilePath)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			diskLoader,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.NoError(t, err)
+
+		response, err := evalInstance.Eval(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, World", response.Interface())
+	})
+}
+
+func TestRegistration(t *testing.T) {
+	t.Parallel()
+
+	e, ok := registry.Lookup(compiler.MachineType)
+	require.True(t, ok)
+	assert.Equal(t, []string{".js"}, e.Extensions)
+
+	comp, err := registry.NewCompiler(compiler.WithStrictMode(false))
+	require.NoError(t, err)
+	assert.IsType(t, &compiler.Compiler{}, comp)
+}
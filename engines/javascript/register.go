@@ -0,0 +1,57 @@
+package javascript
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/robbyt/go-polyscript/engines/javascript/compiler"
+	"github.com/robbyt/go-polyscript/engines/javascript/evaluator"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
+)
+
+func init() {
+	registry.MustRegister(registry.Engine{
+		Type:               compiler.MachineType,
+		Extensions:         []string{".js"},
+		NewCompiler:        newCompilerFromOptions,
+		NewEvaluator:       newEvaluatorFromUnit,
+		NewBundleEvaluator: newEvaluatorFromBundle,
+	})
+}
+
+// newCompilerFromOptions is the registry.CompilerFactory for JavaScript. It only accepts
+// compiler.FunctionalOption values.
+func newCompilerFromOptions(opts ...any) (script.Compiler, error) {
+	javascriptOpts, ok := registry.MatchOptions[compiler.FunctionalOption](opts)
+	if !ok {
+		return nil, registry.ErrOptionsNotSupported
+	}
+
+	c, err := compiler.New(javascriptOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JavaScript compiler: %w", err)
+	}
+	return c, nil
+}
+
+// newEvaluatorFromUnit is the registry.EvaluatorFactory for JavaScript.
+func newEvaluatorFromUnit(
+	handler slog.Handler,
+	unit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) platform.Evaluator {
+	return evaluator.New(handler, unit, opts...)
+}
+
+// newEvaluatorFromBundle is the registry.BundleEvaluatorFactory for JavaScript.
+func newEvaluatorFromBundle(handler slog.Handler, b *bundle.Bundle) (platform.Evaluator, error) {
+	m := b.Manifest()
+	e, err := FromJavaScriptLoaderWithData(handler, b.Loader(), m.StaticData)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
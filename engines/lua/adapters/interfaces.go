@@ -0,0 +1,7 @@
+package adapters
+
+import luaLib "github.com/yuin/gopher-lua"
+
+type LuaExecutable struct {
+	GetLuaByteCode func() *luaLib.FunctionProto
+}
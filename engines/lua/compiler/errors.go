@@ -0,0 +1,11 @@
+package compiler
+
+import "errors"
+
+var (
+	ErrBytecodeNil        = errors.New("lua bytecode is nil")
+	ErrContentNil         = errors.New("lua content is nil")
+	ErrExecCreationFailed = errors.New("unable to create lua executable")
+	ErrNoInstructions     = errors.New("lua script is empty")
+	ErrValidationFailed   = errors.New("lua script validation error")
+)
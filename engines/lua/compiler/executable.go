@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// MachineType is the machine type of Lua executables. It's registered with the file
+// extensions of Lua scripts by the lua package.
+const MachineType machineTypes.Type = "lua"
+
+// executable represents a compiled Lua function prototype
+type executable struct {
+	scriptBodyBytes []byte
+	ByteCode        *luaLib.FunctionProto
+	evalOptions     []platform.EvalOption
+}
+
+func newExecutable(scriptBodyBytes []byte, byteCode *luaLib.FunctionProto) *executable {
+	if len(scriptBodyBytes) == 0 || byteCode == nil {
+		return nil
+	}
+
+	return &executable{
+		scriptBodyBytes: scriptBodyBytes,
+		ByteCode:        byteCode,
+	}
+}
+
+func (e *executable) GetSource() string {
+	return string(e.scriptBodyBytes)
+}
+
+func (e *executable) GetByteCode() any {
+	return e.ByteCode
+}
+
+func (e *executable) GetLuaByteCode() *luaLib.FunctionProto {
+	return e.ByteCode
+}
+
+func (e *executable) GetMachineType() machineTypes.Type {
+	return MachineType
+}
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
+}
@@ -0,0 +1,34 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// TestExecutable tests the functionality of Executable
+func TestExecutable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid creation", func(t *testing.T) {
+		content := "return 1 + 1"
+		bytecode := &luaLib.FunctionProto{}
+
+		exe := newExecutable([]byte(content), bytecode)
+		require.NotNil(t, exe)
+		assert.Equal(t, content, exe.GetSource())
+		assert.Equal(t, bytecode, exe.GetByteCode())
+		assert.Equal(t, bytecode, exe.GetLuaByteCode())
+		assert.Equal(t, MachineType, exe.GetMachineType())
+	})
+
+	t.Run("nil content", func(t *testing.T) {
+		assert.Nil(t, newExecutable(nil, &luaLib.FunctionProto{}))
+	})
+
+	t.Run("nil bytecode", func(t *testing.T) {
+		assert.Nil(t, newExecutable([]byte("return 1"), nil))
+	})
+}
@@ -0,0 +1,32 @@
+package compile
+
+import (
+	"bytes"
+	"fmt"
+
+	luaLib "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// chunkName is the name used for compiled chunks in Lua error messages and stack traces
+const chunkName = "script"
+
+// Compile parses and compiles the script content into a Lua function prototype.
+// The prototype is not bound to an LState, so it can be shared by concurrent evaluations.
+func Compile(scriptBodyBytes []byte) (*luaLib.FunctionProto, error) {
+	if scriptBodyBytes == nil {
+		return nil, ErrContentNil
+	}
+
+	chunk, err := parse.Parse(bytes.NewReader(scriptBodyBytes), chunkName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileFailed, err)
+	}
+
+	proto, err := luaLib.Compile(chunk, chunkName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileFailed, err)
+	}
+
+	return proto, nil
+}
@@ -0,0 +1,37 @@
+package compile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompileSuccess tests the successful compilation of valid script content
+func TestCompileSuccess(t *testing.T) {
+	proto, err := Compile([]byte(`local x = 1 + 1 return x`))
+	require.NoError(t, err)
+	require.NotNil(t, proto)
+}
+
+// TestCompileUndeclaredGlobal tests that globals injected at eval time do not fail compilation
+func TestCompileUndeclaredGlobal(t *testing.T) {
+	proto, err := Compile([]byte(`return ctx.name`))
+	require.NoError(t, err)
+	require.NotNil(t, proto)
+}
+
+// TestCompileSyntaxError tests the compilation failure due to syntax errors
+func TestCompileSyntaxError(t *testing.T) {
+	proto, err := Compile([]byte(`function (`))
+	require.Error(t, err)
+	require.Nil(t, proto)
+	require.ErrorIs(t, err, ErrCompileFailed)
+}
+
+// TestCompileNilContent tests the handling of nil script content
+func TestCompileNilContent(t *testing.T) {
+	proto, err := Compile(nil)
+	require.Error(t, err)
+	require.Nil(t, proto)
+	require.ErrorIs(t, err, ErrContentNil)
+}
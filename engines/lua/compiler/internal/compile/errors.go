@@ -0,0 +1,8 @@
+package compile
+
+import "errors"
+
+var (
+	ErrCompileFailed = errors.New("failed to compile lua")
+	ErrContentNil    = errors.New("lua content is nil")
+)
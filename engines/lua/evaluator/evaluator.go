@@ -0,0 +1,256 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/robbyt/go-polyscript/engines/lua/internal"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// resultGlobal is the global variable checked for a result when the script does not return one
+const resultGlobal = "result"
+
+// Evaluator is an abstraction layer for evaluating code on the Lua engine
+type Evaluator struct {
+	// ctxKey is the variable name used to access input data inside the script (e.g., "ctx")
+	ctxKey string
+
+	// execUnit contains the compiled script and data provider
+	execUnit *script.ExecutableUnit
+
+	// evalOpts configures evaluation, such as the default timeout
+	evalOpts platform.EvalOptions
+
+	logHandler slog.Handler
+	logger     *slog.Logger
+}
+
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
+func New(
+	handler slog.Handler,
+	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) *Evaluator {
+	handler, logger := helpers.SetupLogger(handler, "lua", "Evaluator")
+
+	return &Evaluator{
+		ctxKey:     constants.Ctx,
+		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
+		logHandler: handler,
+		logger:     logger,
+	}
+}
+
+func (be *Evaluator) String() string {
+	return "lua.Evaluator"
+}
+
+// getDataProvider returns the data provider from the executable unit, or nil if unavailable.
+func (be *Evaluator) getDataProvider() data.Provider {
+	if be.execUnit == nil {
+		return nil
+	}
+	return be.execUnit.GetDataProvider()
+}
+
+// loadInputData retrieves input data using the data provider in the executable unit.
+// Returns a map that will be used as input for the Lua state.
+func (be *Evaluator) loadInputData(ctx context.Context) (map[string]any, error) {
+	return data.LoadInputData(ctx, be.logger.WithGroup("loadInputData"), be.getDataProvider())
+}
+
+// newState creates an LState for a single evaluation, with the sandboxed libraries loaded and
+// the ctx global populated. An LState is not safe for concurrent use, so one is created per Eval.
+// The caller must close the returned state.
+func (be *Evaluator) newState(
+	ctx context.Context,
+	inputData map[string]any,
+) (*luaLib.LState, error) {
+	L := luaLib.NewState(luaLib.Options{SkipOpenLibs: true})
+	openSandboxedLibs(L)
+
+	// Send print output to the logger, rather than stdout
+	logger := be.logger.WithGroup("print")
+	L.SetGlobal("print", L.NewFunction(func(L *luaLib.LState) int {
+		parts := make([]string, 0, L.GetTop())
+		for i := 1; i <= L.GetTop(); i++ {
+			parts = append(parts, L.ToStringMeta(L.Get(i)).String())
+		}
+		logger.InfoContext(ctx, strings.Join(parts, "\t"))
+		return 0
+	}))
+
+	ctxTable, err := internal.ConvertToLuaFormat(L, inputData)
+	if err != nil {
+		L.Close()
+		return nil, fmt.Errorf("failed to convert input data: %w", err)
+	}
+	L.SetGlobal(be.ctxKey, ctxTable)
+
+	return L, nil
+}
+
+// sandboxedLibs are the standard libraries available to scripts. The os, io, package, and debug
+// libraries are left out, so scripts can't run commands, or read and write files.
+var sandboxedLibs = []struct {
+	name string
+	open luaLib.LGFunction
+}{
+	{luaLib.BaseLibName, luaLib.OpenBase},
+	{luaLib.TabLibName, luaLib.OpenTable},
+	{luaLib.StringLibName, luaLib.OpenString},
+	{luaLib.MathLibName, luaLib.OpenMath},
+}
+
+// unsafeBaseFuncs are the functions from the base library that load code from files, modules,
+// or strings at run time
+var unsafeBaseFuncs = []string{"dofile", "loadfile", "load", "loadstring", "require", "module"}
+
+// openSandboxedLibs opens the sandboxed libraries on the state, and removes the unsafe base
+// functions
+func openSandboxedLibs(L *luaLib.LState) {
+	for _, lib := range sandboxedLibs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(luaLib.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range unsafeBaseFuncs {
+		L.SetGlobal(name, luaLib.LNil)
+	}
+}
+
+// call runs the function on the state, and returns its first return value
+func call(L *luaLib.LState, fn *luaLib.LFunction) (luaLib.LValue, error) {
+	L.Push(fn)
+	if err := L.PCall(0, 1, nil); err != nil {
+		return nil, err
+	}
+	val := L.Get(-1)
+	L.Pop(1)
+	return val, nil
+}
+
+// exec executes the function prototype on the state
+func (be *Evaluator) exec(
+	ctx context.Context,
+	L *luaLib.LState,
+	proto *luaLib.FunctionProto,
+) (*execResult, error) {
+	logger := be.logger.WithGroup("exec")
+	startTime := time.Now()
+
+	// The state checks the context while running, and stops when it is done
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+
+	mainVal, err := call(L, L.NewFunctionFromProto(proto))
+	if err != nil {
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("lua execution error: %w: %w", ctxErr, err)
+		}
+		return nil, fmt.Errorf("lua execution error: %w", err)
+	}
+
+	// When the script doesn't return a value, look for a global named "result"
+	if mainVal == luaLib.LNil {
+		if resultVal := L.GetGlobal(resultGlobal); resultVal != luaLib.LNil {
+			logger.InfoContext(ctx, "found explicit result variable", "result", resultVal)
+			mainVal = resultVal
+		}
+	}
+
+	// Handle callable results (functions)
+	if fn, ok := mainVal.(*luaLib.LFunction); ok {
+		mainVal, err = call(L, fn)
+		if err != nil {
+			if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+				return nil, fmt.Errorf("error calling function: %w: %w", ctxErr, err)
+			}
+			return nil, fmt.Errorf("error calling function: %w", err)
+		}
+	}
+
+	execTime := time.Since(startTime)
+	return newEvalResult(be.logHandler, mainVal, execTime, ""), nil
+}
+
+// Eval evaluates the loaded function prototype and passes the provided data into a new Lua state
+func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	logger := be.logger.WithGroup("Eval")
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
+	if be.execUnit == nil {
+		return nil, fmt.Errorf("executable unit is nil")
+	}
+
+	if be.execUnit.GetContent() == nil {
+		return nil, fmt.Errorf("content is nil")
+	}
+
+	// Get bytecode from executable unit
+	bytecode := be.execUnit.GetContent().GetByteCode()
+	if bytecode == nil {
+		return nil, fmt.Errorf("bytecode is nil")
+	}
+
+	// Get execution ID
+	exeID := be.execUnit.GetID()
+	if exeID == "" {
+		return nil, fmt.Errorf("exeID is empty")
+	}
+	logger = logger.With("exeID", exeID)
+
+	// 1. Type assert to Lua function prototype
+	proto, ok := bytecode.(*luaLib.FunctionProto)
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid bytecode type: expected *lua.FunctionProto, got %T",
+			bytecode,
+		)
+	}
+
+	// 2. Get the raw input data
+	rawInputData, err := be.loadInputData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input data: %w", err)
+	}
+
+	// 3. Create a fresh state with the input data set as globals
+	L, err := be.newState(ctx, rawInputData)
+	if err != nil {
+		return nil, err
+	}
+	defer L.Close()
+
+	// 4. Execute the program
+	result, err := be.exec(ctx, L, proto)
+	if err != nil {
+		return nil, fmt.Errorf("exec error: %w", err)
+	}
+	logger.DebugContext(ctx, "exec complete", "result", result)
+
+	// 5. Collect results
+	result.scriptExeID = exeID
+	return result, nil
+}
+
+// AddDataToContext implements the data.Setter interface which stores and prepares runtime data
+// which can be eventually passed to the Eval method.
+func (be *Evaluator) AddDataToContext(
+	ctx context.Context,
+	d ...map[string]any,
+) (context.Context, error) {
+	return data.AddDataToContextFromProvider(ctx, be.logger.WithGroup("AddDataToContext"), be.getDataProvider(), d...)
+}
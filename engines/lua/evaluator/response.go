@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/robbyt/go-polyscript/engines/lua/internal"
+	"github.com/robbyt/go-polyscript/platform/data"
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// execResult is a wrapper around the lua.LValue interface
+type execResult struct {
+	luaLib.LValue
+	execTime    time.Duration
+	scriptExeID string
+	logHandler  slog.Handler
+	logger      *slog.Logger
+}
+
+func newEvalResult(
+	handler slog.Handler,
+	obj luaLib.LValue,
+	execTime time.Duration,
+	versionID string,
+) *execResult {
+	if handler == nil {
+		defaultHandler := slog.NewTextHandler(os.Stdout, nil)
+		handler = defaultHandler.WithGroup("lua")
+		// Create a logger from the handler rather than using slog directly
+		defaultLogger := slog.New(handler)
+		defaultLogger.Warn("Handler is nil, using the default logger configuration.")
+	}
+
+	if obj == nil {
+		obj = luaLib.LNil
+	}
+
+	return &execResult{
+		LValue:      obj,
+		execTime:    execTime,
+		scriptExeID: versionID,
+		logHandler:  handler,
+		logger:      slog.New(handler.WithGroup("execResult")),
+	}
+}
+
+func (r *execResult) String() string {
+	return fmt.Sprintf(
+		"ExecResult{Type: %s, Value: %v, ExecTime: %s, ScriptExeID: %s}",
+		r.Type(), r.LValue, r.GetExecTime(), r.GetScriptExeID())
+}
+
+func (r *execResult) Type() data.Types {
+	// Map Lua types to our internal types
+	switch v := r.LValue.(type) {
+	case *luaLib.LNilType:
+		return data.NONE
+	case luaLib.LBool:
+		return data.BOOL
+	case luaLib.LNumber:
+		if internal.IsInteger(v) {
+			return data.INT
+		}
+		return data.FLOAT
+	case luaLib.LString:
+		return data.STRING
+	case *luaLib.LTable:
+		if internal.IsArray(v) {
+			return data.LIST
+		}
+		return data.MAP
+	case *luaLib.LFunction:
+		return data.FUNCTION
+	default:
+		r.logger.Error("Unknown type", "type", r.LValue.Type().String())
+		return data.ERROR
+	}
+}
+
+func (r *execResult) GetScriptExeID() string {
+	return r.scriptExeID
+}
+
+func (r *execResult) GetExecTime() string {
+	return r.execTime.String()
+}
+
+func (r *execResult) Inspect() string {
+	if _, ok := r.LValue.(*luaLib.LTable); !ok {
+		return r.LValue.String()
+	}
+
+	// Tables are shown as JSON, rather than as a memory address
+	jsonBytes, err := json.Marshal(r.Interface())
+	if err != nil {
+		r.logger.Error("Failed to marshal table to JSON", "error", err)
+		return r.LValue.String()
+	}
+	return string(jsonBytes)
+}
+
+// Interface returns the Go native type for the Lua value
+func (r *execResult) Interface() any {
+	v, err := internal.ConvertLuaValueToInterface(r.LValue)
+	if err != nil {
+		r.logger.Error("Failed to convert Lua value to interface", "error", err)
+		return nil
+	}
+	return v
+}
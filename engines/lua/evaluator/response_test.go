@@ -0,0 +1,111 @@
+package evaluator
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/stretchr/testify/require"
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+func TestExecResult(t *testing.T) {
+	t.Parallel()
+
+	handler := slog.NewTextHandler(os.Stdout, nil)
+	L := luaLib.NewState()
+	defer L.Close()
+
+	list := L.CreateTable(2, 0)
+	list.Append(luaLib.LNumber(1))
+	list.Append(luaLib.LString("a"))
+
+	dict := L.CreateTable(0, 1)
+	dict.RawSetString("key", luaLib.LString("value"))
+
+	tests := []struct {
+		name          string
+		value         luaLib.LValue
+		wantType      data.Types
+		wantInterface any
+		wantInspect   string
+	}{
+		{
+			name:          "nil",
+			value:         luaLib.LNil,
+			wantType:      data.NONE,
+			wantInterface: nil,
+			wantInspect:   "nil",
+		},
+		{
+			name:          "bool",
+			value:         luaLib.LTrue,
+			wantType:      data.BOOL,
+			wantInterface: true,
+			wantInspect:   "true",
+		},
+		{
+			name:          "int",
+			value:         luaLib.LNumber(42),
+			wantType:      data.INT,
+			wantInterface: int64(42),
+			wantInspect:   "42",
+		},
+		{
+			name:          "float",
+			value:         luaLib.LNumber(1.5),
+			wantType:      data.FLOAT,
+			wantInterface: 1.5,
+			wantInspect:   "1.5",
+		},
+		{
+			name:          "string",
+			value:         luaLib.LString("hello"),
+			wantType:      data.STRING,
+			wantInterface: "hello",
+			wantInspect:   "hello",
+		},
+		{
+			name:          "list",
+			value:         list,
+			wantType:      data.LIST,
+			wantInterface: []any{int64(1), "a"},
+			wantInspect:   `[1,"a"]`,
+		},
+		{
+			name:          "map",
+			value:         dict,
+			wantType:      data.MAP,
+			wantInterface: map[string]any{"key": "value"},
+			wantInspect:   `{"key":"value"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newEvalResult(handler, tt.value, time.Second, "test-id")
+			require.Equal(t, tt.wantType, result.Type())
+			require.Equal(t, tt.wantInterface, result.Interface())
+			require.Equal(t, tt.wantInspect, result.Inspect())
+			require.Equal(t, "test-id", result.GetScriptExeID())
+			require.Equal(t, "1s", result.GetExecTime())
+			require.Contains(t, result.String(), "ExecResult{Type: "+string(tt.wantType))
+		})
+	}
+
+	t.Run("function", func(t *testing.T) {
+		fn := L.NewFunction(func(*luaLib.LState) int { return 0 })
+		result := newEvalResult(handler, fn, 0, "")
+		require.Equal(t, data.FUNCTION, result.Type())
+		require.Nil(t, result.Interface())
+	})
+
+	t.Run("nil handler and value", func(t *testing.T) {
+		result := newEvalResult(nil, nil, 0, "")
+		require.NotNil(t, result.logHandler)
+		require.NotNil(t, result.logger)
+		require.Equal(t, data.NONE, result.Type())
+	})
+}
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+// ConvertToLuaFormat converts a Go map into a Lua table, which is exposed to scripts as the
+// "ctx" global. Every value is copied into a new Lua value owned by the LState.
+func ConvertToLuaFormat(L *luaLib.LState, inputData map[string]any) (*luaLib.LTable, error) {
+	if L == nil {
+		return nil, fmt.Errorf("lua state is nil")
+	}
+
+	// Create a Lua table for the ctx global variable
+	ctxTable := L.CreateTable(0, len(inputData))
+
+	// Convert each input data key-value pair and add to the ctxTable
+	errz := make([]error, 0, len(inputData))
+	for k, v := range inputData {
+		luaVal, err := ConvertToLuaValue(L, v)
+		if err != nil {
+			// Collect errors but continue processing
+			errz = append(errz, fmt.Errorf("failed to convert input value for key %q: %w", k, err))
+			continue
+		}
+		ctxTable.RawSetString(k, luaVal)
+	}
+
+	// return if there were any errors
+	if len(errz) > 0 {
+		return nil, errors.Join(errz...)
+	}
+
+	return ctxTable, nil
+}
+
+// ConvertLuaValueToInterface converts a Lua value to a Go any value.
+//
+// Numbers with no fractional part become int64, and other numbers become float64. Tables
+// with only consecutive integer keys starting at 1 become []any, and all other tables
+// become map[string]any. An empty table becomes an empty map.
+func ConvertLuaValueToInterface(v luaLib.LValue) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch v := v.(type) {
+	case *luaLib.LNilType:
+		// Return nil for nil values
+		return nil, nil
+	case luaLib.LBool:
+		return bool(v), nil
+	case luaLib.LNumber:
+		if IsInteger(v) {
+			return int64(v), nil
+		}
+		return float64(v), nil
+	case luaLib.LString:
+		return string(v), nil
+	case *luaLib.LTable:
+		if IsArray(v) {
+			list := make([]any, 0, v.Len())
+			for i := 1; i <= v.Len(); i++ {
+				elem, err := ConvertLuaValueToInterface(v.RawGetInt(i))
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert list element: %w", err)
+				}
+				list = append(list, elem)
+			}
+			return list, nil
+		}
+
+		// Create a string-keyed map for JSON compatibility
+		dict := make(map[string]any)
+		var errz []error
+		v.ForEach(func(key, val luaLib.LValue) {
+			// Convert non-string keys to strings for JSON compatibility
+			kStr := key.String()
+
+			vv, err := ConvertLuaValueToInterface(val)
+			if err != nil {
+				errz = append(errz, fmt.Errorf("failed to convert table value: %w", err))
+				return
+			}
+			dict[kStr] = vv
+		})
+		if len(errz) > 0 {
+			return nil, errors.Join(errz...)
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("unsupported Lua type %s", v.Type())
+	}
+}
+
+// ConvertToLuaValue converts a Go value to a Lua value
+func ConvertToLuaValue(L *luaLib.LState, v any) (luaLib.LValue, error) {
+	if v == nil {
+		return luaLib.LNil, nil
+	}
+
+	switch val := v.(type) {
+	case bool:
+		return luaLib.LBool(val), nil
+	case int:
+		return luaLib.LNumber(val), nil
+	case int64:
+		return luaLib.LNumber(val), nil
+	case float64:
+		return luaLib.LNumber(val), nil
+	case string:
+		return luaLib.LString(val), nil
+	case *url.URL:
+		return luaLib.LString(val.String()), nil
+	case []any:
+		list := L.CreateTable(len(val), 0)
+		for _, elem := range val {
+			luaVal, err := ConvertToLuaValue(L, elem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert list element: %w", err)
+			}
+			list.Append(luaVal)
+		}
+		return list, nil
+	case map[string]struct{}:
+		// golang doesn't have a Set, but often a map[string]struct{} is used instead.
+		// In Lua, sets are usually written as a table with each member set to true.
+		set := L.CreateTable(0, len(val))
+		for k := range val {
+			set.RawSetString(k, luaLib.LTrue)
+		}
+		return set, nil
+	case map[string][]string:
+		// Special handling for HTTP headers and query params
+		dict := L.CreateTable(0, len(val))
+		for k, values := range val {
+			list := L.CreateTable(len(values), 0)
+			for _, v := range values {
+				list.Append(luaLib.LString(v))
+			}
+			dict.RawSetString(k, list)
+		}
+		return dict, nil
+	case map[string]any:
+		dict := L.CreateTable(0, len(val))
+		for k, v := range val {
+			luaVal, err := ConvertToLuaValue(L, v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert table value: %w", err)
+			}
+			dict.RawSetString(k, luaVal)
+		}
+		return dict, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// IsInteger reports whether the Lua number has no fractional part, and fits in an int64
+func IsInteger(n luaLib.LNumber) bool {
+	f := float64(n)
+	return f == math.Trunc(f) && f >= math.MinInt64 && f <= math.MaxInt64
+}
+
+// IsArray reports whether the table is a non-empty sequence, with only the keys 1..n set
+func IsArray(t *luaLib.LTable) bool {
+	n := t.Len()
+	if n == 0 {
+		return false
+	}
+
+	count := 0
+	isArray := true
+	t.ForEach(func(key, _ luaLib.LValue) {
+		count++
+		num, ok := key.(luaLib.LNumber)
+		if !ok || !IsInteger(num) || num < 1 || int(num) > n {
+			isArray = false
+		}
+	})
+	return isArray && count == n
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	luaLib "github.com/yuin/gopher-lua"
+)
+
+func TestConvertToLuaValue(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("https://example.com/path?q=1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{name: "nil", input: nil, want: nil},
+		{name: "bool", input: true, want: true},
+		{name: "int", input: 42, want: int64(42)},
+		{name: "int64", input: int64(-7), want: int64(-7)},
+		{name: "float", input: 3.5, want: 3.5},
+		{name: "whole float", input: 2.0, want: int64(2)},
+		{name: "string", input: "hello", want: "hello"},
+		{name: "url", input: u, want: "https://example.com/path?q=1"},
+		{name: "list", input: []any{1, "two", false}, want: []any{int64(1), "two", false}},
+		{name: "empty list", input: []any{}, want: map[string]any{}},
+		{
+			name:  "set",
+			input: map[string]struct{}{"x": {}},
+			want:  map[string]any{"x": true},
+		},
+		{
+			name:  "headers",
+			input: map[string][]string{"Accept": {"text/plain", "application/json"}},
+			want:  map[string]any{"Accept": []any{"text/plain", "application/json"}},
+		},
+		{
+			name:  "nested map",
+			input: map[string]any{"outer": map[string]any{"inner": []any{1.5}}},
+			want:  map[string]any{"outer": map[string]any{"inner": []any{1.5}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			L := luaLib.NewState()
+			defer L.Close()
+
+			val, err := ConvertToLuaValue(L, tt.input)
+			require.NoError(t, err)
+
+			got, err := ConvertLuaValueToInterface(val)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		L := luaLib.NewState()
+		defer L.Close()
+
+		_, err := ConvertToLuaValue(L, struct{}{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported type")
+
+		_, err = ConvertToLuaValue(L, []any{make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to convert list element")
+	})
+}
+
+func TestConvertToLuaFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		L := luaLib.NewState()
+		defer L.Close()
+
+		input := map[string]any{
+			"name":   "World",
+			"config": map[string]any{"retries": 3},
+		}
+		ctxTable, err := ConvertToLuaFormat(L, input)
+		require.NoError(t, err)
+		L.SetGlobal("ctx", ctxTable)
+
+		require.NoError(t, L.DoString(`out = ctx.name .. ":" .. ctx["config"].retries`))
+		require.Equal(t, "World:3", L.GetGlobal("out").String())
+	})
+
+	t.Run("conversion errors", func(t *testing.T) {
+		L := luaLib.NewState()
+		defer L.Close()
+
+		_, err := ConvertToLuaFormat(L, map[string]any{"bad": make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `key "bad"`)
+	})
+
+	t.Run("nil state", func(t *testing.T) {
+		_, err := ConvertToLuaFormat(nil, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestConvertLuaValueToInterface(t *testing.T) {
+	t.Parallel()
+
+	L := luaLib.NewState()
+	defer L.Close()
+
+	tests := []struct {
+		name   string
+		script string
+		want   any
+	}{
+		{name: "nil", script: `return nil`, want: nil},
+		{name: "number", script: `return 10 / 4`, want: 2.5},
+		{name: "sequence", script: `return {"a", "b", 3}`, want: []any{"a", "b", int64(3)}},
+		{
+			name:   "sparse table is a map",
+			script: `return {[1] = "a", [3] = "c"}`,
+			want:   map[string]any{"1": "a", "3": "c"},
+		},
+		{
+			name:   "mixed table is a map",
+			script: `return {"a", key = "value"}`,
+			want:   map[string]any{"1": "a", "key": "value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, L.DoString(tt.script))
+			val := L.Get(-1)
+			L.Pop(1)
+
+			got, err := ConvertLuaValueToInterface(val)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("unsupported function", func(t *testing.T) {
+		_, err := ConvertLuaValueToInterface(L.NewFunction(func(*luaLib.LState) int { return 0 }))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported Lua type")
+	})
+}
@@ -0,0 +1,172 @@
+package lua
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/engines/lua/compiler"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLuaScript = `
+-- Simple Lua script that prints a message
+print("Hello from Lua")
+
+local function greet(name)
+	return "Hello, " .. name
+end
+
+return greet(ctx.name or "World")
+`
+
+// Helper function to create a string loader with test script
+func createTestLoader(t *testing.T) *loader.FromString {
+	t.Helper()
+	stringLoader, err := loader.NewFromString(testLuaScript)
+	require.NoError(t, err)
+	require.NotNil(t, stringLoader)
+	return stringLoader
+}
+
+// Helper function to create a mock loader that fails to load the script
+func createFailingLoader(t *testing.T) *loader.MockLoader {
+	t.Helper()
+	mockLoader := new(loader.MockLoader)
+	mockURL, err := url.Parse("file:///test-lua-file.lua")
+	require.NoError(t, err, "Failed to parse URL")
+	mockLoader.On("GetSourceURL").Return(mockURL)
+	mockLoader.On("GetReader").Return(nil, fmt.Errorf("failed to load script"))
+	return mockLoader
+}
+
+func TestFromLuaLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		evalInstance, err := FromLuaLoader(handler, createTestLoader(t))
+		require.NoError(t, err)
+		require.NotNil(t, evalInstance)
+		assert.Equal(t, "lua.Evaluator", evalInstance.String())
+
+		ctx, err := evalInstance.AddDataToContext(t.Context(), map[string]any{"name": "Ada"})
+		require.NoError(t, err)
+		response, err := evalInstance.Eval(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, Ada", response.Interface())
+	})
+
+	t.Run("error from loader", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		mockLoader := createFailingLoader(t)
+
+		evalInstance, err := FromLuaLoader(handler, mockLoader)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.Contains(t, err.Error(), "failed to load script")
+		mockLoader.AssertExpectations(t)
+	})
+}
+
+func TestFromLuaLoaderWithData(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success with static data", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		staticData := map[string]any{"name": "Static"}
+
+		evalInstance, err := FromLuaLoaderWithData(handler, createTestLoader(t), staticData)
+		require.NoError(t, err)
+		require.NotNil(t, evalInstance)
+
+		response, err := evalInstance.Eval(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, Static", response.Interface())
+	})
+
+	t.Run("error from loader", func(t *testing.T) {
+		handler := slog.NewTextHandler(os.Stdout, nil)
+		mockLoader := createFailingLoader(t)
+
+		evalInstance, err := FromLuaLoaderWithData(handler, mockLoader, nil)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.Contains(t, err.Error(), "failed to load script")
+		mockLoader.AssertExpectations(t)
+	})
+}
+
+func TestNewCompiler(t *testing.T) {
+	t.Parallel()
+
+	comp, err := NewCompiler(
+		compiler.WithLogHandler(slog.NewTextHandler(os.Stdout, nil)),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, comp)
+}
+
+func TestNewEvaluator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil provider", func(t *testing.T) {
+		evalInstance, err := NewEvaluator(nil, createTestLoader(t), nil)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		require.Contains(t, err.Error(), "provider is nil")
+	})
+
+	t.Run("invalid script syntax", func(t *testing.T) {
+		invalidLoader, err := loader.NewFromString(`function ( {`)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			invalidLoader,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.Error(t, err)
+		require.Nil(t, evalInstance)
+		assert.ErrorIs(t, err, compiler.ErrValidationFailed)
+	})
+
+	t.Run("from disk loader", func(t *testing.T) {
+		tempFilePath := filepath.Join(t.TempDir(), "test.lua")
+		require.NoError(t, os.WriteFile(tempFilePath, []byte(testLuaScript), 0o644))
+
+		diskLoader, err := loader.NewFromDisk(tempFilePath)
+		require.NoError(t, err)
+
+		evalInstance, err := NewEvaluator(
+			nil,
+			diskLoader,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.NoError(t, err)
+
+		response, err := evalInstance.Eval(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, World", response.Interface())
+	})
+}
+
+func TestRegistration(t *testing.T) {
+	t.Parallel()
+
+	e, ok := registry.Lookup(compiler.MachineType)
+	require.True(t, ok)
+	assert.Equal(t, []string{".lua"}, e.Extensions)
+
+	comp, err := registry.NewCompiler(compiler.WithLogHandler(slog.NewTextHandler(os.Stdout, nil)))
+	require.NoError(t, err)
+	assert.IsType(t, &compiler.Compiler{}, comp)
+}
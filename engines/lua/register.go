@@ -0,0 +1,57 @@
+package lua
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/robbyt/go-polyscript/engines/lua/compiler"
+	"github.com/robbyt/go-polyscript/engines/lua/evaluator"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
+)
+
+func init() {
+	registry.MustRegister(registry.Engine{
+		Type:               compiler.MachineType,
+		Extensions:         []string{".lua"},
+		NewCompiler:        newCompilerFromOptions,
+		NewEvaluator:       newEvaluatorFromUnit,
+		NewBundleEvaluator: newEvaluatorFromBundle,
+	})
+}
+
+// newCompilerFromOptions is the registry.CompilerFactory for Lua. It only accepts
+// compiler.FunctionalOption values.
+func newCompilerFromOptions(opts ...any) (script.Compiler, error) {
+	luaOpts, ok := registry.MatchOptions[compiler.FunctionalOption](opts)
+	if !ok {
+		return nil, registry.ErrOptionsNotSupported
+	}
+
+	c, err := compiler.New(luaOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Lua compiler: %w", err)
+	}
+	return c, nil
+}
+
+// newEvaluatorFromUnit is the registry.EvaluatorFactory for Lua.
+func newEvaluatorFromUnit(
+	handler slog.Handler,
+	unit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) platform.Evaluator {
+	return evaluator.New(handler, unit, opts...)
+}
+
+// newEvaluatorFromBundle is the registry.BundleEvaluatorFactory for Lua.
+func newEvaluatorFromBundle(handler slog.Handler, b *bundle.Bundle) (platform.Evaluator, error) {
+	m := b.Manifest()
+	e, err := FromLuaLoaderWithData(handler, b.Loader(), m.StaticData)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
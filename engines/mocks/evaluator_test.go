@@ -13,3 +13,10 @@ func TestEvaluatorImplementsEvaluatorWithPrep(t *testing.T) {
 	// This is a compile-time check - if it doesn't compile, the test fails
 	var _ platform.Evaluator = (*Evaluator)(nil)
 }
+
+// TestEvaluatorImplementsReloadableEvaluator verifies at compile time
+// that our mock Evaluator implements the ReloadableEvaluator interface.
+func TestEvaluatorImplementsReloadableEvaluator(t *testing.T) {
+	t.Parallel()
+	var _ platform.ReloadableEvaluator = (*Evaluator)(nil)
+}
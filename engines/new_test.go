@@ -1,5 +1,3 @@
-// Code generated by go generate; DO NOT EDIT.
-
 package engines
 
 import (
@@ -60,19 +58,19 @@ func TestNewEvaluator(t *testing.T) {
 	}{
 		{
 			name:          "Risor machine type",
-			machineType:   machineTypes.Risor,
+			machineType:   risorCompiler.MachineType,
 			expectError:   false,
 			expectedError: nil,
 		},
 		{
 			name:          "Starlark machine type",
-			machineType:   machineTypes.Starlark,
+			machineType:   starlarkCompiler.MachineType,
 			expectError:   false,
 			expectedError: nil,
 		},
 		{
 			name:          "Extism machine type",
-			machineType:   machineTypes.Extism,
+			machineType:   extismCompiler.MachineType,
 			expectError:   false,
 			expectedError: nil,
 		},
@@ -163,3 +161,24 @@ func TestNewCompiler(t *testing.T) {
 		})
 	}
 }
+
+func TestBuiltinEngines(t *testing.T) {
+	// Importing this package registers the built-in engines, with their file extensions
+	for _, ext := range []string{".cel", ".expr", ".js", ".lua", ".risor", ".star", ".starlark", ".wasm"} {
+		require.Contains(t, machineTypes.Extensions(), ext)
+	}
+
+	found, err := machineTypes.GetMachineTypeFromPath("/scripts/main.star")
+	require.NoError(t, err)
+	require.Equal(t, starlarkCompiler.MachineType, found)
+	require.True(t, machineTypes.IsRegistered(extismCompiler.MachineType))
+}
+
+func TestDeprecatedMachineTypes(t *testing.T) {
+	//nolint:staticcheck // the deprecated constants must keep matching the engines' types
+	require.Equal(t, risorCompiler.MachineType, machineTypes.Risor)
+	//nolint:staticcheck // the deprecated constants must keep matching the engines' types
+	require.Equal(t, starlarkCompiler.MachineType, machineTypes.Starlark)
+	//nolint:staticcheck // the deprecated constants must keep matching the engines' types
+	require.Equal(t, extismCompiler.MachineType, machineTypes.Extism)
+}
@@ -0,0 +1,10 @@
+package registry
+
+import "errors"
+
+var (
+	ErrAlreadyRegistered   = errors.New("engine already registered")
+	ErrBundlesNotSupported = errors.New("bundles not supported by engine")
+	ErrInvalidEngine       = errors.New("invalid engine registration")
+	ErrOptionsNotSupported = errors.New("options not supported by engine")
+)
@@ -0,0 +1,199 @@
+// Package registry holds the set of script engines available to go-polyscript.
+//
+// Each engine package registers itself from an init function, providing its machine type,
+// the file extensions used by its scripts, and factories for its compiler and evaluator.
+// Engines maintained outside of this module can register the same way, and become usable
+// through engines.NewEvaluator and engines.NewCompiler once their package is imported.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
+)
+
+// CompilerFactory builds a compiler from a list of engine-specific options. When the options
+// do not belong to this engine, the factory must return ErrOptionsNotSupported so the next
+// engine can be tried.
+type CompilerFactory func(opts ...any) (script.Compiler, error)
+
+// EvaluatorFactory builds an evaluator for an executable unit compiled by the same engine.
+type EvaluatorFactory func(
+	handler slog.Handler,
+	unit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) platform.Evaluator
+
+// BundleEvaluatorFactory builds an evaluator for the entry point of a script bundle, with the
+// manifest's static data. Engines that can import other scripts should resolve them from the
+// bundle's file system.
+type BundleEvaluatorFactory func(handler slog.Handler, b *bundle.Bundle) (platform.Evaluator, error)
+
+// Engine describes a script engine implementation.
+type Engine struct {
+	// Type is the machine type returned by the engine's ExecutableContent.
+	Type machineTypes.Type
+
+	// Extensions are the script file extensions handled by this engine, e.g. ".risor".
+	Extensions []string
+
+	// NewCompiler creates the engine's script.Compiler.
+	NewCompiler CompilerFactory
+
+	// NewEvaluator creates the engine's platform.Evaluator.
+	NewEvaluator EvaluatorFactory
+
+	// NewBundleEvaluator creates a platform.Evaluator for a script bundle. It is optional, and
+	// bundles for an engine without it can't be evaluated.
+	NewBundleEvaluator BundleEvaluatorFactory
+}
+
+var (
+	mu      sync.RWMutex
+	engines = make(map[machineTypes.Type]Engine)
+)
+
+// Register adds an engine to the registry, and records its machine type and file extensions
+// with the types package. Registering the same machine type twice returns an error.
+func Register(e Engine) error {
+	if e.Type == "" {
+		return fmt.Errorf("%w: machine type is empty", ErrInvalidEngine)
+	}
+	if e.NewCompiler == nil {
+		return fmt.Errorf("%w: compiler factory is nil for %s", ErrInvalidEngine, e.Type)
+	}
+	if e.NewEvaluator == nil {
+		return fmt.Errorf("%w: evaluator factory is nil for %s", ErrInvalidEngine, e.Type)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := engines[e.Type]; exists {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, e.Type)
+	}
+
+	if err := machineTypes.Register(e.Type, e.Extensions...); err != nil {
+		return fmt.Errorf("failed to register machine type: %w", err)
+	}
+
+	e.Extensions = slices.Clone(e.Extensions)
+	engines[e.Type] = e
+	return nil
+}
+
+// MustRegister is like Register, but panics on error. It is intended for use in init functions.
+func MustRegister(e Engine) {
+	if err := Register(e); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the registered engine for a machine type.
+func Lookup(t machineTypes.Type) (Engine, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := engines[t]
+	return e, ok
+}
+
+// Types returns the machine types of all registered engines, sorted by name.
+func Types() []machineTypes.Type {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	out := make([]machineTypes.Type, 0, len(engines))
+	for t := range engines {
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// NewEvaluator creates an evaluator for the executable unit, using the engine registered for
+// the unit's machine type.
+func NewEvaluator(
+	handler slog.Handler,
+	unit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) (platform.Evaluator, error) {
+	if unit == nil {
+		return nil, fmt.Errorf("version is nil")
+	}
+
+	e, ok := Lookup(unit.GetMachineType())
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", machineTypes.ErrInvalidMachineType, unit.GetMachineType())
+	}
+	return e.NewEvaluator(handler, unit, opts...), nil
+}
+
+// NewBundleEvaluator creates an evaluator for a script bundle, using the engine named by the
+// bundle's manifest. The evaluator doesn't check the input data against the bundle's schema;
+// use bundle.Bundle.WrapEvaluator for that.
+func NewBundleEvaluator(handler slog.Handler, b *bundle.Bundle) (platform.Evaluator, error) {
+	if b == nil {
+		return nil, fmt.Errorf("bundle is nil")
+	}
+
+	engineType := b.Manifest().Engine
+	e, ok := Lookup(engineType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", machineTypes.ErrInvalidMachineType, engineType)
+	}
+	if e.NewBundleEvaluator == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBundlesNotSupported, engineType)
+	}
+	return e.NewBundleEvaluator(handler, b)
+}
+
+// NewCompiler offers the options to each registered engine in turn, and returns the compiler
+// from the first engine that accepts all of them.
+func NewCompiler(opts ...any) (script.Compiler, error) {
+	if len(opts) == 0 {
+		return nil, fmt.Errorf("no options provided")
+	}
+
+	for _, t := range Types() {
+		e, ok := Lookup(t)
+		if !ok {
+			continue
+		}
+
+		c, err := e.NewCompiler(opts...)
+		if errors.Is(err, ErrOptionsNotSupported) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("unable to determine compiler type from provided options")
+}
+
+// MatchOptions asserts every option into T. It returns false when the list is empty, or when
+// any option has a different type. Engine compiler factories use this to claim their options.
+func MatchOptions[T any](opts []any) ([]T, bool) {
+	if len(opts) == 0 {
+		return nil, false
+	}
+
+	out := make([]T, 0, len(opts))
+	for _, opt := range opts {
+		o, ok := opt.(T)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, o)
+	}
+	return out, true
+}
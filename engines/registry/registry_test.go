@@ -0,0 +1,249 @@
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/engines/mocks"
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
+	"github.com/stretchr/testify/require"
+)
+
+// testOption is an option type only understood by the test engine.
+type testOption func() error
+
+// newTestEngine returns an engine registration that accepts testOption values.
+func newTestEngine(t machineTypes.Type, exts ...string) Engine {
+	return Engine{
+		Type:       t,
+		Extensions: exts,
+		NewCompiler: func(opts ...any) (script.Compiler, error) {
+			testOpts, ok := MatchOptions[testOption](opts)
+			if !ok {
+				return nil, ErrOptionsNotSupported
+			}
+			for _, o := range testOpts {
+				if err := o(); err != nil {
+					return nil, err
+				}
+			}
+			return new(script.MockCompiler), nil
+		},
+		NewEvaluator: func(
+			handler slog.Handler,
+			unit *script.ExecutableUnit,
+			opts ...platform.EvalOption,
+		) platform.Evaluator {
+			return new(mocks.Evaluator)
+		},
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		engineType := machineTypes.Type("registry-test-success")
+		require.NoError(t, Register(newTestEngine(engineType, ".rts")))
+
+		e, ok := Lookup(engineType)
+		require.True(t, ok)
+		require.Equal(t, engineType, e.Type)
+		require.Contains(t, Types(), engineType)
+
+		// the machine type and extension are now known to the types package
+		require.True(t, machineTypes.IsRegistered(engineType))
+		found, err := machineTypes.GetMachineTypeFromPath("/tmp/script.rts")
+		require.NoError(t, err)
+		require.Equal(t, engineType, found)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		engineType := machineTypes.Type("registry-test-duplicate")
+		require.NoError(t, Register(newTestEngine(engineType)))
+		err := Register(newTestEngine(engineType))
+		require.ErrorIs(t, err, ErrAlreadyRegistered)
+	})
+
+	t.Run("extension conflict", func(t *testing.T) {
+		require.NoError(t, Register(newTestEngine("registry-test-conflict-owner", ".rtc")))
+		engineType := machineTypes.Type("registry-test-conflict")
+		err := Register(newTestEngine(engineType, ".rtc"))
+		require.ErrorIs(t, err, machineTypes.ErrExtensionConflict)
+
+		_, ok := Lookup(engineType)
+		require.False(t, ok)
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		e := newTestEngine("")
+		require.ErrorIs(t, Register(e), ErrInvalidEngine)
+
+		e = newTestEngine("registry-test-nil-compiler")
+		e.NewCompiler = nil
+		require.ErrorIs(t, Register(e), ErrInvalidEngine)
+
+		e = newTestEngine("registry-test-nil-evaluator")
+		e.NewEvaluator = nil
+		require.ErrorIs(t, Register(e), ErrInvalidEngine)
+	})
+
+	t.Run("must register panics", func(t *testing.T) {
+		require.Panics(t, func() { MustRegister(newTestEngine("")) })
+	})
+}
+
+func TestNewEvaluator(t *testing.T) {
+	engineType := machineTypes.Type("registry-test-evaluator")
+	require.NoError(t, Register(newTestEngine(engineType)))
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	t.Run("registered type", func(t *testing.T) {
+		content := new(script.MockExecutableContent)
+		content.On("GetMachineType").Return(engineType)
+
+		evaluator, err := NewEvaluator(handler, &script.ExecutableUnit{Content: content})
+		require.NoError(t, err)
+		require.NotNil(t, evaluator)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		content := new(script.MockExecutableContent)
+		content.On("GetMachineType").Return(machineTypes.Type("registry-test-unknown"))
+
+		evaluator, err := NewEvaluator(handler, &script.ExecutableUnit{Content: content})
+		require.ErrorIs(t, err, machineTypes.ErrInvalidMachineType)
+		require.Nil(t, evaluator)
+	})
+
+	t.Run("nil unit", func(t *testing.T) {
+		evaluator, err := NewEvaluator(handler, nil)
+		require.Error(t, err)
+		require.Nil(t, evaluator)
+	})
+}
+
+// newTestBundle returns a bundle for the machine type, with an empty entry point script
+func newTestBundle(t *testing.T, engineType machineTypes.Type) *bundle.Bundle {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		bundle.ManifestFile: `{"engine": "` + engineType.String() + `", "entryPoint": "main.rts"}`,
+		"main.rts":          "",
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	b, err := bundle.FromBytes(buf.Bytes())
+	require.NoError(t, err)
+	return b
+}
+
+func TestNewBundleEvaluator(t *testing.T) {
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	t.Run("engine with a bundle factory", func(t *testing.T) {
+		engineType := machineTypes.Type("registry-test-bundle")
+		e := newTestEngine(engineType)
+		var received *bundle.Bundle
+		e.NewBundleEvaluator = func(_ slog.Handler, b *bundle.Bundle) (platform.Evaluator, error) {
+			received = b
+			return new(mocks.Evaluator), nil
+		}
+		require.NoError(t, Register(e))
+
+		b := newTestBundle(t, engineType)
+		evaluator, err := NewBundleEvaluator(handler, b)
+		require.NoError(t, err)
+		require.NotNil(t, evaluator)
+		require.Same(t, b, received)
+	})
+
+	t.Run("engine without a bundle factory", func(t *testing.T) {
+		engineType := machineTypes.Type("registry-test-no-bundle")
+		require.NoError(t, Register(newTestEngine(engineType)))
+
+		evaluator, err := NewBundleEvaluator(handler, newTestBundle(t, engineType))
+		require.ErrorIs(t, err, ErrBundlesNotSupported)
+		require.Nil(t, evaluator)
+	})
+
+	t.Run("machine type without an engine", func(t *testing.T) {
+		engineType := machineTypes.Type("registry-test-bundle-type-only")
+		require.NoError(t, machineTypes.Register(engineType))
+
+		evaluator, err := NewBundleEvaluator(handler, newTestBundle(t, engineType))
+		require.ErrorIs(t, err, machineTypes.ErrInvalidMachineType)
+		require.Nil(t, evaluator)
+	})
+
+	t.Run("nil bundle", func(t *testing.T) {
+		evaluator, err := NewBundleEvaluator(handler, nil)
+		require.Error(t, err)
+		require.Nil(t, evaluator)
+	})
+}
+
+func TestNewCompiler(t *testing.T) {
+	require.NoError(t, Register(newTestEngine("registry-test-compiler")))
+
+	t.Run("matching options", func(t *testing.T) {
+		var opt testOption = func() error { return nil }
+		comp, err := NewCompiler(opt, opt)
+		require.NoError(t, err)
+		require.NotNil(t, comp)
+	})
+
+	t.Run("factory error is returned", func(t *testing.T) {
+		var opt testOption = func() error { return errors.New("bad option") }
+		comp, err := NewCompiler(opt)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad option")
+		require.Nil(t, comp)
+	})
+
+	t.Run("no options", func(t *testing.T) {
+		comp, err := NewCompiler()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no options provided")
+		require.Nil(t, comp)
+	})
+
+	t.Run("unknown options", func(t *testing.T) {
+		comp, err := NewCompiler("not an option")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to determine compiler type")
+		require.Nil(t, comp)
+	})
+}
+
+func TestMatchOptions(t *testing.T) {
+	t.Run("all match", func(t *testing.T) {
+		out, ok := MatchOptions[string]([]any{"a", "b"})
+		require.True(t, ok)
+		require.Equal(t, []string{"a", "b"}, out)
+	})
+
+	t.Run("mixed types", func(t *testing.T) {
+		out, ok := MatchOptions[string]([]any{"a", 1})
+		require.False(t, ok)
+		require.Nil(t, out)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		out, ok := MatchOptions[string](nil)
+		require.False(t, ok)
+		require.Nil(t, out)
+	})
+}
@@ -0,0 +1,9 @@
+package reload
+
+import "errors"
+
+var (
+	ErrCompileFailed = errors.New("failed to compile new version")
+	ErrUnitNil       = errors.New("executable unit is nil")
+	ErrContentNil    = errors.New("executable unit content is nil")
+)
@@ -0,0 +1,294 @@
+// Package reload provides an evaluator that can swap in a new version of its script while in use.
+//
+// The Evaluator wraps the evaluator of any registered engine. Reload recompiles the script from
+// its loader, and atomically replaces the active version once compilation succeeds. Calls to
+// Eval that are already running keep using the version they started with, and a script that
+// fails to compile leaves the previous version active. A replaced version is closed once its
+// last call returns, when its executable content holds resources, such as an Extism module.
+package reload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/robbyt/go-polyscript/engines/registry"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+)
+
+// closer is implemented by executable content that holds resources, such as the compiled
+// plugin and instance pool of an Extism module
+type closer interface {
+	Close(ctx context.Context) error
+}
+
+// version is an executable unit, and the engine evaluator created for it
+type version struct {
+	unit      *script.ExecutableUnit
+	evaluator platform.Evaluator
+
+	// mu guards the count of running calls, and whether the version has been replaced
+	mu      sync.Mutex
+	calls   int
+	retired bool
+}
+
+// acquire counts a call to the version, and returns false when it has already been replaced
+func (v *version) acquire() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.retired {
+		return false
+	}
+	v.calls++
+	return true
+}
+
+// release ends a call, and returns true when the version was replaced and this was its last call
+func (v *version) release() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.calls--
+	return v.retired && v.calls == 0
+}
+
+// retire marks the version as replaced, and returns true when no calls are running
+func (v *version) retire() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.retired = true
+	return v.calls == 0
+}
+
+// Evaluator is a platform.ReloadableEvaluator for every registered engine
+type Evaluator struct {
+	// active is the version used by new calls to Eval
+	active atomic.Pointer[version]
+
+	// reloadMu serializes calls to Reload and Load
+	reloadMu sync.Mutex
+
+	// evalOpts are passed to the engine evaluator of every version
+	evalOpts []platform.EvalOption
+
+	logHandler slog.Handler
+	logger     *slog.Logger
+}
+
+// New creates a reloadable evaluator, with the executable unit as the first active version.
+// Later versions are compiled with the unit's compiler, and use the same data provider. The
+// options are applied to the evaluator of every version.
+func New(
+	handler slog.Handler,
+	unit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
+) (*Evaluator, error) {
+	handler, logger := helpers.SetupLogger(handler, "reload", "Evaluator")
+
+	e := &Evaluator{
+		evalOpts:   opts,
+		logHandler: handler,
+		logger:     logger,
+	}
+
+	if err := e.swap(unit); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// FromLoader compiles the script from the loader, and creates a reloadable evaluator for it.
+// The compiler selects the engine, e.g. one created by the engine package's NewCompiler.
+func FromLoader(
+	handler slog.Handler,
+	ldr loader.Loader,
+	compiler script.Compiler,
+	dataProvider data.Provider,
+	opts ...platform.EvalOption,
+) (*Evaluator, error) {
+	if dataProvider == nil {
+		return nil, fmt.Errorf("provider is nil")
+	}
+
+	unit, err := script.NewExecutableUnit(handler, "", ldr, compiler, dataProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(handler, unit, opts...)
+}
+
+func (e *Evaluator) String() string {
+	return "reload.Evaluator"
+}
+
+// Eval evaluates the active version of the script
+func (e *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	v := e.acquire()
+	defer e.release(v)
+	return v.evaluator.Eval(ctx)
+}
+
+// AddDataToContext adds data to the context using the active version's evaluator. Every
+// version shares the same data provider, so the data is also available after a reload.
+func (e *Evaluator) AddDataToContext(
+	ctx context.Context,
+	d ...map[string]any,
+) (context.Context, error) {
+	v := e.acquire()
+	defer e.release(v)
+	return v.evaluator.AddDataToContext(ctx, d...)
+}
+
+// acquire returns the active version, counting the call so the version isn't closed while it's
+// in use
+func (e *Evaluator) acquire() *version {
+	for {
+		v := e.active.Load()
+		if v.acquire() {
+			return v
+		}
+		// The version was replaced after it was loaded, so the next one is already active
+	}
+}
+
+// release ends a call to the version, and closes the version when it was replaced and this was
+// its last call
+func (e *Evaluator) release(v *version) {
+	if v.release() {
+		e.closeUnit(v.unit)
+	}
+}
+
+// closeUnit closes the unit's executable content, when it holds resources
+func (e *Evaluator) closeUnit(unit *script.ExecutableUnit) {
+	c, ok := unit.GetContent().(closer)
+	if !ok {
+		return
+	}
+	// Load can activate a unit with the same content as the version it replaces
+	if active := e.active.Load(); active != nil && active.unit.GetContent() == unit.GetContent() {
+		return
+	}
+	if err := c.Close(context.Background()); err != nil {
+		e.logger.Warn("Failed to close replaced version", "ID", unit.GetID(), "error", err)
+		return
+	}
+	e.logger.Debug("Closed replaced version", "ID", unit.GetID())
+}
+
+// Current returns the executable unit of the active version
+func (e *Evaluator) Current() *script.ExecutableUnit {
+	return e.active.Load().unit
+}
+
+// Reload recompiles the script and makes it the active version. When path is empty the script
+// is loaded again from the active version's loader. Otherwise the path can be any input
+// accepted by loader.InferLoader, such as a file path or URL, and the new loader is used for
+// later reloads. A new loader keeps the rules of a wrapping loader, such as a loader.Verifying,
+// so a script from the new path must still be approved. Versions are identified by a checksum
+// of their source, so reloading an unchanged script keeps the active version.
+func (e *Evaluator) Reload(path string) error {
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+
+	logger := e.logger.WithGroup("Reload")
+	current := e.active.Load().unit
+
+	ldr := current.GetLoader()
+	if path != "" {
+		var err error
+		ldr, err = newLoader(path, ldr)
+		if err != nil {
+			return err
+		}
+	}
+
+	unit, err := script.NewExecutableUnit(
+		e.logHandler,
+		"",
+		ldr,
+		current.GetCompiler(),
+		current.GetDataProvider(),
+	)
+	if err != nil {
+		logger.Error("Reload failed, keeping the active version", "ID", current.GetID(), "error", err)
+		return fmt.Errorf("%w: %w", ErrCompileFailed, err)
+	}
+
+	if unit.GetID() == current.GetID() && path == "" {
+		logger.Debug("Script is unchanged", "ID", current.GetID())
+		e.closeUnit(unit)
+		return nil
+	}
+
+	if err := e.swapLocked(unit); err != nil {
+		e.closeUnit(unit)
+		return err
+	}
+	return nil
+}
+
+// newLoader creates the loader for a new path. When the current loader wraps another loader,
+// such as a loader.Verifying, the new loader is wrapped the same way, so its rules still apply.
+func newLoader(path string, current loader.Loader) (loader.Loader, error) {
+	ldr, err := loader.InferLoader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create loader: %w", err)
+	}
+	wrapper, ok := current.(loader.Wrapper)
+	if !ok {
+		return ldr, nil
+	}
+	ldr, err = wrapper.Wrap(ldr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap loader: %w", err)
+	}
+	return ldr, nil
+}
+
+// Load makes an already compiled executable unit the active version. The unit's machine type
+// selects the engine, so it can differ from the active version's.
+func (e *Evaluator) Load(newVersion script.ExecutableUnit) error {
+	return e.swap(&newVersion)
+}
+
+// swap replaces the active version with the executable unit
+func (e *Evaluator) swap(unit *script.ExecutableUnit) error {
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+	return e.swapLocked(unit)
+}
+
+// swapLocked replaces the active version, and must be called with reloadMu held
+func (e *Evaluator) swapLocked(unit *script.ExecutableUnit) error {
+	if unit == nil {
+		return ErrUnitNil
+	}
+	if unit.GetContent() == nil {
+		return ErrContentNil
+	}
+
+	evaluator, err := registry.NewEvaluator(e.logHandler, unit, e.evalOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create evaluator: %w", err)
+	}
+
+	previous := e.active.Swap(&version{unit: unit, evaluator: evaluator})
+	if previous == nil {
+		return nil
+	}
+	e.logger.Info("Activated new version", "ID", unit.GetID(), "previousID", previous.unit.GetID())
+
+	// Running calls keep the previous version open, and the last one to return closes it
+	if previous.retire() {
+		e.closeUnit(previous.unit)
+	}
+	return nil
+}
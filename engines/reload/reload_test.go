@@ -0,0 +1,464 @@
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robbyt/go-polyscript/engines/cel"
+	"github.com/robbyt/go-polyscript/engines/lua"
+	"github.com/robbyt/go-polyscript/engines/mocks"
+	"github.com/robbyt/go-polyscript/engines/registry"
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ platform.ReloadableEvaluator = (*Evaluator)(nil)
+
+// writeScript writes the script content to a file in dir, and returns its path
+func writeScript(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// newTestEvaluator creates a reloadable CEL evaluator for a script on disk
+func newTestEvaluator(t *testing.T, path string) *Evaluator {
+	t.Helper()
+	handler := slog.NewTextHandler(os.Stdout, nil)
+
+	ldr, err := loader.NewFromDisk(path)
+	require.NoError(t, err)
+
+	comp, err := cel.NewCompiler()
+	require.NoError(t, err)
+
+	e, err := FromLoader(handler, ldr, comp, data.NewStaticProvider(map[string]any{"x": 1}))
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	return e
+}
+
+// requireResult evaluates the script, and checks the result
+func requireResult(t *testing.T, e *Evaluator, want any) {
+	t.Helper()
+	response, err := e.Eval(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, want, response.Interface())
+}
+
+func TestEvaluator_Reload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("picks up changes from the loader", func(t *testing.T) {
+		path := writeScript(t, t.TempDir(), "rule.cel", `ctx.x + 1`)
+		e := newTestEvaluator(t, path)
+		requireResult(t, e, int64(2))
+		firstID := e.Current().GetID()
+
+		writeScript(t, filepath.Dir(path), "rule.cel", `ctx.x + 10`)
+		require.NoError(t, e.Reload(""))
+		requireResult(t, e, int64(11))
+		assert.NotEqual(t, firstID, e.Current().GetID())
+	})
+
+	t.Run("unchanged script keeps the active version", func(t *testing.T) {
+		path := writeScript(t, t.TempDir(), "rule.cel", `ctx.x + 1`)
+		e := newTestEvaluator(t, path)
+		active := e.Current()
+
+		require.NoError(t, e.Reload(""))
+		assert.Same(t, active, e.Current())
+	})
+
+	t.Run("compile failure keeps the previous version", func(t *testing.T) {
+		path := writeScript(t, t.TempDir(), "rule.cel", `ctx.x + 1`)
+		e := newTestEvaluator(t, path)
+		active := e.Current()
+
+		writeScript(t, filepath.Dir(path), "rule.cel", `ctx.x +`)
+		err := e.Reload("")
+		require.ErrorIs(t, err, ErrCompileFailed)
+		assert.Same(t, active, e.Current())
+		requireResult(t, e, int64(2))
+	})
+
+	t.Run("new path", func(t *testing.T) {
+		dir := t.TempDir()
+		e := newTestEvaluator(t, writeScript(t, dir, "v1.cel", `ctx.x + 1`))
+
+		v2 := writeScript(t, dir, "v2.cel", `ctx.x + 2`)
+		require.NoError(t, e.Reload(v2))
+		requireResult(t, e, int64(3))
+		assert.Equal(t, "file://"+v2, e.Current().GetLoader().GetSourceURL().String())
+
+		// later reloads use the new loader
+		writeScript(t, dir, "v2.cel", `ctx.x + 20`)
+		require.NoError(t, e.Reload(""))
+		requireResult(t, e, int64(21))
+	})
+
+	t.Run("new path keeps verification", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeScript(t, dir, "v1.cel", `ctx.x + 1`)
+		sum := sha256.Sum256([]byte(`ctx.x + 1`))
+		diskLoader, err := loader.NewFromDisk(path)
+		require.NoError(t, err)
+		pinned, err := loader.NewPinned(diskLoader, hex.EncodeToString(sum[:]))
+		require.NoError(t, err)
+		comp, err := cel.NewCompiler()
+		require.NoError(t, err)
+		e, err := FromLoader(nil, pinned, comp, data.NewStaticProvider(map[string]any{"x": 1}))
+		require.NoError(t, err)
+
+		unapproved := writeScript(t, dir, "v2.cel", `ctx.x + 2`)
+		err = e.Reload(unapproved)
+		require.ErrorIs(t, err, ErrCompileFailed)
+		require.ErrorIs(t, err, loader.ErrVerificationFailed)
+		requireResult(t, e, int64(2))
+
+		approved := writeScript(t, dir, "copy.cel", `ctx.x + 1`)
+		require.NoError(t, e.Reload(approved))
+		assert.IsType(t, &loader.Verifying{}, e.Current().GetLoader())
+		requireResult(t, e, int64(2))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		path := writeScript(t, t.TempDir(), "rule.cel", `ctx.x + 1`)
+		e := newTestEvaluator(t, path)
+
+		require.NoError(t, os.Remove(path))
+		require.ErrorIs(t, e.Reload(""), ErrCompileFailed)
+		requireResult(t, e, int64(2))
+	})
+}
+
+func TestEvaluator_ReloadDuringEval(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeScript(t, dir, "rule.cel", `ctx.x > 0`)
+	e := newTestEvaluator(t, path)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				response, err := e.Eval(t.Context())
+				assert.NoError(t, err)
+				if response != nil {
+					assert.Equal(t, data.BOOL, response.Type())
+				}
+			}
+		}()
+	}
+
+	for i := range 20 {
+		if i%2 == 0 {
+			writeScript(t, dir, "rule.cel", `ctx.x < 0`)
+		} else {
+			writeScript(t, dir, "rule.cel", `ctx.x > 0`)
+		}
+		assert.NoError(t, e.Reload(""))
+	}
+	wg.Wait()
+}
+
+func TestEvaluator_Load(t *testing.T) {
+	t.Parallel()
+
+	path := writeScript(t, t.TempDir(), "rule.cel", `ctx.x + 1`)
+	e := newTestEvaluator(t, path)
+
+	t.Run("different engine", func(t *testing.T) {
+		ldr, err := loader.NewFromString(`return ctx["x"] * 100`)
+		require.NoError(t, err)
+		comp, err := lua.NewCompiler()
+		require.NoError(t, err)
+		unit, err := script.NewExecutableUnit(nil, "lua-version", ldr, comp, e.Current().GetDataProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, e.Load(*unit))
+		assert.Equal(t, "lua-version", e.Current().GetID())
+		requireResult(t, e, int64(100))
+	})
+
+	t.Run("missing content", func(t *testing.T) {
+		active := e.Current()
+		require.ErrorIs(t, e.Load(script.ExecutableUnit{ID: "empty"}), ErrContentNil)
+		assert.Same(t, active, e.Current())
+	})
+}
+
+func TestEvaluator_AddDataToContext(t *testing.T) {
+	t.Parallel()
+
+	path := writeScript(t, t.TempDir(), "rule.cel", `"Hello, " + ctx.name`)
+	ldr, err := loader.NewFromDisk(path)
+	require.NoError(t, err)
+	comp, err := cel.NewCompiler()
+	require.NoError(t, err)
+
+	e, err := FromLoader(nil, ldr, comp, data.NewContextProvider(constants.EvalData))
+	require.NoError(t, err)
+	assert.Equal(t, "reload.Evaluator", e.String())
+
+	ctx, err := e.AddDataToContext(t.Context(), map[string]any{"name": "World"})
+	require.NoError(t, err)
+
+	writeScript(t, filepath.Dir(path), "rule.cel", `"Goodbye, " + ctx.name`)
+	require.NoError(t, e.Reload(""))
+
+	// data added before the reload is used by the new version
+	response, err := e.Eval(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Goodbye, World", response.Interface())
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil unit", func(t *testing.T) {
+		e, err := New(nil, nil)
+		require.ErrorIs(t, err, ErrUnitNil)
+		require.Nil(t, e)
+	})
+
+	t.Run("nil provider", func(t *testing.T) {
+		ldr, err := loader.NewFromString(`1`)
+		require.NoError(t, err)
+		comp, err := cel.NewCompiler()
+		require.NoError(t, err)
+
+		e, err := FromLoader(nil, ldr, comp, nil)
+		require.Error(t, err)
+		require.Nil(t, e)
+	})
+}
+
+func TestEvaluator_EvalOptions(t *testing.T) {
+	t.Parallel()
+
+	ldr, err := loader.NewFromString(`return 1`)
+	require.NoError(t, err)
+	comp, err := lua.NewCompiler()
+	require.NoError(t, err)
+
+	e, err := FromLoader(
+		nil,
+		ldr,
+		comp,
+		data.NewStaticProvider(nil),
+		platform.WithDefaultTimeout(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+	requireResult(t, e, int64(1))
+
+	// the default timeout also applies to versions loaded later
+	ldr, err = loader.NewFromString(`while true do end`)
+	require.NoError(t, err)
+	unit, err := script.NewExecutableUnit(nil, "", ldr, comp, e.Current().GetDataProvider())
+	require.NoError(t, err)
+	require.NoError(t, e.Load(*unit))
+
+	_, err = e.Eval(t.Context())
+	require.ErrorIs(t, err, platform.ErrTimeout)
+}
+
+// closingType is the machine type of closingContent, registered by the tests
+const closingType machineTypes.Type = "reload-test-closing"
+
+func init() {
+	registry.MustRegister(registry.Engine{
+		Type: closingType,
+		NewCompiler: func(opts ...any) (script.Compiler, error) {
+			return nil, registry.ErrOptionsNotSupported
+		},
+		NewEvaluator: func(
+			_ slog.Handler,
+			unit *script.ExecutableUnit,
+			_ ...platform.EvalOption,
+		) platform.Evaluator {
+			return &closingEvaluator{content: unit.GetContent().(*closingContent)}
+		},
+	})
+}
+
+// closingContent is executable content that records when it's closed, like an Extism module
+type closingContent struct {
+	source  string
+	closed  atomic.Bool
+	running *sync.WaitGroup
+	release chan struct{}
+}
+
+func (c *closingContent) GetSource() string                 { return c.source }
+func (c *closingContent) GetByteCode() any                  { return nil }
+func (c *closingContent) GetMachineType() machineTypes.Type { return closingType }
+
+func (c *closingContent) Close(ctx context.Context) error {
+	c.closed.Store(true)
+	return nil
+}
+
+// closingCompiler compiles scripts into closingContent, and keeps every content it created
+type closingCompiler struct {
+	mu       sync.Mutex
+	contents []*closingContent
+	release  chan struct{}
+	running  sync.WaitGroup
+}
+
+func (c *closingCompiler) Compile(reader io.ReadCloser) (script.ExecutableContent, error) {
+	source, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := reader.Close(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content := &closingContent{source: string(source), running: &c.running, release: c.release}
+	c.contents = append(c.contents, content)
+	return content, nil
+}
+
+func (c *closingCompiler) compiled() []*closingContent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*closingContent(nil), c.contents...)
+}
+
+// closingEvaluator fails the test when it's used after its content is closed. When the content
+// has a release channel, Eval waits for it to be closed.
+type closingEvaluator struct {
+	mocks.Evaluator
+	content *closingContent
+}
+
+func (e *closingEvaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	if e.content.release != nil {
+		e.content.running.Done()
+		<-e.content.release
+	}
+	if e.content.closed.Load() {
+		return nil, io.ErrClosedPipe
+	}
+	return new(mocks.EvaluatorResponse), nil
+}
+
+// newClosingEvaluator creates a reloadable evaluator for a script on disk, compiled by comp
+func newClosingEvaluator(t *testing.T, path string, comp *closingCompiler) *Evaluator {
+	t.Helper()
+	ldr, err := loader.NewFromDisk(path)
+	require.NoError(t, err)
+
+	e, err := FromLoader(nil, ldr, comp, data.NewStaticProvider(nil))
+	require.NoError(t, err)
+	return e
+}
+
+func TestEvaluator_ClosesReplacedVersions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("repeated reloads close every replaced version", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := writeScript(t, dir, "rule.test", "version 0")
+		comp := &closingCompiler{}
+		e := newClosingEvaluator(t, path, comp)
+
+		for i := 1; i <= 10; i++ {
+			writeScript(t, dir, "rule.test", fmt.Sprintf("version %d", i))
+			require.NoError(t, e.Reload(""))
+			_, err := e.Eval(t.Context())
+			require.NoError(t, err)
+		}
+
+		contents := comp.compiled()
+		require.Len(t, contents, 11)
+		for _, content := range contents[:10] {
+			assert.True(t, content.closed.Load())
+		}
+		assert.False(t, contents[10].closed.Load(), "the active version stays open")
+	})
+
+	t.Run("unchanged script closes the new compilation", func(t *testing.T) {
+		t.Parallel()
+		path := writeScript(t, t.TempDir(), "rule.test", "unchanged")
+		comp := &closingCompiler{}
+		e := newClosingEvaluator(t, path, comp)
+
+		require.NoError(t, e.Reload(""))
+		contents := comp.compiled()
+		require.Len(t, contents, 2)
+		assert.False(t, contents[0].closed.Load())
+		assert.True(t, contents[1].closed.Load())
+	})
+
+	t.Run("running calls keep the replaced version open", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := writeScript(t, dir, "rule.test", "first")
+		comp := &closingCompiler{release: make(chan struct{})}
+		e := newClosingEvaluator(t, path, comp)
+
+		const calls = 3
+		comp.running.Add(calls)
+		errs := make(chan error, calls)
+		for range calls {
+			go func() {
+				_, err := e.Eval(context.Background())
+				errs <- err
+			}()
+		}
+		comp.running.Wait()
+
+		// the new version doesn't block, so calls to it aren't counted by running
+		comp.mu.Lock()
+		comp.release = nil
+		comp.mu.Unlock()
+		writeScript(t, dir, "rule.test", "second")
+		require.NoError(t, e.Reload(""))
+		first := comp.compiled()[0]
+		assert.False(t, first.closed.Load(), "closed while calls were running")
+
+		close(first.release)
+		for range calls {
+			require.NoError(t, <-errs)
+		}
+		assert.True(t, first.closed.Load())
+	})
+
+	t.Run("loading the active content doesn't close it", func(t *testing.T) {
+		t.Parallel()
+		path := writeScript(t, t.TempDir(), "rule.test", "active")
+		comp := &closingCompiler{}
+		e := newClosingEvaluator(t, path, comp)
+
+		require.NoError(t, e.Load(*e.Current()))
+		_, err := e.Eval(t.Context())
+		require.NoError(t, err)
+		assert.False(t, comp.compiled()[0].closed.Load())
+	})
+}
@@ -3,17 +3,31 @@ package compiler
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/robbyt/go-polyscript/engines/risor/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/engines/risor/internal"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/script"
 )
 
 type Compiler struct {
 	globals    []string
+	limits     platform.Limits
 	logHandler slog.Handler
 	logger     *slog.Logger
+
+	// hostFunctions are added to the globals, and made available when the script runs
+	hostFunctions []*platform.HostFunction
+
+	// importFS is the root scripts import modules from, or nil when imports are disabled
+	importFS fs.FS
+
+	// evalOptions configure the evaluators of the compiled script
+	evalOptions []platform.EvalOption
 }
 
 // New creates a new Risor-specific Compiler instance with the provided options.
@@ -96,7 +110,7 @@ func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
 
 	logger.Debug("Starting Risor compilation", "scriptLength", len(trimmedScript))
 
-	bc, err := compile.CompileWithGlobals(&scriptContent, c.globals)
+	bc, err := compile.CompileWithGlobals(&scriptContent, c.compileGlobals())
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
 	}
@@ -115,7 +129,28 @@ func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
 	if risorExec == nil {
 		return nil, ErrExecCreationFailed
 	}
+	risorExec.limits = c.limits
+	risorExec.hostFunctions = slices.Clone(c.hostFunctions)
+	risorExec.evalOptions = c.evalOptions
+	if c.importFS != nil {
+		risorExec.modules = internal.NewModuleCache(c.importFS, risorExec.hostFunctions, c.limits)
+	}
 
 	logger.Debug("Risor compilation completed")
 	return risorExec, nil
 }
+
+// compileGlobals returns the global names used to compile scripts, including host functions and
+// the import builtin
+func (c *Compiler) compileGlobals() []string {
+	globals := slices.Clone(c.globals)
+	for _, fn := range c.hostFunctions {
+		if !slices.Contains(globals, fn.Name()) {
+			globals = append(globals, fn.Name())
+		}
+	}
+	if c.importFS != nil && !slices.Contains(globals, internal.ImportBuiltin) {
+		globals = append(globals, internal.ImportBuiltin)
+	}
+	return globals
+}
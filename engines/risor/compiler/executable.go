@@ -1,13 +1,25 @@
 package compiler
 
 import (
+	"slices"
+
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	"github.com/robbyt/go-polyscript/engines/risor/internal"
 	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
 )
 
+// MachineType is the machine type of Risor executables. It's registered with the file
+// extensions of Risor scripts by the risor package.
+const MachineType machineTypes.Type = "risor"
+
 type executable struct {
 	scriptBodyBytes []byte
 	ByteCode        *bytecode.Code
+	limits          platform.Limits
+	hostFunctions   []*platform.HostFunction
+	modules         *internal.ModuleCache
+	evalOptions     []platform.EvalOption
 }
 
 func newExecutable(scriptBodyBytes []byte, byteCode *bytecode.Code) *executable {
@@ -34,5 +46,26 @@ func (e *executable) GetRisorByteCode() *bytecode.Code {
 }
 
 func (e *executable) GetMachineType() machineTypes.Type {
-	return machineTypes.Risor
+	return MachineType
+}
+
+// GetLimits returns the resource limits set when the script was compiled
+func (e *executable) GetLimits() platform.Limits {
+	return e.limits
+}
+
+// GetHostFunctions returns the host functions set when the script was compiled
+func (e *executable) GetHostFunctions() []*platform.HostFunction {
+	return slices.Clone(e.hostFunctions)
+}
+
+// GetModules returns the cache of modules the script can import, or nil when the script was
+// compiled without an import root
+func (e *executable) GetModules() *internal.ModuleCache {
+	return e.modules
+}
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
 }
@@ -4,7 +4,6 @@ import (
 	"testing"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
-	machineTypes "github.com/robbyt/go-polyscript/engines/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,7 +23,7 @@ func TestExecutable(t *testing.T) {
 			assert.Equal(t, content, exe.GetSource())
 			assert.Equal(t, bc, exe.GetByteCode())
 			assert.Equal(t, bc, exe.GetRisorByteCode())
-			assert.Equal(t, machineTypes.Risor, exe.GetMachineType())
+			assert.Equal(t, MachineType, exe.GetMachineType())
 		})
 
 		t.Run("nil content", func(t *testing.T) {
@@ -73,7 +72,7 @@ func TestExecutable(t *testing.T) {
 
 		t.Run("GetMachineType", func(t *testing.T) {
 			machineType := executable.GetMachineType()
-			assert.Equal(t, machineTypes.Risor, machineType)
+			assert.Equal(t, MachineType, machineType)
 		})
 	})
 }
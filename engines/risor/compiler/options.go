@@ -2,11 +2,13 @@ package compiler
 
 import (
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"slices"
 
 	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 )
 
@@ -33,6 +35,76 @@ func WithCtxGlobal() FunctionalOption {
 	}
 }
 
+// WithLimits creates an option to set resource limits for the compiled script. Risor enforces
+// MaxSteps as the maximum number of VM instructions, and MaxStackDepth as the maximum depth of
+// the value and call frame stacks.
+func WithLimits(limits platform.Limits) FunctionalOption {
+	return func(c *Compiler) error {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+		c.limits = limits
+		return nil
+	}
+}
+
+// WithHostFunctions creates an option to make Go functions callable from Risor scripts. Each
+// function is a builtin under its name, and receives the context of the evaluation. The option
+// can be used more than once, but each name can only be registered once.
+func WithHostFunctions(funcs ...*platform.HostFunction) FunctionalOption {
+	return func(c *Compiler) error {
+		for _, fn := range funcs {
+			if fn == nil {
+				return fmt.Errorf("host function cannot be nil")
+			}
+			if slices.ContainsFunc(c.hostFunctions, func(h *platform.HostFunction) bool {
+				return h.Name() == fn.Name()
+			}) {
+				return fmt.Errorf("duplicate host function %q", fn.Name())
+			}
+			c.hostFunctions = append(c.hostFunctions, fn)
+		}
+		return nil
+	}
+}
+
+// WithImportFS creates an option to let scripts import modules from a file system. A script
+// calls import("lib/helpers.risor") to run a module, and uses its top level variables and
+// functions through the returned module object. Paths are relative to the root of fsys. Each
+// module is compiled once per compiled script, and run once per evaluation that imports it.
+func WithImportFS(fsys fs.FS) FunctionalOption {
+	return func(c *Compiler) error {
+		if fsys == nil {
+			return fmt.Errorf("import file system cannot be nil")
+		}
+		c.importFS = fsys
+		return nil
+	}
+}
+
+// WithImportDir creates an option to let scripts import modules from a directory. See
+// WithImportFS.
+func WithImportDir(dir string) FunctionalOption {
+	return func(c *Compiler) error {
+		if dir == "" {
+			return fmt.Errorf("import directory cannot be empty")
+		}
+		c.importFS = os.DirFS(dir)
+		return nil
+	}
+}
+
+// WithEvalOptions creates an option to configure the evaluators of the compiled script, e.g.
+// platform.WithDefaultTimeout. It sets evaluation options through helpers that only take
+// compiler options, such as polyscript.FromRisorString. Options given to the evaluator itself
+// take precedence.
+func WithEvalOptions(opts ...platform.EvalOption) FunctionalOption {
+	return func(c *Compiler) error {
+		c.evalOptions = append(c.evalOptions, opts...)
+		return nil
+	}
+}
+
 // WithLogHandler creates an option to set the log handler for Risor compiler.
 // This is the preferred option for logging configuration as it provides
 // more flexibility through the slog.Handler interface.
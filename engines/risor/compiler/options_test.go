@@ -2,9 +2,13 @@ package compiler
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"testing"
+	"time"
+	"testing/fstest"
 
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/stretchr/testify/require"
 )
@@ -87,6 +91,121 @@ func TestCompilerOptionsDetailed(t *testing.T) {
 		})
 	})
 
+	t.Run("WithLimits", func(t *testing.T) {
+		t.Run("valid limits", func(t *testing.T) {
+			c := &Compiler{}
+			err := WithLimits(platform.Limits{MaxSteps: 100, MaxStackDepth: 10})(c)
+
+			require.NoError(t, err)
+			require.Equal(t, platform.Limits{MaxSteps: 100, MaxStackDepth: 10}, c.limits)
+		})
+
+		t.Run("invalid limits", func(t *testing.T) {
+			c := &Compiler{}
+			err := WithLimits(platform.Limits{MaxStackDepth: -1})(c)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid limits")
+		})
+
+		t.Run("limits are set on the executable", func(t *testing.T) {
+			c, err := New(WithLimits(platform.Limits{MaxSteps: 100}))
+			require.NoError(t, err)
+
+			exe, err := c.compile([]byte(`1 + 1`))
+			require.NoError(t, err)
+			require.Equal(t, platform.Limits{MaxSteps: 100}, exe.GetLimits())
+		})
+	})
+
+	t.Run("WithEvalOptions", func(t *testing.T) {
+		c, err := New(WithEvalOptions(platform.WithDefaultTimeout(time.Second)))
+		require.NoError(t, err)
+		require.Len(t, c.evalOptions, 1)
+
+		exe, err := c.compile([]byte(`1 + 1`))
+		require.NoError(t, err)
+		opts := platform.NewEvalOptions(exe.GetEvalOptions()...)
+		require.Equal(t, time.Second, opts.DefaultTimeout)
+	})
+
+	t.Run("WithHostFunctions", func(t *testing.T) {
+		double, err := platform.NewHostFunction(
+			"double",
+			func(ctx context.Context, n int) (int, error) { return n * 2, nil },
+		)
+		require.NoError(t, err)
+
+		t.Run("adds functions", func(t *testing.T) {
+			c := &Compiler{}
+			require.NoError(t, WithHostFunctions(double)(c))
+			require.Equal(t, []*platform.HostFunction{double}, c.hostFunctions)
+		})
+
+		t.Run("nil function", func(t *testing.T) {
+			c := &Compiler{}
+			err := WithHostFunctions(nil)(c)
+			require.Error(t, err)
+		})
+
+		t.Run("duplicate name", func(t *testing.T) {
+			c := &Compiler{}
+			require.NoError(t, WithHostFunctions(double)(c))
+			err := WithHostFunctions(double)(c)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "duplicate host function")
+		})
+
+		t.Run("names are compile globals and functions are set on the executable", func(t *testing.T) {
+			c, err := New(WithHostFunctions(double), WithGlobals([]string{constants.Ctx}))
+			require.NoError(t, err)
+
+			exe, err := c.compile([]byte(`double(21)`))
+			require.NoError(t, err)
+			require.Equal(t, []*platform.HostFunction{double}, exe.GetHostFunctions())
+		})
+
+		t.Run("undefined without the option", func(t *testing.T) {
+			c, err := New()
+			require.NoError(t, err)
+
+			_, err = c.compile([]byte(`double(21)`))
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("Imports", func(t *testing.T) {
+		fsys := fstest.MapFS{"lib.risor": &fstest.MapFile{Data: []byte(`let answer = 42`)}}
+
+		t.Run("WithImportFS", func(t *testing.T) {
+			c, err := New(WithImportFS(fsys))
+			require.NoError(t, err)
+
+			exe, err := c.compile([]byte(`import("lib.risor").answer`))
+			require.NoError(t, err)
+			require.NotNil(t, exe.GetModules())
+		})
+
+		t.Run("WithImportDir", func(t *testing.T) {
+			c := &Compiler{}
+			require.NoError(t, WithImportDir(t.TempDir())(c))
+			require.NotNil(t, c.importFS)
+		})
+
+		t.Run("invalid roots", func(t *testing.T) {
+			require.Error(t, WithImportFS(nil)(&Compiler{}))
+			require.Error(t, WithImportDir("")(&Compiler{}))
+		})
+
+		t.Run("import is undefined without a root", func(t *testing.T) {
+			c, err := New()
+			require.NoError(t, err)
+
+			_, err = c.compile([]byte(`import("lib.risor").answer`))
+			require.Error(t, err)
+		})
+	})
+
 	t.Run("Logger", func(t *testing.T) {
 		t.Run("default initialization", func(t *testing.T) {
 			c, err := New()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"time"
 
 	risor "github.com/deepnoodle-ai/risor/v2"
@@ -21,6 +22,11 @@ import (
 // risor v2's DefaultRegistry() lazy initialization.
 var typeRegistry = risorObject.DefaultRegistry()
 
+// moduleContent is implemented by executable content compiled with an import root
+type moduleContent interface {
+	GetModules() *internal.ModuleCache
+}
+
 // Evaluator is an abstraction layer for evaluating bytecode on the Risor engine
 type Evaluator struct {
 	// ctxKey is the variable name used to access input data inside the engine (ctx)
@@ -29,20 +35,26 @@ type Evaluator struct {
 	// execUnit contains the compiled script and data provider
 	execUnit *script.ExecutableUnit
 
+	// evalOpts configures evaluation, such as the default timeout
+	evalOpts platform.EvalOptions
+
 	logHandler slog.Handler
 	logger     *slog.Logger
 }
 
-// New creates a new Evaluator object
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
 func New(
 	handler slog.Handler,
 	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
 ) *Evaluator {
 	handler, logger := helpers.SetupLogger(handler, "risor", "Evaluator")
 
 	return &Evaluator{
 		ctxKey:     constants.Ctx,
 		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
 		logHandler: handler,
 		logger:     logger,
 	}
@@ -66,6 +78,60 @@ func (be *Evaluator) loadInputData(ctx context.Context) (map[string]any, error)
 	return data.LoadInputData(ctx, be.logger.WithGroup("loadInputData"), be.getDataProvider())
 }
 
+// getLimits returns the resource limits of the compiled script, if it has any
+func (be *Evaluator) getLimits() platform.Limits {
+	if be.execUnit == nil {
+		return platform.Limits{}
+	}
+	limited, ok := be.execUnit.GetContent().(platform.LimitedContent)
+	if !ok {
+		return platform.Limits{}
+	}
+	return limited.GetLimits()
+}
+
+// getHostFunctions returns the host functions of the compiled script, if it has any
+func (be *Evaluator) getHostFunctions() []*platform.HostFunction {
+	if be.execUnit == nil {
+		return nil
+	}
+	withFuncs, ok := be.execUnit.GetContent().(platform.HostFunctionContent)
+	if !ok {
+		return nil
+	}
+	return withFuncs.GetHostFunctions()
+}
+
+// getModules returns the cache of modules the compiled script can import, if it has one
+func (be *Evaluator) getModules() *internal.ModuleCache {
+	if be.execUnit == nil {
+		return nil
+	}
+	withModules, ok := be.execUnit.GetContent().(moduleContent)
+	if !ok {
+		return nil
+	}
+	return withModules.GetModules()
+}
+
+// runOptions returns the risor options for a run, including any resource limits
+func (be *Evaluator) runOptions(env map[string]any) []risor.Option {
+	opts := []risor.Option{
+		risor.WithEnv(env),
+		risor.WithRawResult(),
+		risor.WithTypeRegistry(typeRegistry),
+	}
+
+	limits := be.getLimits()
+	if limits.MaxSteps > 0 {
+		opts = append(opts, risor.WithMaxSteps(int64(min(limits.MaxSteps, math.MaxInt64))))
+	}
+	if limits.MaxStackDepth > 0 {
+		opts = append(opts, risor.WithMaxStackDepth(limits.MaxStackDepth))
+	}
+	return opts
+}
+
 // exec runs the bytecode with the provided environment map
 func (be *Evaluator) exec(
 	ctx context.Context,
@@ -73,10 +139,13 @@ func (be *Evaluator) exec(
 	env map[string]any,
 ) (*execResult, error) {
 	startTime := time.Now()
-	result, err := risor.Run(ctx, bc, risor.WithEnv(env), risor.WithRawResult(), risor.WithTypeRegistry(typeRegistry))
+	result, err := risor.Run(ctx, bc, be.runOptions(env)...)
 	execTime := time.Since(startTime)
 
 	if err != nil {
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("risor execution error: %w: %w", ctxErr, err)
+		}
 		return nil, fmt.Errorf("risor execution error: %w", err)
 	}
 
@@ -91,6 +160,9 @@ func (be *Evaluator) exec(
 // Eval evaluates the loaded bytecode and uses the provided EvalData to pass data in to the Risor engine execution
 func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
 	logger := be.logger.WithGroup("Eval")
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
 	if be.execUnit == nil {
 		return nil, fmt.Errorf("executable unit is nil")
 	}
@@ -127,8 +199,11 @@ func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, erro
 		return nil, fmt.Errorf("failed to get input data: %w", err)
 	}
 
-	// 3. Build the Risor environment with builtins and input data
-	runtimeEnv := internal.BuildRisorEnv(be.ctxKey, rawInputData)
+	// 3. Build the Risor environment with builtins, host functions, and input data
+	runtimeEnv := internal.BuildRisorEnv(be.ctxKey, rawInputData, be.getHostFunctions()...)
+	if modules := be.getModules(); modules != nil {
+		runtimeEnv[internal.ImportBuiltin] = modules.NewImportBuiltin()
+	}
 
 	// 4. Execute the program
 	result, err := be.exec(ctx, risorByteCode, runtimeEnv)
@@ -2,18 +2,22 @@ package evaluator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
 	"github.com/robbyt/go-polyscript/engines/risor/compiler"
 	"github.com/robbyt/go-polyscript/engines/types"
 	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/robbyt/go-polyscript/platform/data"
 	"github.com/robbyt/go-polyscript/platform/script"
@@ -75,7 +79,7 @@ func (m *MockContent) GetByteCode() any {
 }
 
 func (m *MockContent) GetMachineType() types.Type {
-	return types.Risor
+	return compiler.MachineType
 }
 
 // Helper function to create a test executable unit
@@ -110,6 +114,241 @@ func createTestExecutable(
 	}, nil
 }
 
+// TestEvaluator_Limits tests that the limits set at compile time are enforced by the VM
+func TestEvaluator_Limits(t *testing.T) {
+	t.Parallel()
+
+	newEvaluator := func(t *testing.T, scriptContent string, limits platform.Limits) *Evaluator {
+		t.Helper()
+		ldr, err := loader.NewFromString(scriptContent)
+		require.NoError(t, err)
+
+		comp, err := compiler.New(compiler.WithCtxGlobal(), compiler.WithLimits(limits))
+		require.NoError(t, err)
+
+		exe, err := script.NewExecutableUnit(
+			nil,
+			"",
+			ldr,
+			comp,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.NoError(t, err)
+		return New(nil, exe)
+	}
+
+	// Risor has no loops, so recursion is used to run many instructions
+	sumScript := `
+		function sum(n) {
+			if (n == 0) { return 0 }
+			return n + sum(n - 1)
+		}
+		sum(200)
+	`
+
+	t.Run("step limit exceeded", func(t *testing.T) {
+		evaluator := newEvaluator(t, sumScript, platform.Limits{MaxSteps: 100})
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "step limit exceeded")
+	})
+
+	t.Run("within step limit", func(t *testing.T) {
+		evaluator := newEvaluator(t, sumScript, platform.Limits{MaxSteps: 1_000_000})
+		response, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, int64(20100), response.Interface())
+	})
+
+	t.Run("stack depth exceeded", func(t *testing.T) {
+		recursive := `
+			function down(n) {
+				if (n == 0) { return 0 }
+				return down(n - 1) + 1
+			}
+			down(500)
+		`
+		evaluator := newEvaluator(t, recursive, platform.Limits{MaxStackDepth: 50})
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stack overflow")
+
+		evaluator = newEvaluator(t, recursive, platform.Limits{})
+		response, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, int64(500), response.Interface())
+	})
+}
+
+// TestEvaluator_HostFunctions tests that Go functions registered with the compiler can be called
+// from scripts, and receive the context of the evaluation
+func TestEvaluator_HostFunctions(t *testing.T) {
+	t.Parallel()
+
+	type tenantKey struct{}
+	type customer struct {
+		ID     string   `json:"id"`
+		Tenant string   `json:"tenant"`
+		Tags   []string `json:"tags"`
+		Score  float64  `json:"score"`
+	}
+
+	lookup, err := platform.NewHostFunction(
+		"lookup",
+		func(ctx context.Context, id string, tags []string) (customer, error) {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return customer{ID: id, Tenant: tenant, Tags: tags, Score: 1.5}, nil
+		},
+	)
+	require.NoError(t, err)
+	fail, err := platform.NewHostFunction("fail", func(ctx context.Context) error {
+		return errors.New("lookup service unavailable")
+	})
+	require.NoError(t, err)
+
+	newEvaluator := func(t *testing.T, scriptContent string) *Evaluator {
+		t.Helper()
+		ldr, err := loader.NewFromString(scriptContent)
+		require.NoError(t, err)
+
+		comp, err := compiler.New(
+			compiler.WithCtxGlobal(),
+			compiler.WithHostFunctions(lookup, fail),
+		)
+		require.NoError(t, err)
+
+		exe, err := script.NewExecutableUnit(
+			nil,
+			"",
+			ldr,
+			comp,
+			data.NewStaticProvider(map[string]any{"id": "c-42"}),
+		)
+		require.NoError(t, err)
+		return New(nil, exe)
+	}
+
+	t.Run("typed arguments and result", func(t *testing.T) {
+		evaluator := newEvaluator(t, `lookup(ctx["id"], ["gold", "beta"])`)
+
+		ctx := context.WithValue(t.Context(), tenantKey{}, "acme")
+		response, err := evaluator.Eval(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"id":     "c-42",
+			"tenant": "acme",
+			"tags":   []any{"gold", "beta"},
+			"score":  1.5,
+		}, response.Interface())
+	})
+
+	t.Run("function error", func(t *testing.T) {
+		evaluator := newEvaluator(t, `fail()`)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "lookup service unavailable")
+	})
+
+	t.Run("wrong number of arguments", func(t *testing.T) {
+		evaluator := newEvaluator(t, `lookup("c-1")`)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "takes 2 arguments, got 1")
+	})
+}
+
+// TestEvaluator_Imports tests that scripts can import modules from the import root
+func TestEvaluator_Imports(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greet.risor"), []byte(`
+let greeting = "Hello"
+function greet(name) { return greeting + ", " + name + "!" }
+`), 0o644))
+
+	ldr, err := loader.NewFromString(`
+let g = import("greet.risor")
+g.greet(ctx["name"])
+`)
+	require.NoError(t, err)
+
+	comp, err := compiler.New(compiler.WithCtxGlobal(), compiler.WithImportDir(dir))
+	require.NoError(t, err)
+
+	exe, err := script.NewExecutableUnit(
+		nil,
+		"",
+		ldr,
+		comp,
+		data.NewStaticProvider(map[string]any{"name": "World"}),
+	)
+	require.NoError(t, err)
+	evaluator := New(nil, exe)
+
+	for range 2 {
+		response, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, "Hello, World!", response.Interface())
+	}
+}
+
+// TestEvaluator_ContextErrors tests that a script stopped by its context returns an error
+// wrapping the platform timeout or cancellation error
+func TestEvaluator_ContextErrors(t *testing.T) {
+	t.Parallel()
+
+	// Tree recursion runs for a long time without a deep stack
+	fibScript := `
+		function fib(n) {
+			if (n < 2) { return n }
+			return fib(n - 1) + fib(n - 2)
+		}
+		fib(40)
+	`
+
+	newExecutableUnit := func(t *testing.T) *script.ExecutableUnit {
+		t.Helper()
+		ldr, err := loader.NewFromString(fibScript)
+		require.NoError(t, err)
+
+		comp, err := compiler.New(compiler.WithCtxGlobal())
+		require.NoError(t, err)
+
+		exe, err := script.NewExecutableUnit(
+			nil,
+			"",
+			ldr,
+			comp,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.NoError(t, err)
+		return exe
+	}
+
+	t.Run("default timeout", func(t *testing.T) {
+		evaluator := New(nil, newExecutableUnit(t), platform.WithDefaultTimeout(50*time.Millisecond))
+
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		evaluator := New(nil, newExecutableUnit(t))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		defer cancel()
+
+		_, err := evaluator.Eval(ctx)
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrCanceled)
+		require.Contains(t, err.Error(), "risor execution error")
+	})
+}
+
 // TestEvaluator_Evaluate tests evaluating Risor scripts
 func TestEvaluator_Evaluate(t *testing.T) {
 	t.Parallel()
@@ -2,10 +2,12 @@ package internal
 
 import (
 	risor "github.com/deepnoodle-ai/risor/v2"
+	"github.com/robbyt/go-polyscript/platform"
 )
 
 // BuildRisorEnv builds the full Risor environment map with standard builtins and input data.
-// The input data is made available under the given ctxKey (typically "ctx").
+// The input data is made available under the given ctxKey (typically "ctx"), and each host
+// function is added as a builtin under its name.
 //
 // For example, if the inputData is {"foo": "bar", "baz": 123}, the output will be a map
 // containing all standard Risor builtins plus:
@@ -14,9 +16,16 @@ import (
 //	    "foo": "bar",
 //	    "baz": 123,
 //	}
-func BuildRisorEnv(ctxKey string, inputData map[string]any) map[string]any {
+func BuildRisorEnv(
+	ctxKey string,
+	inputData map[string]any,
+	funcs ...*platform.HostFunction,
+) map[string]any {
 	// Builtins() returns a fresh map on each call, so mutating env is safe.
 	env := risor.Builtins()
+	for _, fn := range funcs {
+		env[fn.Name()] = NewBuiltin(fn)
+	}
 	env[ctxKey] = inputData
 	return env
 }
@@ -1,8 +1,12 @@
 package internal
 
 import (
+	"context"
 	"testing"
 
+	risorObject "github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/robbyt/go-polyscript/platform"
+
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,4 +34,20 @@ func TestBuildRisorEnv(t *testing.T) {
 		require.True(t, ok)
 		assert.Equal(t, "bar", ctxData["foo"])
 	})
+
+	t.Run("adds host functions as builtins", func(t *testing.T) {
+		double, err := platform.NewHostFunction(
+			"double",
+			func(ctx context.Context, n int) (int, error) { return n * 2, nil },
+		)
+		require.NoError(t, err)
+
+		env := BuildRisorEnv(constants.Ctx, map[string]any{}, double)
+		builtin, ok := env["double"].(*risorObject.Builtin)
+		require.True(t, ok)
+
+		result, err := builtin.Call(t.Context(), risorObject.NewInt(21))
+		require.NoError(t, err)
+		assert.Equal(t, risorObject.NewInt(42), result)
+	})
 }
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"context"
+
+	risorObject "github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/robbyt/go-polyscript/platform"
+)
+
+// typeRegistry is initialized once at package level to avoid a data race in
+// risor v2's DefaultRegistry() lazy initialization.
+var typeRegistry = risorObject.DefaultRegistry()
+
+// NewBuiltin adapts a host function into a Risor builtin. Arguments are converted to Go values
+// before the call, and the result is converted back to a Risor object.
+func NewBuiltin(fn *platform.HostFunction) *risorObject.Builtin {
+	return risorObject.NewBuiltin(
+		fn.Name(),
+		func(ctx context.Context, args ...risorObject.Object) (risorObject.Object, error) {
+			goArgs := make([]any, len(args))
+			for i, arg := range args {
+				goArgs[i] = arg.Interface()
+			}
+
+			result, err := fn.Call(ctx, goArgs...)
+			if err != nil {
+				return nil, err
+			}
+			return typeRegistry.FromGo(result)
+		},
+	)
+}
@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	risor "github.com/deepnoodle-ai/risor/v2"
+	"github.com/deepnoodle-ai/risor/v2/pkg/bytecode"
+	risorObject "github.com/deepnoodle-ai/risor/v2/pkg/object"
+	risorVM "github.com/deepnoodle-ai/risor/v2/pkg/vm"
+	"github.com/robbyt/go-polyscript/platform"
+)
+
+// ImportBuiltin is the name of the builtin scripts use to import modules
+const ImportBuiltin = "import"
+
+// ModuleCache compiles the modules Risor scripts import from a file system, and caches the
+// bytecode so each module is compiled once. Modules run with the standard builtins, the host
+// functions, and the import builtin, but not the script's input data. It's safe for concurrent
+// use.
+type ModuleCache struct {
+	fsys          fs.FS
+	hostFunctions []*platform.HostFunction
+	limits        platform.Limits
+
+	mu    sync.Mutex
+	codes map[string]*bytecode.Code
+}
+
+// NewModuleCache creates a cache for modules read from fsys
+func NewModuleCache(
+	fsys fs.FS,
+	hostFunctions []*platform.HostFunction,
+	limits platform.Limits,
+) *ModuleCache {
+	return &ModuleCache{
+		fsys:          fsys,
+		hostFunctions: hostFunctions,
+		limits:        limits,
+		codes:         make(map[string]*bytecode.Code),
+	}
+}
+
+// moduleEnv returns the environment modules are compiled and run with. The key set must be the
+// same at compile and run time, because Risor binds globals by index.
+func (c *ModuleCache) moduleEnv(importBuiltin risorObject.Object) map[string]any {
+	env := risor.Builtins()
+	for _, fn := range c.hostFunctions {
+		env[fn.Name()] = NewBuiltin(fn)
+	}
+	env[ImportBuiltin] = importBuiltin
+	return env
+}
+
+// compile returns the bytecode of a module, compiling and caching it on first use
+func (c *ModuleCache) compile(ctx context.Context, path string) (*bytecode.Code, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if code, ok := c.codes[path]; ok {
+		return code, nil
+	}
+
+	if !fs.ValidPath(path) {
+		return nil, fmt.Errorf("invalid module path %q", path)
+	}
+	src, err := fs.ReadFile(c.fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module %q: %w", path, err)
+	}
+
+	code, err := risor.Compile(
+		ctx,
+		string(src),
+		risor.WithEnv(c.moduleEnv(risorObject.Nil)),
+		risor.WithFilename(path),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile module %q: %w", path, err)
+	}
+	c.codes[path] = code
+	return code, nil
+}
+
+// vmOptions returns the options for the VMs modules run in, including any resource limits
+func (c *ModuleCache) vmOptions(env map[string]any) []risorVM.Option {
+	opts := []risorVM.Option{
+		risorVM.WithGlobals(env),
+		risorVM.WithTypeRegistry(typeRegistry),
+	}
+	if c.limits.MaxSteps > 0 {
+		opts = append(opts, risorVM.WithMaxSteps(int64(min(c.limits.MaxSteps, math.MaxInt64))))
+	}
+	if c.limits.MaxStackDepth > 0 {
+		opts = append(opts, risorVM.WithMaxStackDepth(c.limits.MaxStackDepth))
+	}
+	return opts
+}
+
+// NewImportBuiltin returns the import builtin for one evaluation. Each module is run once per
+// evaluation, the first time it's imported, and later imports return the same module.
+func (c *ModuleCache) NewImportBuiltin() *risorObject.Builtin {
+	imp := &importer{cache: c, modules: make(map[string]*risorObject.Module)}
+	imp.builtin = risorObject.NewBuiltin(ImportBuiltin, imp.importModule)
+	return imp.builtin
+}
+
+// importer loads the modules imported during one evaluation. Risor runs a script and its
+// builtins on one goroutine, so it isn't locked.
+type importer struct {
+	cache   *ModuleCache
+	builtin *risorObject.Builtin
+	modules map[string]*risorObject.Module
+	loading []string
+}
+
+// importModule implements the import builtin, which takes the path of a module
+func (i *importer) importModule(
+	ctx context.Context,
+	args ...risorObject.Object,
+) (risorObject.Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("import: expected 1 argument, got %d", len(args))
+	}
+	path, ok := args[0].(*risorObject.String)
+	if !ok {
+		return nil, fmt.Errorf("import: expected a string path, got %s", args[0].Type())
+	}
+	return i.load(ctx, path.Value())
+}
+
+// load runs a module, and returns its globals as a module object
+func (i *importer) load(ctx context.Context, path string) (*risorObject.Module, error) {
+	if module, ok := i.modules[path]; ok {
+		return module, nil
+	}
+	if slices.Contains(i.loading, path) {
+		return nil, fmt.Errorf(
+			"import cycle: %s",
+			strings.Join(append(slices.Clone(i.loading), path), " -> "),
+		)
+	}
+
+	code, err := i.cache.compile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	i.loading = append(i.loading, path)
+	defer func() { i.loading = i.loading[:len(i.loading)-1] }()
+
+	env := i.cache.moduleEnv(i.builtin)
+	machine, err := risorVM.New(code, i.cache.vmOptions(env)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VM for module %q: %w", path, err)
+	}
+	if err := machine.Run(ctx); err != nil {
+		return nil, fmt.Errorf("failed to run module %q: %w", path, err)
+	}
+
+	contents := make(map[string]risorObject.Object)
+	for _, name := range machine.GlobalNames() {
+		if _, isEnv := env[name]; isEnv {
+			continue
+		}
+		value, err := machine.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q from module %q: %w", name, path, err)
+		}
+		contents[name] = exportValue(machine, name, value)
+	}
+
+	module := risorObject.NewBuiltinsModule(path, contents)
+	i.modules[path] = module
+	return module, nil
+}
+
+// exportValue prepares a module global for use by other scripts. Functions run in the module's
+// VM, because Risor resolves a function's globals in the VM that calls it.
+func exportValue(
+	machine *risorVM.VirtualMachine,
+	name string,
+	value risorObject.Object,
+) risorObject.Object {
+	closure, ok := value.(*risorObject.Closure)
+	if !ok {
+		return value
+	}
+	return risorObject.NewBuiltin(
+		name,
+		func(ctx context.Context, args ...risorObject.Object) (risorObject.Object, error) {
+			return machine.Call(ctx, closure, args)
+		},
+	)
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	risor "github.com/deepnoodle-ai/risor/v2"
+	risorObject "github.com/deepnoodle-ai/risor/v2/pkg/object"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleCache(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"lib/math.risor": &fstest.MapFile{Data: []byte(`
+let factor = 3
+function scale(x) { return x * factor + offset() }
+function offset() { return 1 }
+`)},
+		"lib/greet.risor": &fstest.MapFile{Data: []byte(`
+let m = import("lib/math.risor")
+function greet(name) { return shout("Hello, " + name) + " x" + string(m.scale(2)) }
+`)},
+		"lib/a.risor":   &fstest.MapFile{Data: []byte(`let b = import("lib/b.risor")`)},
+		"lib/b.risor":   &fstest.MapFile{Data: []byte(`let a = import("lib/a.risor")`)},
+		"lib/bad.risor": &fstest.MapFile{Data: []byte(`let x = `)},
+	}
+
+	shout, err := platform.NewHostFunction(
+		"shout",
+		func(ctx context.Context, s string) (string, error) { return s + "!", nil },
+	)
+	require.NoError(t, err)
+
+	// run compiles and runs a script with the import builtin, like the evaluator does
+	run := func(t *testing.T, cache *ModuleCache, script string) (any, error) {
+		t.Helper()
+		env := BuildRisorEnv("ctx", map[string]any{}, shout)
+		env[ImportBuiltin] = cache.NewImportBuiltin()
+
+		code, err := risor.Compile(t.Context(), script, risor.WithEnv(env))
+		require.NoError(t, err)
+		return risor.Run(
+			t.Context(),
+			code,
+			risor.WithEnv(env),
+			risor.WithTypeRegistry(typeRegistry),
+		)
+	}
+
+	t.Run("imports variables and functions", func(t *testing.T) {
+		cache := NewModuleCache(fsys, nil, platform.Limits{})
+		result, err := run(
+			t,
+			cache,
+			`let m = import("lib/math.risor"); [m.factor, m.scale(2), [1, 2].map(m.scale)]`,
+		)
+		require.NoError(t, err)
+		require.Equal(t, []any{int64(3), int64(7), []any{int64(4), int64(7)}}, result)
+	})
+
+	t.Run("nested imports and host functions", func(t *testing.T) {
+		cache := NewModuleCache(fsys, []*platform.HostFunction{shout}, platform.Limits{})
+		result, err := run(t, cache, `import("lib/greet.risor").greet("World")`)
+		require.NoError(t, err)
+		require.Equal(t, "Hello, World! x7", result)
+	})
+
+	t.Run("modules are compiled once and run once per evaluation", func(t *testing.T) {
+		cache := NewModuleCache(fsys, nil, platform.Limits{})
+		result, err := run(t, cache, `import("lib/math.risor") == import("lib/math.risor")`)
+		require.NoError(t, err)
+		same, ok := result.(bool)
+		require.True(t, ok)
+		require.True(t, same)
+		require.Len(t, cache.codes, 1)
+
+		code := cache.codes["lib/math.risor"]
+		_, err = run(t, cache, `import("lib/math.risor")`)
+		require.NoError(t, err)
+		require.Same(t, code, cache.codes["lib/math.risor"])
+	})
+
+	t.Run("import cycle", func(t *testing.T) {
+		cache := NewModuleCache(fsys, nil, platform.Limits{})
+		_, err := run(t, cache, `import("lib/a.risor")`)
+		require.Error(t, err)
+		require.Contains(
+			t,
+			err.Error(),
+			"import cycle: lib/a.risor -> lib/b.risor -> lib/a.risor",
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cache := NewModuleCache(fsys, nil, platform.Limits{})
+		tests := map[string]string{
+			`import("lib/missing.risor")`: "failed to read module",
+			`import("../secret.risor")`:   "invalid module path",
+			`import("lib/bad.risor")`:     "failed to compile module",
+			`import(1)`:                   "expected a string path",
+			`import()`:                    "expected 1 argument",
+		}
+		for script, want := range tests {
+			_, err := run(t, cache, script)
+			require.Error(t, err, script)
+			require.Contains(t, err.Error(), want, script)
+		}
+	})
+
+	t.Run("missing module wraps the fs error", func(t *testing.T) {
+		cache := NewModuleCache(fsys, nil, platform.Limits{})
+		_, err := cache.compile(t.Context(), "lib/missing.risor")
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("step limit applies to modules", func(t *testing.T) {
+		limited := fstest.MapFS{
+			"fib.risor": &fstest.MapFile{Data: []byte(`
+function fib(n) { if (n < 2) { return n }; return fib(n - 1) + fib(n - 2) }
+let result = fib(25)
+`)},
+		}
+		cache := NewModuleCache(limited, nil, platform.Limits{MaxSteps: 1000})
+		_, err := run(t, cache, `import("fib.risor")`)
+		require.Error(t, err)
+	})
+
+	t.Run("import builtin object", func(t *testing.T) {
+		cache := NewModuleCache(fsys, nil, platform.Limits{})
+		builtin := cache.NewImportBuiltin()
+		require.Equal(t, ImportBuiltin, builtin.Name())
+
+		module, err := builtin.Call(t.Context(), risorObject.NewString("lib/math.risor"))
+		require.NoError(t, err)
+		factor, ok := module.GetAttr("factor")
+		require.True(t, ok)
+		require.Equal(t, risorObject.NewInt(3), factor)
+	})
+}
@@ -1,18 +1,33 @@
 package compiler
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 
 	"github.com/robbyt/go-polyscript/engines/starlark/compiler/internal/compile"
+	"github.com/robbyt/go-polyscript/engines/starlark/internal"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/script"
+	starlarkLib "go.starlark.net/starlark"
 )
 
 type Compiler struct {
 	globals    []string
+	limits     platform.Limits
 	logHandler slog.Handler
 	logger     *slog.Logger
+
+	// hostFunctions are added to the globals, and made available when the script runs
+	hostFunctions []*platform.HostFunction
+
+	// moduleResolver finds the modules imported with load()
+	moduleResolver ModuleResolver
+
+	// evalOptions configure the evaluators of the compiled script
+	evalOptions []platform.EvalOption
 }
 
 // New creates a new Starlark-specific Compiler instance with the provided options.
@@ -75,7 +90,7 @@ func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
 	logger.Debug("Starting Starlark compilation", "scriptLength", len(scriptBodyBytes))
 
 	// Compile the script with globals
-	program, err := compile.CompileWithEmptyGlobals(scriptBodyBytes, c.globals)
+	program, err := compile.CompileWithEmptyGlobals(scriptBodyBytes, c.compileGlobals())
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
 	}
@@ -89,7 +104,55 @@ func (c *Compiler) compile(scriptBodyBytes []byte) (*executable, error) {
 	if starlarkExec == nil {
 		return nil, ErrExecCreationFailed
 	}
+	starlarkExec.limits = c.limits
+	starlarkExec.hostFunctions = slices.Clone(c.hostFunctions)
+	starlarkExec.evalOptions = c.evalOptions
+
+	modules, err := c.loadModules(program)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	starlarkExec.modules = modules
 
 	logger.Debug("Starlark compilation completed")
 	return starlarkExec, nil
 }
+
+// compileGlobals returns the global names used to compile scripts, including host functions
+func (c *Compiler) compileGlobals() []string {
+	globals := slices.Clone(c.globals)
+	for _, fn := range c.hostFunctions {
+		if !slices.Contains(globals, fn.Name()) {
+			globals = append(globals, fn.Name())
+		}
+	}
+	return globals
+}
+
+// loadModules creates the module cache for a compiled program, and loads the modules it imports
+// so missing or invalid modules are reported at compile time. It returns nil when no module
+// resolver is set.
+func (c *Compiler) loadModules(program *starlarkLib.Program) (*internal.ModuleCache, error) {
+	modules := make([]string, 0, program.NumLoads())
+	for i := range program.NumLoads() {
+		module, _ := program.Load(i)
+		modules = append(modules, module)
+	}
+
+	if c.moduleResolver == nil {
+		if len(modules) > 0 {
+			return nil, fmt.Errorf("%w: %q", ErrNoModuleResolver, modules[0])
+		}
+		return nil, nil
+	}
+
+	cache := internal.NewModuleCache(
+		c.moduleResolver.ResolveModule,
+		c.hostFunctions,
+		c.limits.MaxSteps,
+	)
+	if err := cache.Preload(context.Background(), modules...); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
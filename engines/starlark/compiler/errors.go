@@ -6,5 +6,6 @@ var (
 	ErrBytecodeNil        = errors.New("starlark bytecode is nil")
 	ErrContentNil         = errors.New("starlark content is nil")
 	ErrExecCreationFailed = errors.New("unable to create starlark executable")
+	ErrNoModuleResolver   = errors.New("starlark load() requires a module resolver")
 	ErrValidationFailed   = errors.New("starlark script validation error")
 )
@@ -1,14 +1,26 @@
 package compiler
 
 import (
+	"slices"
+
+	"github.com/robbyt/go-polyscript/engines/starlark/internal"
 	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/platform"
 	starlarkLib "go.starlark.net/starlark"
 )
 
+// MachineType is the machine type of Starlark executables. It's registered with the file
+// extensions of Starlark scripts by the starlark package.
+const MachineType machineTypes.Type = "starlark"
+
 // executable represents a compiled Starlark script
 type executable struct {
 	scriptBodyBytes []byte
 	ByteCode        *starlarkLib.Program
+	limits          platform.Limits
+	hostFunctions   []*platform.HostFunction
+	modules         *internal.ModuleCache
+	evalOptions     []platform.EvalOption
 }
 
 // Keep the existing constructor and methods
@@ -36,5 +48,26 @@ func (e *executable) GetStarlarkByteCode() *starlarkLib.Program {
 }
 
 func (e *executable) GetMachineType() machineTypes.Type {
-	return machineTypes.Starlark
+	return MachineType
+}
+
+// GetLimits returns the resource limits set when the script was compiled
+func (e *executable) GetLimits() platform.Limits {
+	return e.limits
+}
+
+// GetHostFunctions returns the host functions set when the script was compiled
+func (e *executable) GetHostFunctions() []*platform.HostFunction {
+	return slices.Clone(e.hostFunctions)
+}
+
+// GetModules returns the cache of modules imported with load(), or nil when the script was
+// compiled without a module resolver
+func (e *executable) GetModules() *internal.ModuleCache {
+	return e.modules
+}
+
+// GetEvalOptions returns the evaluation options set when the script was compiled
+func (e *executable) GetEvalOptions() []platform.EvalOption {
+	return e.evalOptions
 }
@@ -3,7 +3,6 @@ package compiler
 import (
 	"testing"
 
-	machineTypes "github.com/robbyt/go-polyscript/engines/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	starlarkLib "go.starlark.net/starlark"
@@ -24,7 +23,7 @@ func TestExecutable(t *testing.T) {
 			assert.Equal(t, content, exe.GetSource())
 			assert.Equal(t, bytecode, exe.GetByteCode())
 			assert.Equal(t, bytecode, exe.GetStarlarkByteCode())
-			assert.Equal(t, machineTypes.Starlark, exe.GetMachineType())
+			assert.Equal(t, MachineType, exe.GetMachineType())
 		})
 
 		t.Run("nil content", func(t *testing.T) {
@@ -73,7 +72,7 @@ func TestExecutable(t *testing.T) {
 
 		t.Run("GetMachineType", func(t *testing.T) {
 			machineType := executable.GetMachineType()
-			assert.Equal(t, machineTypes.Starlark, machineType)
+			assert.Equal(t, MachineType, machineType)
 		})
 	})
 }
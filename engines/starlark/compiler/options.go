@@ -7,6 +7,7 @@ import (
 	"slices"
 
 	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 )
 
@@ -33,6 +34,63 @@ func WithCtxGlobal() FunctionalOption {
 	}
 }
 
+// WithLimits creates an option to set resource limits for the compiled script. Starlark
+// enforces MaxSteps, by stopping the script after that many execution steps.
+func WithLimits(limits platform.Limits) FunctionalOption {
+	return func(c *Compiler) error {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+		c.limits = limits
+		return nil
+	}
+}
+
+// WithHostFunctions creates an option to make Go functions callable from Starlark scripts. Each
+// function is a builtin under its name, and receives the context of the evaluation. The option
+// can be used more than once, but each name can only be registered once.
+func WithHostFunctions(funcs ...*platform.HostFunction) FunctionalOption {
+	return func(c *Compiler) error {
+		for _, fn := range funcs {
+			if fn == nil {
+				return fmt.Errorf("host function cannot be nil")
+			}
+			if slices.ContainsFunc(c.hostFunctions, func(h *platform.HostFunction) bool {
+				return h.Name() == fn.Name()
+			}) {
+				return fmt.Errorf("duplicate host function %q", fn.Name())
+			}
+			c.hostFunctions = append(c.hostFunctions, fn)
+		}
+		return nil
+	}
+}
+
+// WithModuleResolver creates an option to let scripts import modules with load(). Modules are
+// found with the resolver, e.g. NewDirResolver or NewFSResolver, and are loaded when the script
+// is compiled. Each module is run once per compiled script, and its frozen globals are shared
+// by every evaluation.
+func WithModuleResolver(resolver ModuleResolver) FunctionalOption {
+	return func(c *Compiler) error {
+		if resolver == nil {
+			return fmt.Errorf("module resolver cannot be nil")
+		}
+		c.moduleResolver = resolver
+		return nil
+	}
+}
+
+// WithEvalOptions creates an option to configure the evaluators of the compiled script, e.g.
+// platform.WithDefaultTimeout. It sets evaluation options through helpers that only take
+// compiler options, such as polyscript.FromStarlarkString. Options given to the evaluator itself
+// take precedence.
+func WithEvalOptions(opts ...platform.EvalOption) FunctionalOption {
+	return func(c *Compiler) error {
+		c.evalOptions = append(c.evalOptions, opts...)
+		return nil
+	}
+}
+
 // WithLogHandler creates an option to set the log handler for Starlark compiler.
 // This is the preferred option for logging configuration as it provides
 // more flexibility through the slog.Handler interface.
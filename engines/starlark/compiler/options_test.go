@@ -2,9 +2,12 @@ package compiler
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/stretchr/testify/require"
 )
@@ -87,6 +90,89 @@ func TestCompilerOptionsDetailed(t *testing.T) {
 		})
 	})
 
+	t.Run("WithLimits", func(t *testing.T) {
+		t.Run("valid limits", func(t *testing.T) {
+			c := &Compiler{}
+			err := WithLimits(platform.Limits{MaxSteps: 100})(c)
+
+			require.NoError(t, err)
+			require.Equal(t, uint64(100), c.limits.MaxSteps)
+		})
+
+		t.Run("invalid limits", func(t *testing.T) {
+			c := &Compiler{}
+			err := WithLimits(platform.Limits{MaxStackDepth: -1})(c)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid limits")
+		})
+
+		t.Run("limits are set on the executable", func(t *testing.T) {
+			c, err := New(WithLimits(platform.Limits{MaxSteps: 100}))
+			require.NoError(t, err)
+
+			exe, err := c.compile([]byte(`x = 1`))
+			require.NoError(t, err)
+			require.Equal(t, platform.Limits{MaxSteps: 100}, exe.GetLimits())
+		})
+	})
+
+	t.Run("WithEvalOptions", func(t *testing.T) {
+		c, err := New(WithEvalOptions(platform.WithDefaultTimeout(time.Second)))
+		require.NoError(t, err)
+		require.Len(t, c.evalOptions, 1)
+
+		exe, err := c.compile([]byte(`x = 1`))
+		require.NoError(t, err)
+		opts := platform.NewEvalOptions(exe.GetEvalOptions()...)
+		require.Equal(t, time.Second, opts.DefaultTimeout)
+	})
+
+	t.Run("WithHostFunctions", func(t *testing.T) {
+		double, err := platform.NewHostFunction(
+			"double",
+			func(ctx context.Context, n int) (int, error) { return n * 2, nil },
+		)
+		require.NoError(t, err)
+
+		t.Run("adds functions", func(t *testing.T) {
+			c := &Compiler{}
+			require.NoError(t, WithHostFunctions(double)(c))
+			require.Equal(t, []*platform.HostFunction{double}, c.hostFunctions)
+		})
+
+		t.Run("nil function", func(t *testing.T) {
+			c := &Compiler{}
+			err := WithHostFunctions(nil)(c)
+			require.Error(t, err)
+		})
+
+		t.Run("duplicate name", func(t *testing.T) {
+			c := &Compiler{}
+			require.NoError(t, WithHostFunctions(double)(c))
+			err := WithHostFunctions(double)(c)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "duplicate host function")
+		})
+
+		t.Run("names are compile globals and functions are set on the executable", func(t *testing.T) {
+			c, err := New(WithHostFunctions(double), WithGlobals([]string{constants.Ctx}))
+			require.NoError(t, err)
+
+			exe, err := c.compile([]byte(`result = double(21)`))
+			require.NoError(t, err)
+			require.Equal(t, []*platform.HostFunction{double}, exe.GetHostFunctions())
+		})
+
+		t.Run("undefined without the option", func(t *testing.T) {
+			c, err := New()
+			require.NoError(t, err)
+
+			_, err = c.compile([]byte(`result = double(21)`))
+			require.Error(t, err)
+		})
+	})
+
 	t.Run("Logger", func(t *testing.T) {
 		t.Run("default initialization", func(t *testing.T) {
 			c, err := New()
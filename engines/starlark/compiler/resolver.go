@@ -0,0 +1,86 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+)
+
+// ModuleResolver finds the source of the modules Starlark scripts import with load(). Module
+// names are the strings passed to load(), e.g. "lib/helpers.star".
+type ModuleResolver interface {
+	ResolveModule(module string) ([]byte, error)
+}
+
+// ModuleResolverFunc adapts a function into a ModuleResolver
+type ModuleResolverFunc func(module string) ([]byte, error)
+
+// ResolveModule calls the function
+func (f ModuleResolverFunc) ResolveModule(module string) ([]byte, error) {
+	return f(module)
+}
+
+// fsResolver reads modules from a file system
+type fsResolver struct {
+	fsys fs.FS
+}
+
+// NewFSResolver creates a resolver that reads modules from a file system, using the module
+// name as the path. Names must be valid fs paths, so modules can't be read from outside fsys.
+func NewFSResolver(fsys fs.FS) ModuleResolver {
+	return &fsResolver{fsys: fsys}
+}
+
+// NewDirResolver creates a resolver that reads modules from a directory, using the module name
+// as the path relative to dir.
+func NewDirResolver(dir string) ModuleResolver {
+	return NewFSResolver(os.DirFS(dir))
+}
+
+// ResolveModule reads the module from the file system
+func (r *fsResolver) ResolveModule(module string) ([]byte, error) {
+	if !fs.ValidPath(module) {
+		return nil, fmt.Errorf("invalid module path %q", module)
+	}
+	return fs.ReadFile(r.fsys, module)
+}
+
+// loaderResolver reads modules from the loaders returned by a function
+type loaderResolver struct {
+	newLoader func(module string) (loader.Loader, error)
+}
+
+// NewLoaderResolver creates a resolver that reads each module from the loader returned by
+// newLoader, e.g. an HTTP loader for a URL built from the module name.
+func NewLoaderResolver(newLoader func(module string) (loader.Loader, error)) ModuleResolver {
+	return &loaderResolver{newLoader: newLoader}
+}
+
+// ResolveModule reads the module from its loader
+func (r *loaderResolver) ResolveModule(module string) ([]byte, error) {
+	ldr, err := r.newLoader(module)
+	if err != nil {
+		return nil, err
+	}
+	if ldr == nil {
+		return nil, fmt.Errorf("no loader for module %q", module)
+	}
+
+	reader, err := ldr.GetReader()
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := io.ReadAll(reader)
+	if closeErr := reader.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module %q: %w", module, err)
+	}
+	return src, nil
+}
@@ -0,0 +1,138 @@
+package compiler
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+	"github.com/stretchr/testify/require"
+)
+
+const helperModule = "def greet(name):\n    return \"Hello, \" + name\n"
+
+func TestModuleResolvers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "lib"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "helpers.star"), []byte(helperModule), 0o644))
+
+	fsys := fstest.MapFS{"lib/helpers.star": &fstest.MapFile{Data: []byte(helperModule)}}
+
+	loaderResolver := NewLoaderResolver(func(module string) (loader.Loader, error) {
+		if module != "lib/helpers.star" {
+			return nil, fs.ErrNotExist
+		}
+		return loader.NewFromBytes([]byte(helperModule))
+	})
+
+	resolvers := map[string]ModuleResolver{
+		"dir":    NewDirResolver(dir),
+		"fs":     NewFSResolver(fsys),
+		"loader": loaderResolver,
+		"func": ModuleResolverFunc(func(module string) ([]byte, error) {
+			return fsys.ReadFile(module)
+		}),
+	}
+
+	for name, resolver := range resolvers {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := resolver.ResolveModule("lib/helpers.star")
+			require.NoError(t, err)
+			require.Equal(t, helperModule, string(src))
+
+			_, err = resolver.ResolveModule("lib/missing.star")
+			require.ErrorIs(t, err, fs.ErrNotExist)
+		})
+	}
+
+	t.Run("paths outside the file system are rejected", func(t *testing.T) {
+		t.Parallel()
+		for _, module := range []string{"../secret.star", "/etc/passwd", "lib/../../x.star"} {
+			_, err := NewDirResolver(dir).ResolveModule(module)
+			require.Error(t, err, module)
+			require.Contains(t, err.Error(), "invalid module path")
+		}
+	})
+
+	t.Run("loader errors", func(t *testing.T) {
+		t.Parallel()
+
+		errLoader := errors.New("loader failed")
+		_, err := NewLoaderResolver(func(string) (loader.Loader, error) {
+			return nil, errLoader
+		}).ResolveModule("x.star")
+		require.ErrorIs(t, err, errLoader)
+
+		_, err = NewLoaderResolver(func(string) (loader.Loader, error) {
+			return nil, nil
+		}).ResolveModule("x.star")
+		require.Error(t, err)
+	})
+}
+
+func TestCompiler_CompileWithModules(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"lib/helpers.star": &fstest.MapFile{Data: []byte(helperModule)},
+		"lib/broken.star":  &fstest.MapFile{Data: []byte("def broken(:\n")},
+	}
+	scriptContent := []byte("load(\"lib/helpers.star\", \"greet\")\n\n_ = greet(\"World\")\n")
+
+	t.Run("modules are loaded at compile time", func(t *testing.T) {
+		c, err := New(WithModuleResolver(NewFSResolver(fsys)))
+		require.NoError(t, err)
+
+		exe, err := c.compile(scriptContent)
+		require.NoError(t, err)
+		require.NotNil(t, exe.GetModules())
+	})
+
+	t.Run("load without a resolver", func(t *testing.T) {
+		c, err := New()
+		require.NoError(t, err)
+
+		_, err = c.compile(scriptContent)
+		require.ErrorIs(t, err, ErrNoModuleResolver)
+		require.ErrorIs(t, err, ErrValidationFailed)
+	})
+
+	t.Run("missing module", func(t *testing.T) {
+		c, err := New(WithModuleResolver(NewFSResolver(fsys)))
+		require.NoError(t, err)
+
+		_, err = c.compile([]byte("load(\"lib/missing.star\", \"x\")\n"))
+		require.ErrorIs(t, err, ErrValidationFailed)
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("invalid module", func(t *testing.T) {
+		c, err := New(WithModuleResolver(NewFSResolver(fsys)))
+		require.NoError(t, err)
+
+		_, err = c.compile([]byte("load(\"lib/broken.star\", \"broken\")\n"))
+		require.ErrorIs(t, err, ErrValidationFailed)
+		require.Contains(t, err.Error(), "lib/broken.star")
+	})
+
+	t.Run("script without loads", func(t *testing.T) {
+		c, err := New()
+		require.NoError(t, err)
+
+		exe, err := c.compile([]byte("_ = 1 + 1\n"))
+		require.NoError(t, err)
+		require.Nil(t, exe.GetModules())
+	})
+
+	t.Run("nil resolver", func(t *testing.T) {
+		_, err := New(WithModuleResolver(nil))
+		require.Error(t, err)
+	})
+}
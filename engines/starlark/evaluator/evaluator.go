@@ -16,6 +16,11 @@ import (
 	starlarkLib "go.starlark.net/starlark"
 )
 
+// moduleContent is implemented by executable content compiled with a module resolver
+type moduleContent interface {
+	GetModules() *internal.ModuleCache
+}
+
 // Evaluator is an abstraction layer for evaluating code on the Starlark engine
 type Evaluator struct {
 	// universe is the global variable map for the Starlark engine
@@ -24,14 +29,19 @@ type Evaluator struct {
 	// execUnit contains the compiled script and data provider
 	execUnit *script.ExecutableUnit
 
+	// evalOpts configures evaluation, such as the default timeout
+	evalOpts platform.EvalOptions
+
 	logHandler slog.Handler
 	logger     *slog.Logger
 }
 
-// New creates a new Evaluator object
+// New creates a new Evaluator object. The options configure every call to Eval, e.g.
+// platform.WithDefaultTimeout.
 func New(
 	handler slog.Handler,
 	execUnit *script.ExecutableUnit,
+	opts ...platform.EvalOption,
 ) *Evaluator {
 	handler, logger := helpers.SetupLogger(handler, "starlark", "Evaluator")
 
@@ -45,6 +55,7 @@ func New(
 	return &Evaluator{
 		universe:   universe,
 		execUnit:   execUnit,
+		evalOpts:   platform.NewUnitEvalOptions(execUnit, opts...),
 		logHandler: handler,
 		logger:     logger,
 	}
@@ -84,6 +95,42 @@ func (be *Evaluator) prepareGlobals(
 	return mergedGlobals
 }
 
+// getLimits returns the resource limits of the compiled script, if it has any
+func (be *Evaluator) getLimits() platform.Limits {
+	if be.execUnit == nil {
+		return platform.Limits{}
+	}
+	limited, ok := be.execUnit.GetContent().(platform.LimitedContent)
+	if !ok {
+		return platform.Limits{}
+	}
+	return limited.GetLimits()
+}
+
+// getHostFunctions returns the host functions of the compiled script, if it has any
+func (be *Evaluator) getHostFunctions() []*platform.HostFunction {
+	if be.execUnit == nil {
+		return nil
+	}
+	withFuncs, ok := be.execUnit.GetContent().(platform.HostFunctionContent)
+	if !ok {
+		return nil
+	}
+	return withFuncs.GetHostFunctions()
+}
+
+// getModules returns the cache of modules the compiled script can load, if it has one
+func (be *Evaluator) getModules() *internal.ModuleCache {
+	if be.execUnit == nil {
+		return nil
+	}
+	withModules, ok := be.execUnit.GetContent().(moduleContent)
+	if !ok {
+		return nil
+	}
+	return withModules.GetModules()
+}
+
 // exec executes the bytecode with the provided globals
 func (be *Evaluator) exec(
 	ctx context.Context,
@@ -100,6 +147,17 @@ func (be *Evaluator) exec(
 			logger.InfoContext(ctx, msg, "starlark-thread", thread.Name)
 		},
 	}
+	internal.SetThreadContext(thread, ctx)
+
+	// Resolve load() statements from the module cache, when the script was compiled with one
+	if modules := be.getModules(); modules != nil {
+		thread.Load = modules.Load
+	}
+
+	// Stop the script after the maximum number of steps, when a limit is set
+	if maxSteps := be.getLimits().MaxSteps; maxSteps > 0 {
+		thread.SetMaxExecutionSteps(maxSteps)
+	}
 
 	// Set up cancellation from context using AfterFunc to avoid goroutine leak
 	// when context is never cancelled (e.g., context.Background())
@@ -113,6 +171,9 @@ func (be *Evaluator) exec(
 	execTime := time.Since(startTime)
 
 	if err != nil {
+		if ctxErr := platform.ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("starlark execution error: %w: %w", ctxErr, err)
+		}
 		return nil, fmt.Errorf("starlark execution error: %w", err)
 	}
 
@@ -137,6 +198,9 @@ func (be *Evaluator) exec(
 // Eval evaluates the loaded bytecode and passes the provided data into the Starlark engine
 func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
 	logger := be.logger.WithGroup("Eval")
+	ctx, cancel := be.evalOpts.EvalContext(ctx)
+	defer cancel()
+
 	if be.execUnit == nil {
 		return nil, fmt.Errorf("executable unit is nil")
 	}
@@ -178,6 +242,9 @@ func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, erro
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert input data: %w", err)
 	}
+	// Host functions are added to the input, so they're available as builtins
+	maps.Copy(input, internal.HostFunctionBuiltins(be.getHostFunctions()))
+
 	// Prepare globals by merging input with "universe"
 	runtimeData := be.prepareGlobals(input)
 
@@ -200,6 +267,7 @@ func (be *Evaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, erro
 	// Handle callable results (functions)
 	if callable, ok := result.Value.(starlarkLib.Callable); ok {
 		thread := &starlarkLib.Thread{Name: "func"}
+		internal.SetThreadContext(thread, ctx)
 		val, err := starlarkLib.Call(thread, callable, nil, nil)
 		if err != nil {
 			return nil, fmt.Errorf("error calling function: %w", err)
@@ -2,15 +2,19 @@ package evaluator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http/httptest"
 	"os"
 	"runtime"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/robbyt/go-polyscript/engines/starlark/compiler"
 	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/constants"
 	"github.com/robbyt/go-polyscript/platform/data"
 	"github.com/robbyt/go-polyscript/platform/script"
@@ -245,6 +249,265 @@ func TestEval_NoGoroutineLeak(t *testing.T) {
 	require.Less(t, growth, 5, "goroutine count grew by %d after 100 Eval() calls; suspected leak", growth)
 }
 
+// TestEvaluator_Limits tests that the step limit set at compile time stops the script
+func TestEvaluator_Limits(t *testing.T) {
+	t.Parallel()
+
+	scriptContent := `
+def spin(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total
+
+_ = spin(100000)
+`
+
+	newEvaluator := func(t *testing.T, limits platform.Limits) *Evaluator {
+		t.Helper()
+		ldr, err := loader.NewFromString(scriptContent)
+		require.NoError(t, err)
+
+		comp, err := compiler.New(compiler.WithCtxGlobal(), compiler.WithLimits(limits))
+		require.NoError(t, err)
+
+		exe, err := script.NewExecutableUnit(
+			nil,
+			"",
+			ldr,
+			comp,
+			data.NewContextProvider(constants.EvalData),
+		)
+		require.NoError(t, err)
+		return New(nil, exe)
+	}
+
+	t.Run("step limit exceeded", func(t *testing.T) {
+		evaluator := newEvaluator(t, platform.Limits{MaxSteps: 1000})
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "too many steps")
+	})
+
+	t.Run("within step limit", func(t *testing.T) {
+		evaluator := newEvaluator(t, platform.Limits{MaxSteps: 10_000_000})
+		response, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, int64(4999950000), response.Interface())
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		evaluator := newEvaluator(t, platform.Limits{})
+		_, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+	})
+}
+
+// TestEvaluator_HostFunctions tests that Go functions registered with the compiler can be called
+// from scripts, and receive the context of the evaluation
+func TestEvaluator_HostFunctions(t *testing.T) {
+	t.Parallel()
+
+	type tenantKey struct{}
+	type customer struct {
+		ID     string   `json:"id"`
+		Tenant string   `json:"tenant"`
+		Tags   []string `json:"tags"`
+		Score  float64  `json:"score"`
+	}
+
+	lookup, err := platform.NewHostFunction(
+		"lookup",
+		func(ctx context.Context, id string, tags []string) (customer, error) {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return customer{ID: id, Tenant: tenant, Tags: tags, Score: 1.5}, nil
+		},
+	)
+	require.NoError(t, err)
+	fail, err := platform.NewHostFunction("fail", func(ctx context.Context) error {
+		return errors.New("lookup service unavailable")
+	})
+	require.NoError(t, err)
+
+	newEvaluator := func(t *testing.T, scriptContent string) *Evaluator {
+		t.Helper()
+		ldr, err := loader.NewFromString(scriptContent)
+		require.NoError(t, err)
+
+		comp, err := compiler.New(
+			compiler.WithCtxGlobal(),
+			compiler.WithHostFunctions(lookup, fail),
+		)
+		require.NoError(t, err)
+
+		exe, err := script.NewExecutableUnit(
+			nil,
+			"",
+			ldr,
+			comp,
+			data.NewStaticProvider(map[string]any{"id": "c-42"}),
+		)
+		require.NoError(t, err)
+		return New(nil, exe)
+	}
+
+	t.Run("typed arguments and result", func(t *testing.T) {
+		evaluator := newEvaluator(t, `result = lookup(ctx["id"], ["gold", "beta"])`)
+
+		ctx := context.WithValue(t.Context(), tenantKey{}, "acme")
+		response, err := evaluator.Eval(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"id":     "c-42",
+			"tenant": "acme",
+			"tags":   []any{"gold", "beta"},
+			"score":  1.5,
+		}, response.Interface())
+	})
+
+	t.Run("function error", func(t *testing.T) {
+		evaluator := newEvaluator(t, `fail()`)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "lookup service unavailable")
+	})
+
+	t.Run("wrong number of arguments", func(t *testing.T) {
+		evaluator := newEvaluator(t, `lookup("c-1")`)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "takes 2 arguments, got 1")
+	})
+}
+
+// TestEvaluator_Modules tests that scripts can import modules with load(), that the modules are
+// shared by evaluations, and that they can call host functions
+func TestEvaluator_Modules(t *testing.T) {
+	t.Parallel()
+
+	type tenantKey struct{}
+	tenant, err := platform.NewHostFunction("tenant", func(ctx context.Context) (string, error) {
+		name, _ := ctx.Value(tenantKey{}).(string)
+		return name, nil
+	})
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"lib/greet.star": &fstest.MapFile{Data: []byte(`
+load("lib/punctuation.star", "punctuate")
+
+def greet(name):
+    return punctuate("Hello, " + name)
+
+names = []
+`)},
+		"lib/punctuation.star": &fstest.MapFile{Data: []byte(`
+def punctuate(s):
+    return s + "!"
+`)},
+		"lib/tenant.star": &fstest.MapFile{Data: []byte(`
+def qualify(name):
+    return tenant() + "/" + name
+`)},
+	}
+
+	newEvaluator := func(t *testing.T, scriptContent string) *Evaluator {
+		t.Helper()
+		ldr, err := loader.NewFromString(scriptContent)
+		require.NoError(t, err)
+
+		comp, err := compiler.New(
+			compiler.WithCtxGlobal(),
+			compiler.WithModuleResolver(compiler.NewFSResolver(fsys)),
+			compiler.WithHostFunctions(tenant),
+		)
+		require.NoError(t, err)
+
+		exe, err := script.NewExecutableUnit(
+			nil,
+			"",
+			ldr,
+			comp,
+			data.NewStaticProvider(map[string]any{"name": "World"}),
+		)
+		require.NoError(t, err)
+		return New(nil, exe)
+	}
+
+	t.Run("loaded functions", func(t *testing.T) {
+		evaluator := newEvaluator(t, `
+load("lib/greet.star", "greet")
+
+_ = greet(ctx["name"])
+`)
+		for range 3 {
+			response, err := evaluator.Eval(t.Context())
+			require.NoError(t, err)
+			require.Equal(t, "Hello, World!", response.Interface())
+		}
+	})
+
+	t.Run("module globals are frozen", func(t *testing.T) {
+		evaluator := newEvaluator(t, `
+load("lib/greet.star", "names")
+
+names.append(ctx["name"])
+`)
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "frozen")
+	})
+
+	t.Run("modules call host functions", func(t *testing.T) {
+		evaluator := newEvaluator(t, `
+load("lib/tenant.star", "qualify")
+
+_ = qualify(ctx["name"])
+`)
+		ctx := context.WithValue(t.Context(), tenantKey{}, "acme")
+		response, err := evaluator.Eval(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "acme/World", response.Interface())
+	})
+}
+
+// TestEvaluator_ContextErrors tests that a script stopped by its context returns an error
+// wrapping the platform timeout or cancellation error
+func TestEvaluator_ContextErrors(t *testing.T) {
+	t.Parallel()
+
+	scriptContent := `
+def spin():
+    for i in range(1 << 40):
+        pass
+
+spin()
+`
+
+	t.Run("default timeout", func(t *testing.T) {
+		exe, _ := evalBuilder(t, scriptContent)
+		evaluator := New(nil, exe, platform.WithDefaultTimeout(50*time.Millisecond))
+
+		_, err := evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		_, evaluator := evalBuilder(t, scriptContent)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		defer cancel()
+
+		_, err := evaluator.Eval(ctx)
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrCanceled)
+		require.Contains(t, err.Error(), "starlark execution error")
+	})
+}
+
 // TestEvaluator_AddDataToContext tests the AddDataToContext method with various scenarios
 func TestEvaluator_AddDataToContext(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robbyt/go-polyscript/platform"
+	starlarkLib "go.starlark.net/starlark"
+)
+
+// contextLocalKey is the thread local holding the context of the evaluation
+const contextLocalKey = "polyscript.context"
+
+// SetThreadContext stores the context of the evaluation on a thread, so host functions called
+// by the thread receive it
+func SetThreadContext(thread *starlarkLib.Thread, ctx context.Context) {
+	thread.SetLocal(contextLocalKey, ctx)
+}
+
+// threadContext returns the context stored on the thread, or context.Background() if none is
+func threadContext(thread *starlarkLib.Thread) context.Context {
+	if ctx, ok := thread.Local(contextLocalKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// NewBuiltin adapts a host function into a Starlark builtin. It takes positional arguments,
+// which are converted to Go values before the call, and the result is converted back to a
+// Starlark value.
+func NewBuiltin(fn *platform.HostFunction) *starlarkLib.Builtin {
+	return starlarkLib.NewBuiltin(
+		fn.Name(),
+		func(
+			thread *starlarkLib.Thread,
+			b *starlarkLib.Builtin,
+			args starlarkLib.Tuple,
+			kwargs []starlarkLib.Tuple,
+		) (starlarkLib.Value, error) {
+			if len(kwargs) > 0 {
+				return nil, fmt.Errorf("%s: unexpected keyword arguments", b.Name())
+			}
+
+			goArgs := make([]any, len(args))
+			for i, arg := range args {
+				v, err := ConvertStarlarkValueToInterface(arg)
+				if err != nil {
+					return nil, fmt.Errorf("%s: argument %d: %w", b.Name(), i+1, err)
+				}
+				goArgs[i] = v
+			}
+
+			result, err := fn.Call(threadContext(thread), goArgs...)
+			if err != nil {
+				return nil, err
+			}
+			return ConvertToStarlarkValue(result)
+		},
+	)
+}
+
+// HostFunctionBuiltins returns the host functions as Starlark builtins, keyed by name
+func HostFunctionBuiltins(funcs []*platform.HostFunction) starlarkLib.StringDict {
+	builtins := make(starlarkLib.StringDict, len(funcs))
+	for _, fn := range funcs {
+		builtins[fn.Name()] = NewBuiltin(fn)
+	}
+	return builtins
+}
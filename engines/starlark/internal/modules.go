@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/robbyt/go-polyscript/platform"
+	starlarkLib "go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// loadChainLocalKey is the thread local holding the modules being loaded by the thread and its
+// parents, used to detect import cycles
+const loadChainLocalKey = "polyscript.loadChain"
+
+// ResolveFunc returns the source of a module loaded with load()
+type ResolveFunc func(module string) ([]byte, error)
+
+// ModuleCache loads the modules imported by Starlark scripts with load(). Each module is run
+// once with the universe from StarlarkModules and the script's host functions, and its frozen
+// globals are shared by every script that loads it. It's safe for concurrent use by several
+// threads.
+type ModuleCache struct {
+	resolve  ResolveFunc
+	universe starlarkLib.StringDict
+	maxSteps uint64
+
+	mu      sync.RWMutex
+	modules map[string]starlarkLib.StringDict
+}
+
+// NewModuleCache creates a cache that finds module sources with resolve. Modules can call the
+// host functions, like the scripts that load them. A maxSteps above zero limits the execution
+// steps of each module when it's first loaded.
+func NewModuleCache(
+	resolve ResolveFunc,
+	hostFunctions []*platform.HostFunction,
+	maxSteps uint64,
+) *ModuleCache {
+	universe := StarlarkModules()
+	maps.Copy(universe, HostFunctionBuiltins(hostFunctions))
+	return &ModuleCache{
+		resolve:  resolve,
+		universe: universe,
+		maxSteps: maxSteps,
+		modules:  make(map[string]starlarkLib.StringDict),
+	}
+}
+
+// Load returns the globals of a module, running it first if it isn't cached. It has the
+// signature of starlark.Thread.Load.
+func (c *ModuleCache) Load(
+	thread *starlarkLib.Thread,
+	module string,
+) (starlarkLib.StringDict, error) {
+	if globals, ok := c.get(module); ok {
+		return globals, nil
+	}
+
+	chain, _ := thread.Local(loadChainLocalKey).([]string)
+	if slices.Contains(chain, module) {
+		return nil, fmt.Errorf(
+			"import cycle: %s",
+			strings.Join(append(slices.Clone(chain), module), " -> "),
+		)
+	}
+
+	src, err := c.resolve(module)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve module %q: %w", module, err)
+	}
+
+	ctx := threadContext(thread)
+	child := &starlarkLib.Thread{
+		Name:  "load " + module,
+		Print: thread.Print,
+		Load:  c.Load,
+	}
+	child.SetLocal(loadChainLocalKey, append(slices.Clone(chain), module))
+	SetThreadContext(child, ctx)
+	if c.maxSteps > 0 {
+		child.SetMaxExecutionSteps(c.maxSteps)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		child.Cancel(ctx.Err().Error())
+	})
+	defer stop()
+
+	opts := &syntax.FileOptions{GlobalReassign: true}
+	globals, err := starlarkLib.ExecFileOptions(opts, child, module, src, c.universe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load module %q: %w", module, err)
+	}
+	globals.Freeze()
+
+	return c.store(module, globals), nil
+}
+
+// Preload loads the modules, so missing or invalid modules are found before a script runs
+func (c *ModuleCache) Preload(ctx context.Context, modules ...string) error {
+	thread := &starlarkLib.Thread{Name: "preload"}
+	SetThreadContext(thread, ctx)
+	for _, module := range modules {
+		if _, err := c.Load(thread, module); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns the globals of a cached module
+func (c *ModuleCache) get(module string) (starlarkLib.StringDict, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	globals, ok := c.modules[module]
+	return globals, ok
+}
+
+// store caches the globals of a module, and returns the cached globals. When threads load the
+// same module concurrently, the first one stored is kept, so every script shares it.
+func (c *ModuleCache) store(
+	module string,
+	globals starlarkLib.StringDict,
+) starlarkLib.StringDict {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.modules[module]; ok {
+		return cached
+	}
+	c.modules[module] = globals
+	return globals
+}
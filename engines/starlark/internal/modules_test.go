@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/stretchr/testify/require"
+	starlarkLib "go.starlark.net/starlark"
+)
+
+// mapResolver resolves modules from a map, and counts how often each module is resolved
+type mapResolver struct {
+	mu      sync.Mutex
+	sources map[string]string
+	calls   map[string]int
+}
+
+func newMapResolver(sources map[string]string) *mapResolver {
+	return &mapResolver{sources: sources, calls: make(map[string]int)}
+}
+
+func (r *mapResolver) resolve(module string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[module]++
+	src, ok := r.sources[module]
+	if !ok {
+		return nil, fmt.Errorf("module %q not found", module)
+	}
+	return []byte(src), nil
+}
+
+func (r *mapResolver) callCount(module string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[module]
+}
+
+func TestModuleCache_Load(t *testing.T) {
+	t.Parallel()
+
+	sources := map[string]string{
+		"math.star":  "def double(n):\n    return n * 2\n\nprimes = [2, 3, 5]\n",
+		"util.star":  "load(\"math.star\", \"double\")\n\ndef quadruple(n):\n    return double(double(n))\n",
+		"json.star":  "encoded = json.encode({\"a\": 1})\n",
+		"a.star":     "load(\"b.star\", \"b\")\na = 1\n",
+		"b.star":     "load(\"c.star\", \"c\")\nb = 2\n",
+		"c.star":     "load(\"a.star\", \"a\")\nc = 3\n",
+		"self.star":  "load(\"self.star\", \"x\")\nx = 1\n",
+		"bad.star":   "def broken(:\n",
+		"fail.star":  "fail(\"module failed\")\n",
+		"spin.star":  "def spin():\n    for i in range(1 << 40):\n        pass\n\nspin()\n",
+		"count.star": "def count():\n    for i in range(1000):\n        pass\n\ncount()\n",
+	}
+
+	newThread := func() *starlarkLib.Thread {
+		return &starlarkLib.Thread{Name: "test"}
+	}
+
+	t.Run("loads and caches modules", func(t *testing.T) {
+		resolver := newMapResolver(sources)
+		cache := NewModuleCache(resolver.resolve, nil, 0)
+
+		globals, err := cache.Load(newThread(), "math.star")
+		require.NoError(t, err)
+		require.Contains(t, globals, "double")
+
+		again, err := cache.Load(newThread(), "math.star")
+		require.NoError(t, err)
+		require.Equal(t, globals, again)
+		require.Equal(t, 1, resolver.callCount("math.star"))
+	})
+
+	t.Run("loaded globals are frozen", func(t *testing.T) {
+		cache := NewModuleCache(newMapResolver(sources).resolve, nil, 0)
+
+		globals, err := cache.Load(newThread(), "math.star")
+		require.NoError(t, err)
+		primes, ok := globals["primes"].(*starlarkLib.List)
+		require.True(t, ok)
+		require.Error(t, primes.Append(starlarkLib.MakeInt(7)))
+	})
+
+	t.Run("nested loads share the cache", func(t *testing.T) {
+		resolver := newMapResolver(sources)
+		cache := NewModuleCache(resolver.resolve, nil, 0)
+
+		globals, err := cache.Load(newThread(), "util.star")
+		require.NoError(t, err)
+
+		thread := newThread()
+		result, err := starlarkLib.Call(
+			thread,
+			globals["quadruple"],
+			starlarkLib.Tuple{starlarkLib.MakeInt(3)},
+			nil,
+		)
+		require.NoError(t, err)
+		require.Equal(t, starlarkLib.MakeInt(12), result)
+
+		_, err = cache.Load(thread, "math.star")
+		require.NoError(t, err)
+		require.Equal(t, 1, resolver.callCount("math.star"))
+	})
+
+	t.Run("modules use the starlark universe", func(t *testing.T) {
+		cache := NewModuleCache(newMapResolver(sources).resolve, nil, 0)
+
+		globals, err := cache.Load(newThread(), "json.star")
+		require.NoError(t, err)
+		require.Equal(t, starlarkLib.String(`{"a":1}`), globals["encoded"])
+	})
+
+	t.Run("modules call host functions", func(t *testing.T) {
+		double, err := platform.NewHostFunction(
+			"double",
+			func(ctx context.Context, n int) (int, error) { return n * 2, nil },
+		)
+		require.NoError(t, err)
+		hostSources := map[string]string{"host.star": "doubled = double(21)\n"}
+
+		cache := NewModuleCache(newMapResolver(hostSources).resolve, nil, 0)
+		_, err = cache.Load(newThread(), "host.star")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "undefined: double")
+
+		cache = NewModuleCache(newMapResolver(hostSources).resolve, []*platform.HostFunction{double}, 0)
+		globals, err := cache.Load(newThread(), "host.star")
+		require.NoError(t, err)
+		require.Equal(t, starlarkLib.MakeInt(42), globals["doubled"])
+	})
+
+	t.Run("import cycles", func(t *testing.T) {
+		cache := NewModuleCache(newMapResolver(sources).resolve, nil, 0)
+
+		_, err := cache.Load(newThread(), "a.star")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import cycle: a.star -> b.star -> c.star -> a.star")
+
+		_, err = cache.Load(newThread(), "self.star")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import cycle: self.star -> self.star")
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		resolver := newMapResolver(sources)
+		cache := NewModuleCache(resolver.resolve, nil, 0)
+
+		for _, module := range []string{"missing.star", "bad.star", "fail.star"} {
+			_, err := cache.Load(newThread(), module)
+			require.Error(t, err, module)
+			_, err = cache.Load(newThread(), module)
+			require.Error(t, err, module)
+			require.Equal(t, 2, resolver.callCount(module), module)
+		}
+	})
+
+	t.Run("step limit", func(t *testing.T) {
+		cache := NewModuleCache(newMapResolver(sources).resolve, nil, 100)
+		_, err := cache.Load(newThread(), "count.star")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "too many steps")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		cache := NewModuleCache(newMapResolver(sources).resolve, nil, 0)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		err := cache.Preload(ctx, "spin.star")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), context.Canceled.Error())
+	})
+
+	t.Run("concurrent loads", func(t *testing.T) {
+		cache := NewModuleCache(newMapResolver(sources).resolve, nil, 0)
+
+		var wg sync.WaitGroup
+		var failures atomic.Int32
+		results := make([]starlarkLib.StringDict, 8)
+		for i := range results {
+			wg.Go(func() {
+				globals, err := cache.Load(newThread(), "util.star")
+				if err != nil {
+					failures.Add(1)
+					return
+				}
+				results[i] = globals
+			})
+		}
+		wg.Wait()
+
+		require.Zero(t, failures.Load())
+		for _, globals := range results {
+			require.Equal(t, results[0], globals)
+		}
+	})
+
+	t.Run("preload stops at the first error", func(t *testing.T) {
+		resolver := newMapResolver(sources)
+		cache := NewModuleCache(resolver.resolve, nil, 0)
+
+		err := cache.Preload(t.Context(), "math.star", "missing.star", "json.star")
+		require.Error(t, err)
+		require.Equal(t, 0, resolver.callCount("json.star"))
+	})
+}
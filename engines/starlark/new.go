@@ -3,6 +3,7 @@ package starlark
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/robbyt/go-polyscript/engines/starlark/compiler"
 	"github.com/robbyt/go-polyscript/engines/starlark/evaluator"
@@ -17,16 +18,19 @@ import (
 // Input parameters:
 // - logHandler: logger handler for logging
 // - ldr: loader implementation for loading the Starlark script content
+// - opts: optional compiler options, such as WithLimits
 //
 // Returns an evaluator, which implements the evaluation.Evaluator interface.
 func FromStarlarkLoader(
 	logHandler slog.Handler,
 	ldr loader.Loader,
+	opts ...compiler.FunctionalOption,
 ) (*evaluator.Evaluator, error) {
 	return NewEvaluator(
 		logHandler,
 		ldr,
 		data.NewContextProvider(constants.EvalData),
+		opts...,
 	)
 }
 
@@ -36,12 +40,14 @@ func FromStarlarkLoader(
 // - logHandler: logger handler for logging
 // - ldr: loader implementation for loading the Starlark script content
 // - staticData: map of initial static data to be passed to the script
+// - opts: optional compiler options, such as WithLimits
 //
 // Returns an evaluator, which implements the evaluation.Evaluator interface.
 func FromStarlarkLoaderWithData(
 	logHandler slog.Handler,
 	ldr loader.Loader,
 	staticData map[string]any,
+	opts ...compiler.FunctionalOption,
 ) (*evaluator.Evaluator, error) {
 	staticProvider := data.NewStaticProvider(staticData)
 	dynamicProvider := data.NewContextProvider(constants.EvalData)
@@ -52,6 +58,7 @@ func FromStarlarkLoaderWithData(
 		logHandler,
 		ldr,
 		compositeProvider,
+		opts...,
 	)
 }
 
@@ -62,17 +69,20 @@ func NewCompiler(opts ...compiler.FunctionalOption) (*compiler.Compiler, error)
 }
 
 // NewEvaluator creates a Starlark evaluator with bytecode loaded, and ready for execution.
+// The ctx global is declared in addition to any globals set by opts.
 // Returns a Evaluator, which implements the evaluation.Evaluator interface.
 func NewEvaluator(
 	logHandler slog.Handler,
 	ldr loader.Loader,
 	dataProvider data.Provider,
+	opts ...compiler.FunctionalOption,
 ) (*evaluator.Evaluator, error) {
 	if dataProvider == nil {
 		return nil, fmt.Errorf("provider is nil")
 	}
 
-	compiler, err := NewCompiler(compiler.WithCtxGlobal())
+	compilerOpts := append(slices.Clone(opts), compiler.WithCtxGlobal())
+	compiler, err := NewCompiler(compilerOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Starlark compiler: %w", err)
 	}
@@ -1,16 +1,18 @@
-//go:generate go run ./gen/typeGen.go
-// Code generated by go generate; DO NOT EDIT.
-
 package types
 
 import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 )
 
-var ErrInvalidMachineType = errors.New("invalid machine type")
+var (
+	ErrInvalidMachineType = errors.New("invalid machine type")
+	ErrExtensionConflict  = errors.New("file extension already registered")
+)
 
 type Type string
 
@@ -18,33 +20,132 @@ func (t Type) String() string {
 	return string(t)
 }
 
+// Unsupported is a placeholder that can't be registered as a machine type
+const Unsupported Type = "unsupported"
+
+// The machine types below are declared by their engine packages, and are only registered when
+// the engine is imported. They're kept so existing code still compiles.
 const (
-	Unsupported Type = "unsupported"
 	// Risor engine: https://github.com/risor-io/risor
+	//
+	// Deprecated: use the MachineType constant of engines/risor/compiler.
 	Risor Type = "risor"
 	// Starlark engine: https://github.com/google/starlark-go
+	//
+	// Deprecated: use the MachineType constant of engines/starlark/compiler.
 	Starlark Type = "starlark"
 	// Extism WASM engine: https://extism.org/
+	//
+	// Deprecated: use the MachineType constant of engines/extism/compiler.
 	Extism Type = "extism"
 )
 
+// registry tracks the known machine types and the file extensions that map to them. Each engine
+// package declares its own machine type, and adds it with Register, usually through
+// registry.Register from an init function, so only the engines that have been imported are known.
+var registry = struct {
+	sync.RWMutex
+	types      map[Type]struct{}
+	extensions map[string]Type
+}{
+	types:      make(map[Type]struct{}),
+	extensions: make(map[string]Type),
+}
+
+// Register adds a machine type, and the file extensions used by its scripts, to the set of
+// known types. Extensions may be given with or without a leading dot, and are case
+// insensitive. Registering a type that is already known adds any new extensions to it.
+// An error is returned when an extension is already claimed by a different type.
+func Register(t Type, extensions ...string) error {
+	name := normalize(string(t))
+	if name == "" || Type(name) == Unsupported {
+		return fmt.Errorf("%w: %q", ErrInvalidMachineType, t)
+	}
+	t = Type(name)
+
+	exts := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = normalize(ext)
+		if ext == "" {
+			return fmt.Errorf("%w: empty file extension for %s", ErrInvalidMachineType, t)
+		}
+		exts = append(exts, ext)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, ext := range exts {
+		if owner, ok := registry.extensions[ext]; ok && owner != t {
+			return fmt.Errorf("%w: .%s is used by %s", ErrExtensionConflict, ext, owner)
+		}
+	}
+
+	registry.types[t] = struct{}{}
+	for _, ext := range exts {
+		registry.extensions[ext] = t
+	}
+	return nil
+}
+
+// IsRegistered reports whether the machine type has been registered.
+func IsRegistered(t Type) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+	_, ok := registry.types[t]
+	return ok
+}
+
+// Registered returns all known machine types, sorted by name.
+func Registered() []Type {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	out := make([]Type, 0, len(registry.types))
+	for t := range registry.types {
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Extensions returns every registered file extension, with a leading dot, sorted.
+func Extensions() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	out := make([]string, 0, len(registry.extensions))
+	for ext := range registry.extensions {
+		out = append(out, "."+ext)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// GetMachineTypeFromString resolves a machine type from its name, or from one of its
+// registered file extensions.
 func GetMachineTypeFromString(machineType string) (Type, error) {
-	machineType = strings.ToLower(strings.TrimSpace(machineType))
-	machineType = strings.TrimPrefix(machineType, ".")
+	machineType = normalize(machineType)
+
+	registry.RLock()
+	defer registry.RUnlock()
 
-	switch machineType {
-	case "risor":
-		return Risor, nil
-	case "starlark":
-		return Starlark, nil
-	case "extism":
-		return Extism, nil
-	default:
-		return "", fmt.Errorf("%w: %s", ErrInvalidMachineType, machineType)
+	if _, ok := registry.types[Type(machineType)]; ok {
+		return Type(machineType), nil
 	}
+	if t, ok := registry.extensions[machineType]; ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidMachineType, machineType)
 }
 
 func GetMachineTypeFromPath(path string) (Type, error) {
 	ext := strings.TrimSpace(strings.ToLower(strings.TrimSpace(filepath.Ext(path))))
 	return GetMachineTypeFromString(ext)
 }
+
+// normalize lowercases and trims a type name or extension, and removes any leading dot.
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.TrimPrefix(s, ".")
+}
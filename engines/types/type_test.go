@@ -1,5 +1,3 @@
-// Code generated by go generate; DO NOT EDIT.
-
 package types
 
 import (
@@ -8,6 +6,31 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// The engine packages declare and register their own machine types, so these tests register
+// stand-ins for them, along with the deprecated Risor, Starlark, and Extism types.
+const (
+	JavaScript Type = "javascript"
+	Lua        Type = "lua"
+	CEL        Type = "cel"
+	Expr       Type = "expr"
+)
+
+func init() {
+	for t, exts := range map[Type][]string{
+		Risor:      {"risor"},
+		Starlark:   {"star", "starlark"},
+		Extism:     {"wasm"},
+		JavaScript: {"js"},
+		Lua:        {"lua"},
+		CEL:        {"cel"},
+		Expr:       {"expr"},
+	} {
+		if err := Register(t, exts...); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func TestGetMachineTypeFromString(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -33,6 +56,30 @@ func TestGetMachineTypeFromString(t *testing.T) {
 			expected:    Extism,
 			expectError: false,
 		},
+		{
+			name:        "valid machine type JavaScript",
+			input:       "JavaScript",
+			expected:    JavaScript,
+			expectError: false,
+		},
+		{
+			name:        "valid machine type Lua",
+			input:       "Lua",
+			expected:    Lua,
+			expectError: false,
+		},
+		{
+			name:        "valid machine type CEL",
+			input:       "CEL",
+			expected:    CEL,
+			expectError: false,
+		},
+		{
+			name:        "valid machine type Expr",
+			input:       "expr",
+			expected:    Expr,
+			expectError: false,
+		},
 		{
 			name:        "invalid machine type",
 			input:       "invalid",
@@ -104,6 +151,42 @@ func TestGetMachineTypeFromPath(t *testing.T) {
 			expected:    Extism,
 			expectError: false,
 		},
+		{
+			name:        "valid file extension .star",
+			input:       "example.star",
+			expected:    Starlark,
+			expectError: false,
+		},
+		{
+			name:        "valid file extension .wasm",
+			input:       "example.wasm",
+			expected:    Extism,
+			expectError: false,
+		},
+		{
+			name:        "valid file extension .js",
+			input:       "example.js",
+			expected:    JavaScript,
+			expectError: false,
+		},
+		{
+			name:        "valid file extension .lua",
+			input:       "example.lua",
+			expected:    Lua,
+			expectError: false,
+		},
+		{
+			name:        "valid file extension .cel",
+			input:       "policy.cel",
+			expected:    CEL,
+			expectError: false,
+		},
+		{
+			name:        "valid file extension .expr",
+			input:       "pricing.expr",
+			expected:    Expr,
+			expectError: false,
+		},
 		{
 			name:        "invalid file extension .invalid",
 			input:       "example.invalid",
@@ -131,3 +214,48 @@ func TestGetMachineTypeFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestRegister(t *testing.T) {
+	t.Run("new type with extensions", func(t *testing.T) {
+		custom := Type("registertest")
+		require.NoError(t, Register(custom, ".rtest", "RTEST2"))
+		require.True(t, IsRegistered(custom))
+		require.Contains(t, Registered(), custom)
+		require.Contains(t, Extensions(), ".rtest")
+		require.Contains(t, Extensions(), ".rtest2")
+
+		result, err := GetMachineTypeFromPath("/scripts/policy.rtest2")
+		require.NoError(t, err)
+		require.Equal(t, custom, result)
+
+		result, err = GetMachineTypeFromString("RegisterTest")
+		require.NoError(t, err)
+		require.Equal(t, custom, result)
+	})
+
+	t.Run("re-registering adds extensions", func(t *testing.T) {
+		custom := Type("registertest-merge")
+		require.NoError(t, Register(custom, "merge1"))
+		require.NoError(t, Register(custom, "merge1", "merge2"))
+
+		result, err := GetMachineTypeFromPath("example.merge2")
+		require.NoError(t, err)
+		require.Equal(t, custom, result)
+	})
+
+	t.Run("extension owned by another type", func(t *testing.T) {
+		err := Register(Type("registertest-conflict"), "risor")
+		require.ErrorIs(t, err, ErrExtensionConflict)
+		require.False(t, IsRegistered(Type("registertest-conflict")))
+	})
+
+	t.Run("invalid type names", func(t *testing.T) {
+		require.ErrorIs(t, Register(""), ErrInvalidMachineType)
+		require.ErrorIs(t, Register(Unsupported), ErrInvalidMachineType)
+	})
+
+	t.Run("empty extension", func(t *testing.T) {
+		err := Register(Type("registertest-empty"), ".")
+		require.ErrorIs(t, err, ErrInvalidMachineType)
+	})
+}
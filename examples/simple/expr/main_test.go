@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExprExample(t *testing.T) {
+	result, err := runExprExample(nil)
+	require.NoError(t, err, "runExprExample should not return an error")
+	require.NotNil(t, result, "Result should not be nil")
+
+	greeting := result["greeting"]
+	require.IsType(t, "", greeting, "Greeting should be a string")
+	assert.Equal(t, "Hello, World!", greeting, "Should have the correct greeting")
+
+	length := result["length"]
+	require.IsType(t, 0, length, "Length should be int")
+	assert.Equal(t, 13, length, "Should have the correct length")
+}
+
+func TestRun(t *testing.T) {
+	err := run()
+	require.NoError(t, err, "run() should execute without error")
+}
@@ -0,0 +1,9 @@
+// Script has access to ctx variable passed from Go
+const name = ctx.name;
+const message = "Hello, " + name + "!";
+
+// The value of the last expression is returned
+({
+    greeting: message,
+    length: message.length,
+});
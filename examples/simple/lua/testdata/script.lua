@@ -0,0 +1,9 @@
+-- Script has access to ctx variable passed from Go
+local name = ctx["name"]
+local message = "Hello, " .. name .. "!"
+
+-- Return a table with our result
+return {
+    greeting = message,
+    length = #message,
+}
@@ -0,0 +1,31 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTimeout is wrapped by evaluation errors when the context's deadline passed before the
+	// script finished, including the deadline set by an evaluator's default timeout.
+	ErrTimeout = errors.New("evaluation timed out")
+
+	// ErrCanceled is wrapped by evaluation errors when the context was canceled before the
+	// script finished.
+	ErrCanceled = errors.New("evaluation canceled")
+)
+
+// ContextError returns an error wrapping ErrTimeout or ErrCanceled, and the context's own
+// error, when the context is done. It returns nil while the context is still active.
+func ContextError(ctx context.Context) error {
+	err := ctx.Err()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	}
+}
@@ -0,0 +1,39 @@
+package platform_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("active context", func(t *testing.T) {
+		require.NoError(t, platform.ContextError(t.Context()))
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := platform.ContextError(ctx)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NotErrorIs(t, err, platform.ErrCanceled)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err := platform.ContextError(ctx)
+		require.ErrorIs(t, err, platform.ErrCanceled)
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, platform.ErrTimeout)
+	})
+}
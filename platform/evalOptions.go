@@ -0,0 +1,72 @@
+package platform
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/robbyt/go-polyscript/platform/script"
+)
+
+// EvalOptions configures how an evaluator runs scripts. The evaluators of all engines accept
+// EvalOption values when they're created.
+type EvalOptions struct {
+	// DefaultTimeout bounds each call to Eval when the caller's context has no deadline.
+	// Zero disables the default timeout.
+	DefaultTimeout time.Duration
+}
+
+// EvalOption is a functional option for EvalOptions
+type EvalOption func(*EvalOptions)
+
+// WithDefaultTimeout sets a timeout for each call to Eval. It only applies when the caller's
+// context has no deadline of its own, so a caller can still choose a shorter or longer one.
+// A script stopped by the timeout returns an error wrapping ErrTimeout.
+func WithDefaultTimeout(timeout time.Duration) EvalOption {
+	return func(o *EvalOptions) {
+		o.DefaultTimeout = timeout
+	}
+}
+
+// NewEvalOptions applies the options to a zero EvalOptions
+func NewEvalOptions(opts ...EvalOption) EvalOptions {
+	var o EvalOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// EvalOptionsContent is implemented by executable content that was compiled with evaluation
+// options, so the engine's evaluator can apply them.
+type EvalOptionsContent interface {
+	GetEvalOptions() []EvalOption
+}
+
+// NewUnitEvalOptions applies the evaluation options the unit's content was compiled with, and
+// then opts, so the options given to an evaluator take precedence. The unit can be nil.
+func NewUnitEvalOptions(unit *script.ExecutableUnit, opts ...EvalOption) EvalOptions {
+	if unit == nil {
+		return NewEvalOptions(opts...)
+	}
+	content, ok := unit.GetContent().(EvalOptionsContent)
+	if !ok {
+		return NewEvalOptions(opts...)
+	}
+	return NewEvalOptions(slices.Concat(content.GetEvalOptions(), opts)...)
+}
+
+// EvalContext returns the context for one call to Eval. When a default timeout is set and the
+// context has no deadline, the returned context times out after the default timeout. The
+// cancel function must be called when the evaluation finishes.
+func (o EvalOptions) EvalContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.DefaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.DefaultTimeout)
+}
@@ -0,0 +1,92 @@
+package platform_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalOptions_EvalContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no default timeout", func(t *testing.T) {
+		opts := platform.NewEvalOptions()
+		ctx, cancel := opts.EvalContext(t.Context())
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		require.False(t, ok)
+	})
+
+	t.Run("default timeout without caller deadline", func(t *testing.T) {
+		opts := platform.NewEvalOptions(platform.WithDefaultTimeout(time.Minute))
+		ctx, cancel := opts.EvalContext(t.Context())
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	})
+
+	t.Run("caller deadline is kept", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(t.Context(), time.Hour)
+		defer parentCancel()
+		want, _ := parent.Deadline()
+
+		opts := platform.NewEvalOptions(platform.WithDefaultTimeout(time.Second))
+		ctx, cancel := opts.EvalContext(parent)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.Equal(t, want, deadline)
+	})
+
+	t.Run("nil option is ignored", func(t *testing.T) {
+		opts := platform.NewEvalOptions(nil, platform.WithDefaultTimeout(time.Second))
+		require.Equal(t, time.Second, opts.DefaultTimeout)
+	})
+}
+
+// evalOptionsContent is executable content compiled with evaluation options
+type evalOptionsContent struct {
+	script.MockExecutableContent
+	opts []platform.EvalOption
+}
+
+func (c *evalOptionsContent) GetEvalOptions() []platform.EvalOption {
+	return c.opts
+}
+
+func TestNewUnitEvalOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil unit", func(t *testing.T) {
+		opts := platform.NewUnitEvalOptions(nil, platform.WithDefaultTimeout(time.Second))
+		require.Equal(t, time.Second, opts.DefaultTimeout)
+	})
+
+	t.Run("content without evaluation options", func(t *testing.T) {
+		unit := &script.ExecutableUnit{Content: &script.MockExecutableContent{}}
+		opts := platform.NewUnitEvalOptions(unit)
+		require.Zero(t, opts.DefaultTimeout)
+	})
+
+	t.Run("content evaluation options", func(t *testing.T) {
+		content := &evalOptionsContent{
+			opts: []platform.EvalOption{platform.WithDefaultTimeout(time.Minute)},
+		}
+		unit := &script.ExecutableUnit{Content: content}
+
+		opts := platform.NewUnitEvalOptions(unit)
+		require.Equal(t, time.Minute, opts.DefaultTimeout)
+
+		// the evaluator's own options take precedence
+		opts = platform.NewUnitEvalOptions(unit, platform.WithDefaultTimeout(time.Second))
+		require.Equal(t, time.Second, opts.DefaultTimeout)
+	})
+}
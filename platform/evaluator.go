@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script"
 )
 
 // EvalOnly is the interface for the generic code evaluator.
@@ -26,3 +27,17 @@ type Evaluator interface {
 	EvalOnly
 	data.Setter
 }
+
+// ReloadableEvaluator is an Evaluator whose script can be replaced while it is in use.
+// Calls to Eval that are already running finish with the version they started with.
+type ReloadableEvaluator interface {
+	Evaluator
+
+	// Reload recompiles the script and makes it the active version. When path is empty the
+	// current loader is used again, otherwise a new loader is created for the path.
+	// The previous version stays active when compilation fails.
+	Reload(path string) error
+
+	// Load makes an already compiled executable unit the active version.
+	Load(newVersion script.ExecutableUnit) error
+}
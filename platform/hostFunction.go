@@ -0,0 +1,255 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+
+	// hostFunctionName matches names that are valid identifiers in every engine
+	hostFunctionName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// HostFunction is a Go function that scripts can call. It's registered once and can be made
+// available to several engines: as a Risor or Starlark builtin, or as an Extism host function.
+//
+// Arguments and results cross the engine boundary as JSON values, so the function's parameter
+// and result types must be JSON marshalable. Script values are converted to the parameter types
+// with encoding/json, and results are returned to the script as nil, bool, int64, float64,
+// string, []any, or map[string]any.
+type HostFunction struct {
+	name      string
+	fn        reflect.Value
+	params    []reflect.Type
+	hasResult bool
+}
+
+// NewHostFunction wraps fn so it can be called by scripts under the given name. The first
+// parameter of fn must be a context.Context, which is the context of the evaluation. It can
+// take any number of other parameters, and must return either an error, or a result and an
+// error. For example:
+//
+//	lookup, err := platform.NewHostFunction("lookup",
+//	    func(ctx context.Context, id string, limit int) ([]Customer, error) { ... })
+func NewHostFunction(name string, fn any) (*HostFunction, error) {
+	if !hostFunctionName.MatchString(name) {
+		return nil, fmt.Errorf("invalid host function name %q", name)
+	}
+	if fn == nil {
+		return nil, fmt.Errorf("host function %q is nil", name)
+	}
+
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("host function %q must be a function, got %T", name, fn)
+	}
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("host function %q cannot be variadic", name)
+	}
+	if t.NumIn() == 0 || t.In(0) != contextType {
+		return nil, fmt.Errorf("host function %q must take a context.Context first", name)
+	}
+
+	switch {
+	case t.NumOut() == 1 && t.Out(0) == errorType:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf(
+			"host function %q must return an error, or a result and an error",
+			name,
+		)
+	}
+
+	params := make([]reflect.Type, 0, t.NumIn()-1)
+	for i := 1; i < t.NumIn(); i++ {
+		params = append(params, t.In(i))
+	}
+
+	return &HostFunction{
+		name:      name,
+		fn:        v,
+		params:    params,
+		hasResult: t.NumOut() == 2,
+	}, nil
+}
+
+// Name returns the name scripts use to call the function
+func (h *HostFunction) Name() string {
+	return h.name
+}
+
+// NumArgs returns the number of arguments the function takes, not counting the context
+func (h *HostFunction) NumArgs() int {
+	return len(h.params)
+}
+
+// Call calls the function with arguments converted from plain Go values, such as the values
+// produced by decoding JSON. The result is returned as plain Go values.
+func (h *HostFunction) Call(ctx context.Context, args ...any) (any, error) {
+	result, err := h.call(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result of host function %q: %w", h.name, err)
+	}
+	plain, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result of host function %q: %w", h.name, err)
+	}
+	return plain, nil
+}
+
+// CallJSON calls the function with a JSON array of arguments, and returns its JSON encoded
+// result. An empty input calls the function without arguments.
+func (h *HostFunction) CallJSON(ctx context.Context, input []byte) ([]byte, error) {
+	var args []any
+	if len(bytes.TrimSpace(input)) > 0 {
+		decoded, err := decodeJSONValue(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid arguments for host function %q: %w", h.name, err)
+		}
+		list, ok := decoded.([]any)
+		if !ok {
+			return nil, fmt.Errorf(
+				"arguments for host function %q must be a JSON array, got %T",
+				h.name,
+				decoded,
+			)
+		}
+		args = list
+	}
+
+	result, err := h.call(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result of host function %q: %w", h.name, err)
+	}
+	return data, nil
+}
+
+// call converts the arguments and calls the function, returning its raw result
+func (h *HostFunction) call(ctx context.Context, args []any) (result any, err error) {
+	if len(args) != len(h.params) {
+		return nil, fmt.Errorf(
+			"host function %q takes %d arguments, got %d",
+			h.name,
+			len(h.params),
+			len(args),
+		)
+	}
+
+	in := make([]reflect.Value, 0, len(args)+1)
+	in = append(in, reflect.ValueOf(ctx))
+	for i, arg := range args {
+		v, err := convertArg(arg, h.params[i])
+		if err != nil {
+			return nil, fmt.Errorf("host function %q argument %d: %w", h.name, i+1, err)
+		}
+		in = append(in, v)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = fmt.Errorf("host function %q panicked: %v", h.name, r)
+		}
+	}()
+
+	out := h.fn.Call(in)
+	if errV := out[len(out)-1]; !errV.IsNil() {
+		return nil, errV.Interface().(error)
+	}
+	if !h.hasResult {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+// convertArg converts a script value to the type of a function parameter. Values that are
+// already assignable are used as-is, and others are converted by a JSON round trip.
+func convertArg(arg any, typ reflect.Type) (reflect.Value, error) {
+	if arg == nil {
+		return reflect.Zero(typ), nil
+	}
+	if v := reflect.ValueOf(arg); v.Type().AssignableTo(typ) {
+		return v, nil
+	}
+
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("failed to marshal %T: %w", arg, err)
+	}
+	ptr := reflect.New(typ)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot convert %T to %s: %w", arg, typ, err)
+	}
+	return ptr.Elem(), nil
+}
+
+// decodeJSONValue decodes JSON into plain Go values, with whole numbers as int64 and other
+// numbers as float64
+func decodeJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return fixJSONNumbers(value), nil
+}
+
+// fixJSONNumbers replaces the json.Number values in decoded JSON with int64 or float64
+func fixJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		for i := range v {
+			v[i] = fixJSONNumbers(v[i])
+		}
+		return v
+	case map[string]any:
+		for k := range v {
+			v[k] = fixJSONNumbers(v[k])
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// HostFunctionContent is implemented by executable content that was compiled with host
+// functions, so the engine's evaluator can make them available when the script runs.
+type HostFunctionContent interface {
+	GetHostFunctions() []*HostFunction
+}
@@ -0,0 +1,214 @@
+package platform_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TestNewHostFunction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		fnName   string
+		fn       any
+		wantArgs int
+		wantErr  string
+	}{
+		{
+			name:     "result and error",
+			fnName:   "add",
+			fn:       func(ctx context.Context, a, b int) (int, error) { return a + b, nil },
+			wantArgs: 2,
+		},
+		{
+			name:     "error only",
+			fnName:   "notify",
+			fn:       func(ctx context.Context, msg string) error { return nil },
+			wantArgs: 1,
+		},
+		{
+			name:    "empty name",
+			fnName:  "",
+			fn:      func(ctx context.Context) error { return nil },
+			wantErr: "invalid host function name",
+		},
+		{
+			name:    "invalid name",
+			fnName:  "my-func",
+			fn:      func(ctx context.Context) error { return nil },
+			wantErr: "invalid host function name",
+		},
+		{name: "nil function", fnName: "f", fn: nil, wantErr: "is nil"},
+		{name: "not a function", fnName: "f", fn: 42, wantErr: "must be a function"},
+		{
+			name:    "variadic",
+			fnName:  "f",
+			fn:      func(ctx context.Context, args ...int) error { return nil },
+			wantErr: "cannot be variadic",
+		},
+		{
+			name:    "no context",
+			fnName:  "f",
+			fn:      func(a int) error { return nil },
+			wantErr: "context.Context first",
+		},
+		{
+			name:    "no error result",
+			fnName:  "f",
+			fn:      func(ctx context.Context) int { return 0 },
+			wantErr: "must return an error",
+		},
+		{
+			name:    "too many results",
+			fnName:  "f",
+			fn:      func(ctx context.Context) (int, int, error) { return 0, 0, nil },
+			wantErr: "must return an error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fn, err := platform.NewHostFunction(tt.fnName, tt.fn)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.fnName, fn.Name())
+			require.Equal(t, tt.wantArgs, fn.NumArgs())
+		})
+	}
+}
+
+func TestHostFunction_Call(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+
+	move, err := platform.NewHostFunction(
+		"move",
+		func(ctx context.Context, p point, dx int, label *string) (map[string]any, error) {
+			result := map[string]any{
+				"point": point{X: p.X + dx, Y: p.Y},
+				"scope": ctx.Value(key{}),
+			}
+			if label != nil {
+				result["label"] = *label
+			}
+			return result, nil
+		},
+	)
+	require.NoError(t, err)
+
+	t.Run("converts arguments and result", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), key{}, "test")
+		result, err := move.Call(ctx, map[string]any{"x": int64(1), "y": 2.0}, int64(4), "origin")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"point": map[string]any{"x": int64(5), "y": int64(2)},
+			"scope": "test",
+			"label": "origin",
+		}, result)
+	})
+
+	t.Run("nil arguments are zero values", func(t *testing.T) {
+		result, err := move.Call(t.Context(), nil, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"point": map[string]any{"x": int64(0), "y": int64(0)},
+			"scope": nil,
+		}, result)
+	})
+
+	t.Run("wrong number of arguments", func(t *testing.T) {
+		_, err := move.Call(t.Context(), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `host function "move" takes 3 arguments, got 1`)
+	})
+
+	t.Run("argument can't be converted", func(t *testing.T) {
+		_, err := move.Call(t.Context(), "not a point", int64(1), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "argument 1")
+	})
+
+	t.Run("function error is returned", func(t *testing.T) {
+		errNotFound := errors.New("not found")
+		fn, err := platform.NewHostFunction("find", func(ctx context.Context, id string) error {
+			return errNotFound
+		})
+		require.NoError(t, err)
+
+		_, err = fn.Call(t.Context(), "id")
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("panic is returned as an error", func(t *testing.T) {
+		fn, err := platform.NewHostFunction("boom", func(ctx context.Context) (int, error) {
+			panic("boom")
+		})
+		require.NoError(t, err)
+
+		_, err = fn.Call(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "panicked: boom")
+	})
+}
+
+func TestHostFunction_CallJSON(t *testing.T) {
+	t.Parallel()
+
+	scale, err := platform.NewHostFunction(
+		"scale",
+		func(ctx context.Context, p point, factor float64) (point, error) {
+			return point{X: int(float64(p.X) * factor), Y: int(float64(p.Y) * factor)}, nil
+		},
+	)
+	require.NoError(t, err)
+	ping, err := platform.NewHostFunction("ping", func(ctx context.Context) (string, error) {
+		return "pong", nil
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		fn      *platform.HostFunction
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "array of arguments", fn: scale, input: `[{"x": 1, "y": 2}, 2.5]`, want: `{"x":2,"y":5}`},
+		{name: "empty input", fn: ping, input: ``, want: `"pong"`},
+		{name: "empty array", fn: ping, input: `[]`, want: `"pong"`},
+		{name: "invalid JSON", fn: scale, input: `[1,`, wantErr: "invalid arguments"},
+		{name: "not an array", fn: scale, input: `{"x": 1}`, wantErr: "must be a JSON array"},
+		{name: "trailing data", fn: ping, input: `[] []`, wantErr: "invalid arguments"},
+		{name: "wrong number of arguments", fn: scale, input: `[{"x": 1}]`, wantErr: "takes 2 arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.fn.CallJSON(t.Context(), []byte(tt.input))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package platform
+
+import "fmt"
+
+// Limits bounds the resources a script can use while it's evaluated. Each engine enforces the
+// limits its runtime supports, and ignores the others. A zero value leaves that resource at the
+// engine's default.
+//
+// Engine support:
+//   - Starlark: MaxSteps
+//   - Risor: MaxSteps, MaxStackDepth
+//   - Extism: MaxMemoryPages, MaxHTTPResponseBytes, MaxVarBytes
+type Limits struct {
+	// MaxSteps is the maximum number of execution steps or VM instructions for one evaluation.
+	MaxSteps uint64
+
+	// MaxStackDepth is the maximum depth of the call stack.
+	MaxStackDepth int
+
+	// MaxMemoryPages is the maximum size of a WASM module's linear memory, in 64KiB pages.
+	MaxMemoryPages uint32
+
+	// MaxHTTPResponseBytes is the maximum size of an HTTP response body read by a WASM module.
+	MaxHTTPResponseBytes int64
+
+	// MaxVarBytes is the maximum total size of the variables stored by a WASM module.
+	MaxVarBytes int64
+}
+
+// Validate checks that no limit is negative
+func (l Limits) Validate() error {
+	if l.MaxStackDepth < 0 {
+		return fmt.Errorf("max stack depth cannot be negative, got %d", l.MaxStackDepth)
+	}
+	if l.MaxHTTPResponseBytes < 0 {
+		return fmt.Errorf(
+			"max HTTP response bytes cannot be negative, got %d",
+			l.MaxHTTPResponseBytes,
+		)
+	}
+	if l.MaxVarBytes < 0 {
+		return fmt.Errorf("max var bytes cannot be negative, got %d", l.MaxVarBytes)
+	}
+	return nil
+}
+
+// IsZero reports whether no limits are set
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// LimitedContent is implemented by executable content that was compiled with resource limits,
+// so the engine's evaluator can apply them when the script runs.
+type LimitedContent interface {
+	GetLimits() Limits
+}
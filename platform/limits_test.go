@@ -0,0 +1,56 @@
+package platform_test
+
+import (
+	"testing"
+
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		limits  platform.Limits
+		wantErr bool
+	}{
+		{name: "zero value", limits: platform.Limits{}},
+		{
+			name: "all limits set",
+			limits: platform.Limits{
+				MaxSteps:             1000,
+				MaxStackDepth:        64,
+				MaxMemoryPages:       16,
+				MaxHTTPResponseBytes: 1024,
+				MaxVarBytes:          1024,
+			},
+		},
+		{name: "negative stack depth", limits: platform.Limits{MaxStackDepth: -1}, wantErr: true},
+		{
+			name:    "negative HTTP response bytes",
+			limits:  platform.Limits{MaxHTTPResponseBytes: -1},
+			wantErr: true,
+		},
+		{name: "negative var bytes", limits: platform.Limits{MaxVarBytes: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.limits.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLimits_IsZero(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, platform.Limits{}.IsZero())
+	require.False(t, platform.Limits{MaxSteps: 1}.IsZero())
+}
@@ -0,0 +1,159 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// MaxSize is the largest total size of the files in a bundle, after decompression
+const MaxSize = 64 << 20
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// readArchive reads the files from a tar.gz or zip archive, detected from its first bytes
+func readArchive(data []byte) (map[string][]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		return readTarGz(data)
+	case bytes.HasPrefix(data, zipMagic):
+		return readZip(data)
+	default:
+		return nil, fmt.Errorf("%w: not a tar.gz or zip archive", ErrInvalidBundle)
+	}
+}
+
+// readTarGz reads the regular files from a gzip compressed tar archive
+func readTarGz(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	files := newFileSet()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+			if err := files.add(hdr.Name, tr); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf(
+				"%w: %q is not a regular file or directory",
+				ErrInvalidBundle,
+				hdr.Name,
+			)
+		}
+	}
+	return files.files, nil
+}
+
+// readZip reads the files from a zip archive
+func readZip(data []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	files := newFileSet()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			return nil, fmt.Errorf("%w: %q is not a regular file", ErrInvalidBundle, f.Name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+		err = files.add(f.Name, rc)
+		if closeErr := rc.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files.files, nil
+}
+
+// fileSet collects the files of an archive, and enforces MaxSize
+type fileSet struct {
+	files map[string][]byte
+	size  int64
+}
+
+func newFileSet() *fileSet {
+	return &fileSet{files: make(map[string][]byte)}
+}
+
+// add reads a file from an archive. Names are cleaned of a leading "./", and must be valid fs
+// paths, so files can't be placed outside the bundle.
+func (s *fileSet) add(name string, r io.Reader) error {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("%w: invalid file name %q", ErrInvalidBundle, name)
+	}
+	if _, exists := s.files[name]; exists {
+		return fmt.Errorf("%w: duplicate file %q", ErrInvalidBundle, name)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, MaxSize-s.size+1))
+	if err != nil {
+		return fmt.Errorf("%w: failed to read %q: %w", ErrInvalidBundle, name, err)
+	}
+	s.size += int64(len(content))
+	if s.size > MaxSize {
+		return fmt.Errorf("%w: files are larger than %d bytes", ErrInvalidBundle, MaxSize)
+	}
+
+	s.files[name] = content
+	return nil
+}
+
+// newFS returns a read-only file system holding the files. The files are stored in an
+// uncompressed zip archive, because zip.Reader already implements fs.FS, including the
+// directories implied by the file names.
+func newFS(files map[string][]byte) (fs.FS, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
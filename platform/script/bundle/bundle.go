@@ -0,0 +1,292 @@
+// Package bundle reads script bundles: a tar.gz or zip archive holding one or more scripts, a
+// manifest naming the engine, entry point, and default static data, and an optional schema for
+// the input data. A bundle ships a script and its configuration as one versioned artifact.
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script/loader"
+)
+
+// ManifestFile is the name of the manifest at the root of a bundle
+const ManifestFile = "manifest.json"
+
+// Manifest describes the contents of a bundle. For example:
+//
+//	{
+//	  "name": "discount",
+//	  "version": "1.4.0",
+//	  "engine": "risor",
+//	  "entryPoint": "main.risor",
+//	  "staticData": {"maxDiscount": 0.25},
+//	  "inputSchema": "schema.json"
+//	}
+type Manifest struct {
+	// Name and Version identify the bundle, and are informational
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// Engine is the machine type that runs the scripts, e.g. "risor" or "starlark"
+	Engine machineTypes.Type `json:"engine"`
+
+	// EntryPoint is the path of the script to compile, relative to the root of the bundle
+	EntryPoint string `json:"entryPoint"`
+
+	// Function is the exported function to call, and is required for Extism modules
+	Function string `json:"function,omitempty"`
+
+	// StaticData is passed to the script with every evaluation
+	StaticData map[string]any `json:"staticData,omitempty"`
+
+	// InputSchema is the path of a JSON Schema file for the input data, if any
+	InputSchema string `json:"inputSchema,omitempty"`
+}
+
+// Bundle is a script bundle that has been read and validated. Its content is held in memory,
+// so it doesn't change after it's read.
+type Bundle struct {
+	manifest  Manifest
+	fsys      fs.FS
+	schema    *Schema
+	sourceURL *url.URL
+	checksum  string
+}
+
+// Open reads a bundle from a file on disk
+func Open(filePath string) (*Bundle, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %q: %w", filePath, err)
+	}
+	ldr, err := loader.NewFromDisk(absPath)
+	if err != nil {
+		return nil, err
+	}
+	return FromLoader(ldr)
+}
+
+// FromBytes reads a bundle from the content of an archive
+func FromBytes(content []byte) (*Bundle, error) {
+	ldr, err := loader.NewFromBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	return FromLoader(ldr)
+}
+
+// FromLoader reads a bundle from the archive returned by a loader, such as an HTTP loader
+func FromLoader(ldr loader.Loader) (*Bundle, error) {
+	if ldr == nil {
+		return nil, fmt.Errorf("%w: loader is nil", ErrInvalidBundle)
+	}
+
+	reader, err := ldr.GetReader()
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(io.LimitReader(reader, MaxSize+1))
+	if closeErr := reader.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	if len(content) > MaxSize {
+		return nil, fmt.Errorf("%w: archive is larger than %d bytes", ErrInvalidBundle, MaxSize)
+	}
+
+	files, err := readArchive(content)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := parseManifest(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *Schema
+	if manifest.InputSchema != "" {
+		schema, err = ParseSchema(files[manifest.InputSchema])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+	}
+
+	fsys, err := newFS(files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle file system: %w", err)
+	}
+
+	var sourceURL *url.URL
+	if u := ldr.GetSourceURL(); u != nil {
+		sourceURL = new(url.URL)
+		*sourceURL = *u
+	}
+
+	return &Bundle{
+		manifest:  manifest,
+		fsys:      fsys,
+		schema:    schema,
+		sourceURL: sourceURL,
+		checksum:  helpers.SHA256Bytes(content),
+	}, nil
+}
+
+// parseManifest decodes and validates the manifest of a bundle
+func parseManifest(files map[string][]byte) (Manifest, error) {
+	var m Manifest
+	content, ok := files[ManifestFile]
+	if !ok {
+		return m, fmt.Errorf("%w: missing %s", ErrInvalidBundle, ManifestFile)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&m); err != nil {
+		return m, fmt.Errorf("%w: failed to parse %s: %w", ErrInvalidBundle, ManifestFile, err)
+	}
+
+	if m.Engine == "" {
+		return m, fmt.Errorf("%w: manifest has no engine", ErrInvalidBundle)
+	}
+	if !machineTypes.IsRegistered(m.Engine) {
+		return m, fmt.Errorf("%w: unknown engine %q", ErrInvalidBundle, m.Engine)
+	}
+	if m.EntryPoint == "" {
+		return m, fmt.Errorf("%w: manifest has no entry point", ErrInvalidBundle)
+	}
+	if _, ok := files[m.EntryPoint]; !ok {
+		return m, fmt.Errorf("%w: entry point %q not found", ErrInvalidBundle, m.EntryPoint)
+	}
+	if m.InputSchema != "" {
+		if _, ok := files[m.InputSchema]; !ok {
+			return m, fmt.Errorf(
+				"%w: input schema %q not found",
+				ErrInvalidBundle,
+				m.InputSchema,
+			)
+		}
+	}
+	return m, nil
+}
+
+func (b *Bundle) String() string {
+	return fmt.Sprintf(
+		"bundle.Bundle{Name: %s, Version: %s, Engine: %s, SHA256: %s}",
+		b.manifest.Name,
+		b.manifest.Version,
+		b.manifest.Engine,
+		b.checksum[:8],
+	)
+}
+
+// Manifest returns the bundle's manifest
+func (b *Bundle) Manifest() Manifest {
+	return b.manifest
+}
+
+// FS returns a read-only file system holding the files in the bundle, for scripts that import
+// other scripts from the bundle
+func (b *Bundle) FS() fs.FS {
+	return b.fsys
+}
+
+// InputSchema returns the schema for the input data, or nil when the bundle doesn't have one
+func (b *Bundle) InputSchema() *Schema {
+	return b.schema
+}
+
+// Version returns the SHA256 checksum of the archive
+func (b *Bundle) Version() string {
+	return b.checksum
+}
+
+// SourceURL returns the source URL of the archive
+func (b *Bundle) SourceURL() *url.URL {
+	return b.sourceURL
+}
+
+// Loader returns a loader for the entry point script. Its source URL is the URL of the
+// archive, with the entry point as the fragment, e.g. "file:///rules/discount.tar.gz#main.risor".
+func (b *Bundle) Loader() loader.Loader {
+	var sourceURL *url.URL
+	if b.sourceURL != nil {
+		sourceURL = new(url.URL)
+		*sourceURL = *b.sourceURL
+		sourceURL.Fragment = b.manifest.EntryPoint
+	}
+	return &entryLoader{bundle: b, sourceURL: sourceURL}
+}
+
+// entryLoader loads the entry point script of a bundle
+type entryLoader struct {
+	bundle    *Bundle
+	sourceURL *url.URL
+}
+
+func (l *entryLoader) String() string {
+	return fmt.Sprintf("bundle.Loader{EntryPoint: %s}", l.bundle.manifest.EntryPoint)
+}
+
+// GetReader opens the entry point script
+func (l *entryLoader) GetReader() (io.ReadCloser, error) {
+	return l.bundle.fsys.Open(l.bundle.manifest.EntryPoint)
+}
+
+// GetSourceURL returns the source URL of the entry point script
+func (l *entryLoader) GetSourceURL() *url.URL {
+	return l.sourceURL
+}
+
+// GetVersion returns the SHA256 checksum of the archive, which never changes
+func (l *entryLoader) GetVersion(_ context.Context) (string, error) {
+	return l.bundle.checksum, nil
+}
+
+// WrapEvaluator returns an evaluator that checks the input data against the bundle's input
+// schema before each evaluation, and fails with ErrInvalidInput when it doesn't match. The
+// input data is the runtime data added to the context, not the static data. When the bundle
+// has no input schema, the evaluator is returned unchanged.
+func (b *Bundle) WrapEvaluator(evaluator platform.Evaluator) platform.Evaluator {
+	if b.schema == nil {
+		return evaluator
+	}
+	return &validatingEvaluator{
+		Evaluator: evaluator,
+		schema:    b.schema,
+		input:     data.NewContextProvider(constants.EvalData),
+	}
+}
+
+// validatingEvaluator checks the input data before calling the wrapped evaluator
+type validatingEvaluator struct {
+	platform.Evaluator
+	schema *Schema
+	input  data.Getter
+}
+
+// Eval validates the input data, and evaluates the script if it's valid
+func (e *validatingEvaluator) Eval(ctx context.Context) (platform.EvaluatorResponse, error) {
+	input, err := e.input.GetData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input data: %w", err)
+	}
+	if err := e.schema.Validate(input); err != nil {
+		return nil, err
+	}
+	return e.Evaluator.Eval(ctx)
+}
@@ -0,0 +1,377 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/constants"
+	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The engines register their machine types when they're imported, so these tests register the
+// engines named by their manifests without importing them.
+func init() {
+	for _, engine := range []machineTypes.Type{"risor", "starlark"} {
+		if err := machineTypes.Register(engine); err != nil {
+			panic(err)
+		}
+	}
+}
+
+const testManifest = `{
+	"name": "discount",
+	"version": "1.0.0",
+	"engine": "risor",
+	"entryPoint": "main.risor",
+	"staticData": {"maxDiscount": 0.25},
+	"inputSchema": "schema.json"
+}`
+
+const testSchema = `{
+	"type": "object",
+	"required": ["amount"],
+	"properties": {"amount": {"type": "number"}}
+}`
+
+func testFiles() map[string]string {
+	return map[string]string{
+		"manifest.json":   testManifest,
+		"main.risor":      "let lib = import(\"lib/math.risor\")\nlib.double(ctx[\"amount\"])",
+		"lib/math.risor":  `function double(x) { return x * 2 }`,
+		"schema.json":     testSchema,
+		"docs/readme.txt": "not a script",
+	}
+}
+
+// tarEntry is a file or other entry in a test tar archive
+type tarEntry struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+// buildTarGz creates a tar.gz archive holding the files
+func buildTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	entries := make([]tarEntry, 0, len(files))
+	for name, content := range files {
+		entries = append(entries, tarEntry{name: name, content: content, typeflag: tar.TypeReg})
+	}
+	return buildTarGzEntries(t, entries)
+}
+
+// buildTarGzEntries creates a tar.gz archive holding the entries, in order
+func buildTarGzEntries(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Mode:     0o644,
+			Size:     int64(len(e.content)),
+			Typeflag: e.typeflag,
+		}
+		if e.typeflag == tar.TypeSymlink {
+			hdr.Size = 0
+			hdr.Linkname = e.content
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Size > 0 {
+			_, err := tw.Write([]byte(e.content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// buildZip creates a zip archive holding the files
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestFromBytes(t *testing.T) {
+	t.Parallel()
+
+	formats := []struct {
+		name  string
+		build func(*testing.T, map[string]string) []byte
+	}{
+		{name: "tar.gz", build: buildTarGz},
+		{name: "zip", build: buildZip},
+	}
+
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			t.Parallel()
+			archive := format.build(t, testFiles())
+
+			b, err := FromBytes(archive)
+			require.NoError(t, err)
+
+			m := b.Manifest()
+			assert.Equal(t, "discount", m.Name)
+			assert.Equal(t, "1.0.0", m.Version)
+			assert.Equal(t, "risor", m.Engine.String())
+			assert.Equal(t, "main.risor", m.EntryPoint)
+			assert.Equal(t, map[string]any{"maxDiscount": 0.25}, m.StaticData)
+			assert.NotNil(t, b.InputSchema())
+			assert.Equal(t, helpers.SHA256Bytes(archive), b.Version())
+			assert.Contains(t, b.String(), "Engine: risor")
+
+			lib, err := fs.ReadFile(b.FS(), "lib/math.risor")
+			require.NoError(t, err)
+			assert.Equal(t, `function double(x) { return x * 2 }`, string(lib))
+
+			ldr := b.Loader()
+			reader, err := ldr.GetReader()
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.NoError(t, reader.Close())
+			assert.Equal(t, testFiles()["main.risor"], string(content))
+
+			sourceURL := ldr.GetSourceURL()
+			require.NotNil(t, sourceURL)
+			assert.Equal(t, "bytes", sourceURL.Scheme)
+			assert.Equal(t, "main.risor", sourceURL.Fragment)
+			assert.Empty(t, b.SourceURL().Fragment, "bundle URL should not be modified")
+		})
+	}
+
+	t.Run("entries with a leading dot slash", func(t *testing.T) {
+		t.Parallel()
+		files := make(map[string]string)
+		for name, content := range testFiles() {
+			files["./"+name] = content
+		}
+		b, err := FromBytes(buildTarGz(t, files))
+		require.NoError(t, err)
+		assert.Equal(t, "main.risor", b.Manifest().EntryPoint)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "discount.tar.gz")
+	require.NoError(t, os.WriteFile(path, buildTarGz(t, testFiles()), 0o600))
+
+	b, err := Open(path)
+	require.NoError(t, err)
+
+	ldr := b.Loader()
+	assert.Equal(t, "file://"+path+"#main.risor", ldr.GetSourceURL().String())
+
+	versioned, ok := ldr.(interface {
+		GetVersion(context.Context) (string, error)
+	})
+	require.True(t, ok, "loader should be versioned")
+	version, err := versioned.GetVersion(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, b.Version(), version)
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.tar.gz"))
+	require.Error(t, err)
+}
+
+func TestInvalidBundles(t *testing.T) {
+	t.Parallel()
+
+	withFiles := func(changes map[string]string, remove ...string) []byte {
+		files := testFiles()
+		for _, name := range remove {
+			delete(files, name)
+		}
+		for name, content := range changes {
+			files[name] = content
+		}
+		return buildTarGz(t, files)
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		errMsg  string
+	}{
+		{
+			name:    "not an archive",
+			archive: []byte("print('hello')"),
+			errMsg:  "not a tar.gz or zip archive",
+		},
+		{
+			name:    "corrupt gzip",
+			archive: []byte{0x1f, 0x8b, 0x00, 0x01},
+			errMsg:  "invalid bundle",
+		},
+		{
+			name:    "missing manifest",
+			archive: withFiles(nil, "manifest.json"),
+			errMsg:  "missing manifest.json",
+		},
+		{
+			name:    "invalid manifest",
+			archive: withFiles(map[string]string{"manifest.json": "{"}),
+			errMsg:  "failed to parse manifest.json",
+		},
+		{
+			name: "unknown manifest field",
+			archive: withFiles(map[string]string{
+				"manifest.json": `{"engine": "risor", "entryPoint": "main.risor", "entry": "x"}`,
+			}),
+			errMsg: "unknown field",
+		},
+		{
+			name: "missing engine",
+			archive: withFiles(map[string]string{
+				"manifest.json": `{"entryPoint": "main.risor"}`,
+			}),
+			errMsg: "manifest has no engine",
+		},
+		{
+			name: "unknown engine",
+			archive: withFiles(map[string]string{
+				"manifest.json": `{"engine": "cobol", "entryPoint": "main.risor"}`,
+			}),
+			errMsg: `unknown engine "cobol"`,
+		},
+		{
+			name: "missing entry point",
+			archive: withFiles(map[string]string{
+				"manifest.json": `{"engine": "risor"}`,
+			}),
+			errMsg: "manifest has no entry point",
+		},
+		{
+			name: "entry point not found",
+			archive: withFiles(map[string]string{
+				"manifest.json": `{"engine": "risor", "entryPoint": "other.risor"}`,
+			}),
+			errMsg: `entry point "other.risor" not found`,
+		},
+		{
+			name:    "input schema not found",
+			archive: withFiles(nil, "schema.json"),
+			errMsg:  `input schema "schema.json" not found`,
+		},
+		{
+			name:    "invalid input schema",
+			archive: withFiles(map[string]string{"schema.json": `{"type": "decimal"}`}),
+			errMsg:  `unknown type "decimal"`,
+		},
+		{
+			name: "path outside the bundle",
+			archive: buildTarGzEntries(t, []tarEntry{
+				{name: "../evil.risor", content: "x", typeflag: tar.TypeReg},
+			}),
+			errMsg: `invalid file name "../evil.risor"`,
+		},
+		{
+			name: "symlink",
+			archive: buildTarGzEntries(t, []tarEntry{
+				{name: "main.risor", content: "/etc/passwd", typeflag: tar.TypeSymlink},
+			}),
+			errMsg: "is not a regular file or directory",
+		},
+		{
+			name: "duplicate file",
+			archive: buildTarGzEntries(t, []tarEntry{
+				{name: "main.risor", content: "1", typeflag: tar.TypeReg},
+				{name: "./main.risor", content: "2", typeflag: tar.TypeReg},
+			}),
+			errMsg: `duplicate file "main.risor"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := FromBytes(tc.archive)
+			require.ErrorIs(t, err, ErrInvalidBundle)
+			require.ErrorContains(t, err, tc.errMsg)
+			require.Nil(t, b)
+		})
+	}
+
+	t.Run("nil loader", func(t *testing.T) {
+		t.Parallel()
+		_, err := FromLoader(nil)
+		require.ErrorIs(t, err, ErrInvalidBundle)
+	})
+}
+
+// stubEvaluator records whether Eval was called
+type stubEvaluator struct {
+	data.Provider
+	called bool
+}
+
+func (e *stubEvaluator) Eval(_ context.Context) (platform.EvaluatorResponse, error) {
+	e.called = true
+	return nil, nil
+}
+
+func TestWrapEvaluator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without a schema", func(t *testing.T) {
+		t.Parallel()
+		b, err := FromBytes(buildZip(t, map[string]string{
+			"manifest.json": `{"engine": "starlark", "entryPoint": "main.star"}`,
+			"main.star":     "_ = 1",
+		}))
+		require.NoError(t, err)
+		require.Nil(t, b.InputSchema())
+
+		stub := &stubEvaluator{}
+		assert.Same(t, stub, b.WrapEvaluator(stub))
+	})
+
+	t.Run("with a schema", func(t *testing.T) {
+		t.Parallel()
+		b, err := FromBytes(buildTarGz(t, testFiles()))
+		require.NoError(t, err)
+
+		stub := &stubEvaluator{Provider: data.NewContextProvider(constants.EvalData)}
+		evaluator := b.WrapEvaluator(stub)
+
+		ctx, err := evaluator.AddDataToContext(t.Context(), map[string]any{"amount": "ten"})
+		require.NoError(t, err)
+		_, err = evaluator.Eval(ctx)
+		require.ErrorIs(t, err, ErrInvalidInput)
+		require.ErrorContains(t, err, "input.amount: expected number, got string")
+		assert.False(t, stub.called)
+
+		ctx, err = evaluator.AddDataToContext(t.Context(), map[string]any{"amount": 10})
+		require.NoError(t, err)
+		_, err = evaluator.Eval(ctx)
+		require.NoError(t, err)
+		assert.True(t, stub.called)
+	})
+}
@@ -0,0 +1,11 @@
+package bundle
+
+import "errors"
+
+var (
+	// ErrInvalidBundle is returned when a bundle can't be read, or its manifest is invalid
+	ErrInvalidBundle = errors.New("invalid bundle")
+
+	// ErrInvalidInput is returned when input data doesn't match a bundle's input schema
+	ErrInvalidInput = errors.New("input does not match the bundle's input schema")
+)
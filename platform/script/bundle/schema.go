@@ -0,0 +1,176 @@
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Schema describes the input data a bundled script expects. It's written in a subset of JSON
+// Schema: the "type", "properties", "required", "additionalProperties" (true or false),
+// "items", and "enum" keywords are checked, and other keywords, such as "title" or
+// "description", are ignored.
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *bool
+	items                *Schema
+	enum                 []any
+}
+
+// schemaJSON is the JSON form of a Schema
+type schemaJSON struct {
+	Type                 json.RawMessage    `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+}
+
+var schemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// ParseSchema parses a JSON Schema document
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid input schema: %w", err)
+	}
+	return &s, nil
+}
+
+// UnmarshalJSON decodes a schema, where "type" is either a type name or a list of them
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var raw schemaJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var types []string
+	if len(raw.Type) > 0 {
+		var single string
+		if err := json.Unmarshal(raw.Type, &single); err == nil {
+			types = []string{single}
+		} else if err := json.Unmarshal(raw.Type, &types); err != nil {
+			return errors.New(`"type" must be a string or a list of strings`)
+		}
+	}
+	for _, t := range types {
+		if !slices.Contains(schemaTypes, t) {
+			return fmt.Errorf("unknown type %q", t)
+		}
+	}
+
+	*s = Schema{
+		types:                types,
+		properties:           raw.Properties,
+		required:             raw.Required,
+		additionalProperties: raw.AdditionalProperties,
+		items:                raw.Items,
+		enum:                 raw.Enum,
+	}
+	return nil
+}
+
+// Validate checks input data against the schema. The data is compared in its JSON form, so
+// Go values such as structs or int slices are checked as the objects and arrays they encode
+// to. All violations are returned, joined into one error that wraps ErrInvalidInput.
+func (s *Schema) Validate(input map[string]any) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	if errs := s.validate("input", value); len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate checks a decoded JSON value, and returns an error for each violation
+func (s *Schema) validate(path string, value any) []error {
+	if s == nil {
+		return nil
+	}
+
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool {
+		return hasType(value, t)
+	}) {
+		return []error{fmt.Errorf(
+			"%s: expected %s, got %s",
+			path,
+			strings.Join(s.types, " or "),
+			typeName(value),
+		)}
+	}
+
+	if len(s.enum) > 0 && !slices.ContainsFunc(s.enum, func(v any) bool {
+		return reflect.DeepEqual(v, value)
+	}) {
+		return []error{fmt.Errorf("%s: %v is not one of the allowed values", path, value)}
+	}
+
+	var errs []error
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: missing required property %q", path, name))
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			propPath := path + "." + name
+			if prop, ok := s.properties[name]; ok {
+				errs = append(errs, prop.validate(propPath, v[name])...)
+			} else if s.additionalProperties != nil && !*s.additionalProperties {
+				errs = append(errs, fmt.Errorf("%s: property is not allowed", propPath))
+			}
+		}
+	case []any:
+		for i, item := range v {
+			errs = append(errs, s.items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+	}
+	return errs
+}
+
+// hasType reports whether a decoded JSON value is of a JSON Schema type
+func hasType(value any, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	default:
+		return typeName(value) == t
+	}
+}
+
+// typeName returns the JSON Schema type of a decoded JSON value
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,157 @@
+package bundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	schema, err := ParseSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Order",
+		"type": "object",
+		"required": ["id", "items"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string"},
+			"priority": {"type": "integer"},
+			"status": {"enum": ["new", "paid"]},
+			"note": {"type": ["string", "null"]},
+			"items": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["sku"],
+					"properties": {"sku": {"type": "string"}, "qty": {"type": "number"}}
+				}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	type item struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	}
+
+	tests := []struct {
+		name   string
+		input  map[string]any
+		errMsg []string
+	}{
+		{
+			name: "valid",
+			input: map[string]any{
+				"id":       "A1",
+				"priority": 2,
+				"status":   "paid",
+				"note":     nil,
+				"items":    []item{{SKU: "x", Qty: 1}},
+			},
+		},
+		{
+			name: "missing required properties",
+			input: map[string]any{
+				"status": "new",
+			},
+			errMsg: []string{
+				`input: missing required property "id"`,
+				`input: missing required property "items"`,
+			},
+		},
+		{
+			name: "wrong types",
+			input: map[string]any{
+				"id":       1,
+				"priority": 1.5,
+				"note":     true,
+				"items":    "none",
+			},
+			errMsg: []string{
+				"input.id: expected string, got number",
+				"input.priority: expected integer, got number",
+				"input.note: expected string or null, got boolean",
+				"input.items: expected array, got string",
+			},
+		},
+		{
+			name: "nested errors",
+			input: map[string]any{
+				"id":    "A1",
+				"items": []any{map[string]any{"sku": "x"}, map[string]any{"qty": "2"}},
+			},
+			errMsg: []string{
+				`input.items[1]: missing required property "sku"`,
+				"input.items[1].qty: expected number, got string",
+			},
+		},
+		{
+			name: "value not in enum",
+			input: map[string]any{
+				"id":     "A1",
+				"items":  []any{},
+				"status": "lost",
+			},
+			errMsg: []string{"input.status: lost is not one of the allowed values"},
+		},
+		{
+			name: "additional property",
+			input: map[string]any{
+				"id":    "A1",
+				"items": []any{},
+				"extra": 1,
+			},
+			errMsg: []string{"input.extra: property is not allowed"},
+		},
+		{
+			name:   "not JSON encodable",
+			input:  map[string]any{"fn": func() {}},
+			errMsg: []string{"unsupported type"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := schema.Validate(tc.input)
+			if len(tc.errMsg) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidInput)
+			for _, msg := range tc.errMsg {
+				require.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema string
+		errMsg string
+	}{
+		{name: "not JSON", schema: "{", errMsg: "invalid input schema"},
+		{name: "unknown type", schema: `{"type": "decimal"}`, errMsg: `unknown type "decimal"`},
+		{name: "invalid type", schema: `{"type": 1}`, errMsg: `"type" must be a string`},
+		{
+			name:   "invalid nested schema",
+			schema: `{"properties": {"a": {"type": "int"}}}`,
+			errMsg: `unknown type "int"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseSchema([]byte(tc.schema))
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
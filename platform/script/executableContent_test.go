@@ -32,7 +32,7 @@ func TestExecutableContent(t *testing.T) {
 
 	t.Run("GetMachineType", func(t *testing.T) {
 		mockContent := new(MockExecutableContent)
-		expectedType := machineTypes.Risor
+		expectedType := machineTypes.Type("risor")
 		mockContent.On("GetMachineType").Return(expectedType)
 
 		machineType := mockContent.GetMachineType()
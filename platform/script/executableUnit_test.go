@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,7 +52,7 @@ func (m *mockReadCloser) Close() error {
 func TestVersionMethods(t *testing.T) {
 	t.Run("GetMachineType", func(t *testing.T) {
 		mockContent := new(MockExecutableContent)
-		expectedType := machineTypes.Risor
+		expectedType := machineTypes.Type("risor")
 		mockContent.On("GetMachineType").Return(expectedType)
 
 		exe := &ExecutableUnit{
@@ -318,6 +319,27 @@ func TestNewVersion(t *testing.T) {
 		mockLoader.AssertExpectations(t)
 	})
 
+	t.Run("VerificationFailed", func(t *testing.T) {
+		ldr, err := loader.NewFromString("print('tampered')")
+		require.NoError(t, err)
+		pinned, err := loader.NewPinned(ldr, strings.Repeat("0", 64))
+		require.NoError(t, err)
+
+		// The compiler has no expectations, so the test fails if Compile is called
+		mockCompiler := new(MockCompiler)
+
+		exe, err := NewExecutableUnit(
+			logHandler,
+			"test",
+			pinned,
+			mockCompiler,
+			data.NewStaticProvider(emptyScriptData),
+		)
+		require.ErrorIs(t, err, loader.ErrVerificationFailed)
+		require.Nil(t, exe)
+		mockCompiler.AssertNotCalled(t, "Compile", mock.Anything)
+	})
+
 	t.Run("ReaderError", func(t *testing.T) {
 		// Setup mock reader with read error
 		mockReader := new(mockReadCloser)
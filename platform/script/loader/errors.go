@@ -7,4 +7,6 @@ var (
 	ErrSchemeUnsupported  = errors.New("unsupported scheme")
 	ErrScriptNotAvailable = errors.New("script not available")
 	ErrInputEmpty         = errors.New("input is empty")
+	ErrNotVersioned       = errors.New("loader does not support versioning")
+	ErrVerificationFailed = errors.New("script verification failed")
 )
@@ -2,6 +2,7 @@ package loader
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 type FromBytes struct {
 	content   []byte
 	sourceURL *url.URL
+	checksum  string
 }
 
 // NewFromBytes creates a new Loader from a byte slice.
@@ -30,8 +32,8 @@ func NewFromBytes(content []byte) (*FromBytes, error) {
 		)
 	}
 
-	contentHash := helpers.SHA256Bytes(content)[:8]
-	u, err := url.Parse("bytes://inline/" + contentHash)
+	checksum := helpers.SHA256Bytes(content)
+	u, err := url.Parse("bytes://inline/" + checksum[:8])
 	if err != nil {
 		return nil, fmt.Errorf("failed to create source URL: %w", err)
 	}
@@ -39,6 +41,7 @@ func NewFromBytes(content []byte) (*FromBytes, error) {
 	return &FromBytes{
 		content:   content,
 		sourceURL: u,
+		checksum:  checksum,
 	}, nil
 }
 
@@ -56,6 +59,11 @@ func (l *FromBytes) GetSourceURL() *url.URL {
 	return l.sourceURL
 }
 
+// GetVersion returns the SHA256 checksum of the content, which never changes.
+func (l *FromBytes) GetVersion(_ context.Context) (string, error) {
+	return l.checksum, nil
+}
+
 // isOnlyWhitespace checks if a byte slice contains only whitespace characters
 func isOnlyWhitespace(data []byte) bool {
 	if len(data) == 0 {
@@ -483,3 +483,17 @@ func TestWhitespaceAndBinaryInteraction(t *testing.T) {
 func TestFromBytes_ImplementsLoader(t *testing.T) {
 	var _ Loader = (*FromBytes)(nil)
 }
+
+func TestFromBytes_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(SimpleContent)
+	loader, err := NewFromBytes(content)
+	require.NoError(t, err)
+
+	version, err := loader.GetVersion(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, helpers.SHA256Bytes(content), version)
+
+	var _ Versioned = (*FromBytes)(nil)
+}
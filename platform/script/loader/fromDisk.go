@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -95,3 +96,13 @@ func (l *FromDisk) GetReader() (io.ReadCloser, error) {
 func (l *FromDisk) GetSourceURL() *url.URL {
 	return l.sourceURL
 }
+
+// GetVersion returns a version built from the file's modification time and size, so changes
+// can be detected without reading the file.
+func (l *FromDisk) GetVersion(_ context.Context) (string, error) {
+	info, err := os.Stat(l.sourceURL.Path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrScriptNotAvailable, err)
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
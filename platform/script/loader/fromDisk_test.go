@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/robbyt/go-polyscript/internal/helpers"
 	"github.com/stretchr/testify/require"
@@ -256,3 +257,42 @@ func TestFromDisk_String(t *testing.T) {
 func TestFromDisk_ImplementsLoader(t *testing.T) {
 	var _ Loader = (*FromDisk)(nil)
 }
+
+func TestFromDisk_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "script.risor")
+	require.NoError(t, os.WriteFile(path, []byte(SimpleContent), 0o644))
+
+	loader, err := NewFromDisk(path)
+	require.NoError(t, err)
+
+	version, err := loader.GetVersion(t.Context())
+	require.NoError(t, err)
+	require.NotEmpty(t, version)
+
+	t.Run("unchanged", func(t *testing.T) {
+		again, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, version, again)
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(MultilineContent), 0o644))
+		modTime := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+		changed, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.NotEqual(t, version, changed)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		missing, err := NewFromDisk(filepath.Join(t.TempDir(), "missing.risor"))
+		require.NoError(t, err)
+		_, err = missing.GetVersion(t.Context())
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+	})
+
+	var _ Versioned = (*FromDisk)(nil)
+}
@@ -0,0 +1,91 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+
+	"github.com/robbyt/go-polyscript/internal/helpers"
+)
+
+// FromFS implements the Loader interface for a file in an fs.FS, such as an embed.FS, an
+// fstest.MapFS, or the result of os.DirFS.
+type FromFS struct {
+	fsys      fs.FS
+	path      string
+	sourceURL *url.URL
+}
+
+// NewFromFS creates a new Loader for the file at path in fsys. The path must be a valid fs
+// path (e.g. "scripts/hello.risor", without a leading slash), and name an existing file. The
+// source URL is "fs:///" followed by the path, so it's stable across loads of the same entry.
+func NewFromFS(fsys fs.FS, path string) (*FromFS, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("%w: file system is nil", ErrScriptNotAvailable)
+	}
+	if path == "" || path == "." || !fs.ValidPath(path) {
+		return nil, fmt.Errorf("%w: invalid fs path %q", ErrScriptNotAvailable, path)
+	}
+
+	info, err := fs.Stat(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScriptNotAvailable, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %q is a directory", ErrScriptNotAvailable, path)
+	}
+
+	return &FromFS{
+		fsys:      fsys,
+		path:      path,
+		sourceURL: &url.URL{Scheme: "fs", Path: "/" + path},
+	}, nil
+}
+
+func (l *FromFS) String() string {
+	return fmt.Sprintf("loader.FromFS{Path: %s}", l.path)
+}
+
+// GetReader opens the file in the file system.
+func (l *FromFS) GetReader() (io.ReadCloser, error) {
+	return l.fsys.Open(l.path)
+}
+
+// GetSourceURL returns the source URL of the script.
+func (l *FromFS) GetSourceURL() *url.URL {
+	return l.sourceURL
+}
+
+// GetVersion returns a version built from the file's modification time and size. Some file
+// systems, such as embed.FS, have no modification times, so for those the version is the
+// SHA256 checksum of the content.
+func (l *FromFS) GetVersion(_ context.Context) (string, error) {
+	info, err := fs.Stat(l.fsys, l.path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrScriptNotAvailable, err)
+	}
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+	}
+
+	content, err := fs.ReadFile(l.fsys, l.path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrScriptNotAvailable, err)
+	}
+	return helpers.SHA256Bytes(content), nil
+}
+
+// newFromFSFile creates a Loader from an open fs.File, named after the file. The content is
+// read once, because a file can't be reopened without its file system.
+func newFromFSFile(file fs.File) (*FromIoReader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScriptNotAvailable, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %q is a directory", ErrScriptNotAvailable, info.Name())
+	}
+	return NewFromIoReader(file, info.Name())
+}
@@ -0,0 +1,118 @@
+package loader
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"scripts/hello.risor": {Data: []byte(`print("hello")`)},
+		"scripts/empty":       {Mode: fs.ModeDir | 0o755},
+	}
+
+	t.Run("valid path", func(t *testing.T) {
+		t.Parallel()
+		ldr, err := NewFromFS(fsys, "scripts/hello.risor")
+		require.NoError(t, err)
+		require.Equal(t, "fs:///scripts/hello.risor", ldr.GetSourceURL().String())
+		require.Equal(t, "loader.FromFS{Path: scripts/hello.risor}", ldr.String())
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		require.Equal(t, `print("hello")`, string(content))
+
+		again, err := NewFromFS(fsys, "scripts/hello.risor")
+		require.NoError(t, err)
+		require.Equal(t, ldr.GetSourceURL(), again.GetSourceURL(), "source URL should be stable")
+	})
+
+	t.Run("invalid inputs", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name string
+			fsys fs.FS
+			path string
+		}{
+			{name: "nil fs", fsys: nil, path: "scripts/hello.risor"},
+			{name: "empty path", fsys: fsys, path: ""},
+			{name: "root", fsys: fsys, path: "."},
+			{name: "leading slash", fsys: fsys, path: "/scripts/hello.risor"},
+			{name: "parent dir", fsys: fsys, path: "../hello.risor"},
+			{name: "missing file", fsys: fsys, path: "scripts/missing.risor"},
+			{name: "directory", fsys: fsys, path: "scripts/empty"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+				ldr, err := NewFromFS(tc.fsys, tc.path)
+				require.ErrorIs(t, err, ErrScriptNotAvailable)
+				require.Nil(t, ldr)
+			})
+		}
+	})
+
+	t.Run("version", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mapFS := fstest.MapFS{
+			"timed.star":   {Data: []byte("x = 1"), ModTime: modTime},
+			"untimed.star": {Data: []byte("x = 1")},
+		}
+
+		timed, err := NewFromFS(mapFS, "timed.star")
+		require.NoError(t, err)
+		version, err := timed.GetVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "1704164645000000000-5", version)
+
+		untimed, err := NewFromFS(mapFS, "untimed.star")
+		require.NoError(t, err)
+		before, err := untimed.GetVersion(ctx)
+		require.NoError(t, err)
+		assert.Len(t, before, 64, "should be a SHA256 checksum")
+
+		mapFS["untimed.star"] = &fstest.MapFile{Data: []byte("x = 2")}
+		after, err := untimed.GetVersion(ctx)
+		require.NoError(t, err)
+		assert.NotEqual(t, before, after)
+
+		delete(mapFS, "untimed.star")
+		_, err = untimed.GetVersion(ctx)
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+	})
+
+	t.Run("infer from fs.File", func(t *testing.T) {
+		t.Parallel()
+		file, err := fsys.Open("scripts/hello.risor")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, file.Close()) }()
+
+		ldr, err := InferLoader(file)
+		require.NoError(t, err)
+		require.IsType(t, (*FromIoReader)(nil), ldr)
+		assert.Equal(t, "reader", ldr.GetSourceURL().Scheme)
+		assert.Equal(t, "hello.risor", ldr.GetSourceURL().Host)
+
+		dir, err := fsys.Open("scripts")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, dir.Close()) }()
+
+		_, err = InferLoader(dir)
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+	})
+}
@@ -6,11 +6,16 @@
 //   - Basic authentication: Use loader.WithBasicAuth(username, password)
 //   - Bearer token: Use loader.WithBearerAuth(token)
 //   - Custom headers: Use loader.WithHeaderAuth(headers)
+//   - OAuth2 client credentials: Use loader.WithAuthenticator(httpauth.NewClientCredentials(...))
+//   - Mutual TLS: Use loader.WithClientCertificate(httpauth.NewClientCertificate(...))
+//   - HMAC request signing: Use loader.WithAuthenticator(httpauth.NewHMACAuth(...))
 //
-// The loader also supports context-based operations for timeout and cancellation control.
+// The loader also supports context-based operations for timeout and cancellation control, and
+// an optional HTTPCache, so scripts are still available when the server is down.
 package loader
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -18,6 +23,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/robbyt/go-polyscript/internal/helpers"
@@ -49,8 +55,29 @@ type HTTPOptions struct {
 	// Authentication to use for HTTP requests
 	Authenticator httpauth.Authenticator
 
+	// ClientCertificate is the client certificate for mutual TLS, set with
+	// WithClientCertificate. Its CA bundle is read again for each connection, so a rotated
+	// bundle is trusted without creating a new loader. Default is nil (no client certificate).
+	ClientCertificate *httpauth.ClientCertificate
+
 	// Headers for additional headers not related to authentication
 	Headers map[string]string
+
+	// Cache stores downloaded scripts, so they're revalidated instead of downloaded again, and
+	// are still available when the server is down. Default is nil (no caching).
+	Cache *HTTPCache
+
+	// Retry configures retries of failed requests, with exponential backoff.
+	// Default is nil (one attempt per URL).
+	Retry *RetryOptions
+
+	// Mirrors are URLs of copies of the script, tried in order when the primary URL fails with
+	// a network error or a retryable status. They use the same authentication and headers.
+	Mirrors []string
+
+	// LogHandler receives a log record for every request attempt, with its outcome.
+	// Default is nil (slog.Default() is used).
+	LogHandler slog.Handler
 }
 
 // DefaultHTTPOptions returns default options for HTTP loader.
@@ -98,6 +125,52 @@ func (o *HTTPOptions) WithNoAuth() *HTTPOptions {
 	return &newOpts
 }
 
+// WithAuthenticator returns a copy of options with the specified authenticator, such as an
+// httpauth.ClientCredentials for OAuth2.
+func (o *HTTPOptions) WithAuthenticator(auth httpauth.Authenticator) *HTTPOptions {
+	newOpts := *o
+	newOpts.Authenticator = auth
+	return &newOpts
+}
+
+// WithClientCertificate returns a copy of options with a TLS configuration that presents the
+// client certificate for mutual TLS, reloading it when its files change. An existing TLSConfig
+// is copied, and keeps its other settings.
+func (o *HTTPOptions) WithClientCertificate(cert *httpauth.ClientCertificate) *HTTPOptions {
+	newOpts := *o
+	newOpts.TLSConfig = cert.ConfigureTLS(o.TLSConfig)
+	newOpts.ClientCertificate = cert
+	return &newOpts
+}
+
+// WithCache returns a copy of options with the specified response cache.
+func (o *HTTPOptions) WithCache(cache *HTTPCache) *HTTPOptions {
+	newOpts := *o
+	newOpts.Cache = cache
+	return &newOpts
+}
+
+// WithRetry returns a copy of options with the specified retry options.
+func (o *HTTPOptions) WithRetry(retry *RetryOptions) *HTTPOptions {
+	newOpts := *o
+	newOpts.Retry = retry
+	return &newOpts
+}
+
+// WithMirrors returns a copy of options with the specified mirror URLs.
+func (o *HTTPOptions) WithMirrors(mirrors ...string) *HTTPOptions {
+	newOpts := *o
+	newOpts.Mirrors = mirrors
+	return &newOpts
+}
+
+// WithLogHandler returns a copy of options with the specified log handler.
+func (o *HTTPOptions) WithLogHandler(handler slog.Handler) *HTTPOptions {
+	newOpts := *o
+	newOpts.LogHandler = handler
+	return &newOpts
+}
+
 // WithTimeout returns a copy of options with the specified timeout.
 func (o *HTTPOptions) WithTimeout(timeout time.Duration) *HTTPOptions {
 	newOpts := *o
@@ -116,6 +189,19 @@ type FromHTTP struct {
 	sourceURL *url.URL
 	options   *HTTPOptions
 	client    httpRequester
+	logger    *slog.Logger
+	sleep     func(ctx context.Context, delay time.Duration) error
+
+	// cache is options.Cache, or nil when the loader's credentials can't be identified, and
+	// cacheKey identifies the loader's responses in it
+	cache    *HTTPCache
+	cacheKey string
+
+	// validators from the last response to GetVersion, used for conditional requests
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	version      string
 }
 
 // NewFromHTTP creates a new HTTP loader with the given URL and default options.
@@ -159,6 +245,27 @@ func NewFromHTTPWithOptions(rawURL string, options *HTTPOptions) (*FromHTTP, err
 		return nil, fmt.Errorf("%w: %s", ErrSchemeUnsupported, rawURL)
 	}
 
+	for _, mirror := range options.Mirrors {
+		mirrorURL, err := url.Parse(mirror)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse mirror URL: %w", err)
+		}
+		if mirrorURL.Scheme != "http" && mirrorURL.Scheme != "https" {
+			return nil, fmt.Errorf("%w: %s", ErrSchemeUnsupported, mirror)
+		}
+	}
+
+	if options.Retry != nil {
+		if err := options.Retry.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	logger := slog.Default()
+	if options.LogHandler != nil {
+		logger = slog.New(options.LogHandler)
+	}
+
 	// Create HTTP client with specified options
 	client := &http.Client{
 		Timeout: options.Timeout,
@@ -183,15 +290,35 @@ func NewFromHTTPWithOptions(rawURL string, options *HTTPOptions) (*FromHTTP, err
 		}
 
 		transport.TLSClientConfig = tlsConfig
+		if options.ClientCertificate != nil {
+			transport.DialTLSContext = options.ClientCertificate.DialTLSContext(
+				tlsConfig,
+				transport.DialContext,
+			)
+		}
 		client.Transport = transport
 	}
 
-	return &FromHTTP{
+	l := &FromHTTP{
 		url:       rawURL,
 		sourceURL: sourceURL,
 		options:   options,
 		client:    client,
-	}, nil
+		logger:    logger.With("loader", "http"),
+		sleep:     sleepContext,
+	}
+	if options.Cache != nil {
+		key, ok := httpCacheKey(rawURL, options)
+		if ok {
+			l.cache, l.cacheKey = options.Cache, key
+		} else {
+			l.logger.Warn(
+				"Not caching responses, the credentials can't be identified",
+				"url", rawURL,
+			)
+		}
+	}
+	return l, nil
 }
 
 // GetReader returns a reader for the HTTP content.
@@ -210,7 +337,208 @@ func (l *FromHTTP) GetReader() (io.ReadCloser, error) {
 // The returned io.ReadCloser must be closed by the caller when done.
 // HTTP errors are handled and converted to appropriate error types.
 func (l *FromHTTP) GetReaderWithContext(ctx context.Context) (io.ReadCloser, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.url, nil)
+	if l.cache != nil {
+		return l.getCachedReader(ctx, l.cache)
+	}
+
+	resp, err := l.do(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		closeBody(resp)
+		return nil, fmt.Errorf(
+			"%w: HTTP %d - %s",
+			ErrScriptNotAvailable,
+			resp.StatusCode,
+			resp.Status,
+		)
+	}
+
+	return resp.Body, nil
+}
+
+// getCachedReader returns the script from the cache while it's fresh, and otherwise revalidates
+// it with a conditional request. The cached copy is used when the server can't be reached, or
+// responds with a server error.
+func (l *FromHTTP) getCachedReader(ctx context.Context, cache *HTTPCache) (io.ReadCloser, error) {
+	entry := cache.get(l.cacheKey, l.logger.With("url", l.url))
+	if entry != nil && cache.isFresh(entry) {
+		return io.NopCloser(bytes.NewReader(entry.Body)), nil
+	}
+
+	var headers http.Header
+	if entry != nil {
+		headers = conditionalHeaders(entry.ETag, entry.LastModified)
+	}
+
+	resp, err := l.do(ctx, headers)
+	if err != nil {
+		return l.staleReader(ctx, cache, entry, err)
+	}
+	defer closeBody(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		revalidated := *entry
+		revalidated.FetchedAt = cache.now()
+		entry = &revalidated
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return l.staleReader(ctx, cache, entry, fmt.Errorf("failed to read response body: %w", err))
+		}
+		entry = &httpCacheEntry{
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    cache.now(),
+		}
+	default:
+		statusErr := fmt.Errorf(
+			"%w: HTTP %d - %s",
+			ErrScriptNotAvailable,
+			resp.StatusCode,
+			resp.Status,
+		)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return l.staleReader(ctx, cache, entry, statusErr)
+		}
+		return nil, statusErr
+	}
+
+	cache.put(l.cacheKey, entry, l.logger.With("url", l.url))
+	return io.NopCloser(bytes.NewReader(entry.Body)), nil
+}
+
+// staleReader returns the cached copy of the script when the server is unavailable, if there's
+// one that isn't too stale, and otherwise returns err.
+func (l *FromHTTP) staleReader(
+	ctx context.Context,
+	cache *HTTPCache,
+	entry *httpCacheEntry,
+	err error,
+) (io.ReadCloser, error) {
+	if entry == nil || ctx.Err() != nil || !cache.isUsableStale(entry) {
+		return nil, err
+	}
+	l.logger.Warn(
+		"Script server unavailable, using cached copy",
+		"url", l.url,
+		"fetchedAt", entry.FetchedAt,
+		"error", err,
+	)
+	return io.NopCloser(bytes.NewReader(entry.Body)), nil
+}
+
+// GetVersion checks the script for changes with a conditional GET. The ETag and Last-Modified
+// validators from the previous check are sent as If-None-Match and If-Modified-Since, so an
+// unchanged script only costs a 304 response. The version is the ETag when the server sends one,
+// then the Last-Modified time, and otherwise the SHA256 checksum of the response body.
+func (l *FromHTTP) GetVersion(ctx context.Context) (string, error) {
+	l.mu.Lock()
+	etag, lastModified, version := l.etag, l.lastModified, l.version
+	l.mu.Unlock()
+
+	var headers http.Header
+	if version != "" {
+		headers = conditionalHeaders(etag, lastModified)
+	}
+
+	resp, err := l.do(ctx, headers)
+	if err != nil {
+		return "", err
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode == http.StatusNotModified && version != "" {
+		return version, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf(
+			"%w: HTTP %d - %s",
+			ErrScriptNotAvailable,
+			resp.StatusCode,
+			resp.Status,
+		)
+	}
+
+	etag = resp.Header.Get("ETag")
+	lastModified = resp.Header.Get("Last-Modified")
+	switch {
+	case etag != "":
+		version = "etag:" + etag
+	case lastModified != "":
+		version = "last-modified:" + lastModified
+	default:
+		checksum, err := helpers.SHA256Reader(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read response body: %w", err)
+		}
+		version = "sha256:" + checksum
+	}
+
+	l.mu.Lock()
+	l.etag, l.lastModified, l.version = etag, lastModified, version
+	l.mu.Unlock()
+
+	return version, nil
+}
+
+// conditionalHeaders returns the headers for a conditional request with the validators
+func conditionalHeaders(etag, lastModified string) http.Header {
+	headers := make(http.Header)
+	if etag != "" {
+		headers.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		headers.Set("If-Modified-Since", lastModified)
+	}
+	return headers
+}
+
+// send sends a GET request for the script at rawURL with the headers added. When the server
+// rejects the credentials with a 401 status, and the authenticator can discard them, the request
+// is sent once more with fresh credentials.
+func (l *FromHTTP) send(
+	ctx context.Context,
+	rawURL string,
+	headers http.Header,
+) (*http.Response, error) {
+	req, err := l.newRequest(ctx, rawURL, headers)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	invalidator, ok := l.options.Authenticator.(httpauth.Invalidator)
+	if !ok {
+		return resp, nil
+	}
+	closeBody(resp)
+	invalidator.Invalidate(req)
+	l.logger.Info("Credentials rejected, retrying with fresh credentials", "url", rawURL)
+
+	req, err = l.newRequest(ctx, rawURL, headers)
+	if err != nil {
+		return nil, err
+	}
+	return l.client.Do(req)
+}
+
+// newRequest creates a GET request for the script at rawURL, which is the loader's URL or one of
+// its mirrors, with authentication, the options' headers, and the headers applied.
+func (l *FromHTTP) newRequest(
+	ctx context.Context,
+	rawURL string,
+	headers http.Header,
+) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -227,29 +555,23 @@ func (l *FromHTTP) GetReaderWithContext(ctx context.Context) (io.ReadCloser, err
 		req.Header.Set(key, value)
 	}
 
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+
 	// Set a default User-Agent if not specified
 	if req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", "go-polyscript/http-loader")
 	}
 
-	resp, err := l.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
-	}
+	return req, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		if err := resp.Body.Close(); err != nil {
-			slog.Default().Debug("Failed to close response body", "error", err)
-		}
-		return nil, fmt.Errorf(
-			"%w: HTTP %d - %s",
-			ErrScriptNotAvailable,
-			resp.StatusCode,
-			resp.Status,
-		)
+// closeBody closes the response body, logging any error.
+func closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		slog.Default().Debug("Failed to close response body", "error", err)
 	}
-
-	return resp.Body, nil
 }
 
 // GetSourceURL returns the source URL.
@@ -259,11 +581,21 @@ func (l *FromHTTP) GetSourceURL() *url.URL {
 }
 
 // String returns a string representation of the HTTP loader.
-// This is useful for debugging and logging.
+// This is useful for debugging and logging. When the loader has a cache, the checksum comes
+// from the cached copy, and the script isn't downloaded.
 func (l *FromHTTP) String() string {
 	var chksum string
 	noChkSum := fmt.Sprintf("loader.FromHTTP{URL: %s}", l.url)
 
+	if l.cache != nil {
+		entry := l.cache.get(l.cacheKey, l.logger.With("url", l.url))
+		if entry == nil {
+			return noChkSum
+		}
+		chksum = helpers.SHA256Bytes(entry.Body)[:8]
+		return fmt.Sprintf("loader.FromHTTP{URL: %s, SHA256: %s}", l.url, chksum)
+	}
+
 	if l.sourceURL != nil {
 		reader, err := l.GetReader()
 		if err != nil {
@@ -2,13 +2,25 @@ package loader
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/robbyt/go-polyscript/internal/helpers"
 	"github.com/robbyt/go-polyscript/platform/script/loader/httpauth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -664,3 +676,313 @@ func TestFromHTTP_GetSourceURL(t *testing.T) {
 func TestFromHTTP_ImplementsLoader(t *testing.T) {
 	var _ Loader = (*FromHTTP)(nil)
 }
+
+func TestFromHTTP_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("etag", func(t *testing.T) {
+		var mu sync.Mutex
+		etag := `"v1"`
+		var conditional []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			conditional = append(conditional, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			_, err := w.Write([]byte(SimpleContent))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		loader, err := NewFromHTTP(server.URL)
+		require.NoError(t, err)
+
+		version, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, `etag:"v1"`, version)
+
+		again, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, version, again)
+
+		mu.Lock()
+		etag = `"v2"`
+		mu.Unlock()
+
+		changed, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, `etag:"v2"`, changed)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"", `"v1"`, `"v1"`}, conditional)
+	})
+
+	t.Run("last modified", func(t *testing.T) {
+		modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "script.js", modified, strings.NewReader(FunctionContent))
+		}))
+		defer server.Close()
+
+		loader, err := NewFromHTTP(server.URL)
+		require.NoError(t, err)
+
+		version, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, "last-modified:"+modified.Format(http.TimeFormat), version)
+
+		again, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, version, again)
+	})
+
+	t.Run("content hash without validators", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get("If-None-Match"))
+			assert.Empty(t, r.Header.Get("If-Modified-Since"))
+			_, err := w.Write([]byte(SimpleContent))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		loader, err := NewFromHTTP(server.URL)
+		require.NoError(t, err)
+
+		version, err := loader.GetVersion(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, "sha256:"+helpers.SHA256(SimpleContent), version)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		loader, err := NewFromHTTP(server.URL)
+		require.NoError(t, err)
+
+		_, err = loader.GetVersion(t.Context())
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+	})
+
+	var _ Versioned = (*FromHTTP)(nil)
+}
+
+func TestFromHTTP_ClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	// newServers creates a token endpoint issuing numbered tokens, and a script server that only
+	// accepts the token it was told to accept
+	newServers := func(t *testing.T) (*atomic.Int32, *atomic.Int32, *atomic.Value, string, string) {
+		t.Helper()
+		var tokenRequests, scriptRequests atomic.Int32
+		var accepted atomic.Value
+		accepted.Store("token-1")
+
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count := tokenRequests.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, err := fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, count)
+			assert.NoError(t, err)
+		}))
+		t.Cleanup(tokenServer.Close)
+
+		scriptServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scriptRequests.Add(1)
+			if r.Header.Get("Authorization") != "Bearer "+accepted.Load().(string) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, err := w.Write([]byte(SimpleContent))
+			assert.NoError(t, err)
+		}))
+		t.Cleanup(scriptServer.Close)
+
+		return &tokenRequests, &scriptRequests, &accepted, tokenServer.URL, scriptServer.URL
+	}
+
+	newLoader := func(t *testing.T, tokenURL, scriptURL string) *FromHTTP {
+		t.Helper()
+		auth, err := httpauth.NewClientCredentials(httpauth.ClientCredentialsConfig{
+			TokenURL:     tokenURL,
+			ClientID:     "client",
+			ClientSecret: "secret",
+		})
+		require.NoError(t, err)
+		ldr, err := NewFromHTTPWithOptions(scriptURL, DefaultHTTPOptions().WithAuthenticator(auth))
+		require.NoError(t, err)
+		return ldr
+	}
+
+	t.Run("retries once with a fresh token", func(t *testing.T) {
+		t.Parallel()
+		tokenRequests, scriptRequests, accepted, tokenURL, scriptURL := newServers(t)
+		ldr := newLoader(t, tokenURL, scriptURL)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		// The token is revoked before it expires
+		accepted.Store("token-2")
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, int32(2), tokenRequests.Load())
+		assert.Equal(t, int32(3), scriptRequests.Load())
+	})
+
+	t.Run("fails when the fresh token is rejected", func(t *testing.T) {
+		t.Parallel()
+		tokenRequests, scriptRequests, accepted, tokenURL, scriptURL := newServers(t)
+		accepted.Store("never")
+		ldr := newLoader(t, tokenURL, scriptURL)
+
+		_, err := ldr.GetReader()
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+		require.ErrorContains(t, err, "HTTP 401")
+		assert.Equal(t, int32(2), tokenRequests.Load())
+		assert.Equal(t, int32(2), scriptRequests.Load())
+	})
+
+	t.Run("static credentials are not retried", func(t *testing.T) {
+		t.Parallel()
+		_, scriptRequests, _, _, scriptURL := newServers(t)
+		ldr, err := NewFromHTTPWithOptions(scriptURL, DefaultHTTPOptions().WithBearerAuth("expired"))
+		require.NoError(t, err)
+
+		_, err = ldr.GetReader()
+		require.ErrorContains(t, err, "HTTP 401")
+		assert.Equal(t, int32(1), scriptRequests.Load())
+	})
+}
+
+func TestFromHTTP_ClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte(FunctionContent))
+		assert.NoError(t, err)
+	}))
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS12, ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// The test server's self-signed certificate is the trusted CA, and the client certificate.
+	// It's valid for 127.0.0.1 and example.com, but not localhost.
+	dir := t.TempDir()
+	serverCert := server.TLS.Certificates[0]
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.crt"), certPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.key"), keyPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), certPEM, 0o600))
+
+	cert, err := httpauth.NewClientCertificate(httpauth.ClientCertificateConfig{
+		CertFile: filepath.Join(dir, "client.crt"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	})
+	require.NoError(t, err)
+	options := DefaultHTTPOptions().WithClientCertificate(cert)
+
+	t.Run("IP address host", func(t *testing.T) {
+		t.Parallel()
+		ldr, err := NewFromHTTPWithOptions(server.URL+"/script.risor", options)
+		require.NoError(t, err)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, FunctionContent)
+	})
+
+	t.Run("certificate for another host", func(t *testing.T) {
+		t.Parallel()
+		ldr, err := NewFromHTTPWithOptions(
+			strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/script.risor",
+			options,
+		)
+		require.NoError(t, err)
+
+		_, err = ldr.GetReader()
+		require.ErrorContains(t, err, "not localhost")
+	})
+
+	t.Run("existing TLS config is kept", func(t *testing.T) {
+		t.Parallel()
+		base := DefaultHTTPOptions()
+		base.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS13, ServerName: "example.com"}
+		withCert := base.WithClientCertificate(cert)
+
+		require.Equal(t, uint16(tls.VersionTLS13), withCert.TLSConfig.MinVersion)
+		require.Nil(t, base.TLSConfig.GetClientCertificate)
+
+		// The configured server name is verified instead of the dialed host
+		ldr, err := NewFromHTTPWithOptions(
+			strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/script.risor",
+			withCert,
+		)
+		require.NoError(t, err)
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, FunctionContent)
+	})
+}
+
+func TestFromHTTP_HMACAuth(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("artifact-store-secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Verify the signature the way the artifact store would
+		canonical := httpauth.CanonicalRequest(
+			r,
+			r.Header.Get("X-Timestamp"),
+			r.Header.Get("X-Content-SHA256"),
+		)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(canonical))
+		want := "HMAC-SHA256 KeyId=loader, Signature=" + hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(r.Header.Get("Authorization")), []byte(want)) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, err := w.Write([]byte(SimpleContent))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+
+	auth, err := httpauth.NewHMACAuth(httpauth.HMACConfig{KeyID: "loader", Secret: secret})
+	require.NoError(t, err)
+	ldr, err := NewFromHTTPWithOptions(
+		server.URL+"/scripts/main.risor?v=2",
+		DefaultHTTPOptions().WithAuthenticator(auth),
+	)
+	require.NoError(t, err)
+
+	reader, err := ldr.GetReader()
+	require.NoError(t, err)
+	verifyReaderContent(t, reader, SimpleContent)
+
+	wrongKey, err := httpauth.NewHMACAuth(httpauth.HMACConfig{KeyID: "loader", Secret: []byte("wrong")})
+	require.NoError(t, err)
+	ldr, err = NewFromHTTPWithOptions(
+		server.URL+"/scripts/main.risor",
+		DefaultHTTPOptions().WithAuthenticator(wrongKey),
+	)
+	require.NoError(t, err)
+	_, err = ldr.GetReader()
+	require.ErrorContains(t, err, "HTTP 403")
+}
@@ -2,6 +2,7 @@ package loader
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/url"
@@ -14,6 +15,7 @@ import (
 type FromIoReader struct {
 	content   []byte
 	sourceURL *url.URL
+	checksum  string
 }
 
 // NewFromIoReader creates a new Loader from an io.Reader source.
@@ -39,23 +41,18 @@ func NewFromIoReader(reader io.Reader, sourceName string) (*FromIoReader, error)
 		)
 	}
 
-	// Create source URL with identifier based on content
-	urlStr := "reader://"
-	if sourceName != "" {
-		urlStr += sourceName + "/"
-	} else {
-		urlStr += "unnamed/"
-	}
-	urlStr += helpers.SHA256(string(content))[:8]
-
-	u, err := url.Parse(urlStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create source URL: %w", err)
+	// Create source URL with identifier based on content. The URL is built rather than parsed,
+	// so a name that isn't a valid host name, such as a file name with a space, is escaped.
+	if sourceName == "" {
+		sourceName = "unnamed"
 	}
+	checksum := helpers.SHA256Bytes(content)
+	u := &url.URL{Scheme: "reader", Host: sourceName, Path: "/" + checksum[:8]}
 
 	return &FromIoReader{
 		content:   content,
 		sourceURL: u,
+		checksum:  checksum,
 	}, nil
 }
 
@@ -76,3 +73,9 @@ func (l *FromIoReader) GetReader() (io.ReadCloser, error) {
 func (l *FromIoReader) GetSourceURL() *url.URL {
 	return l.sourceURL
 }
+
+// GetVersion returns the SHA256 checksum of the content read from the reader, which never
+// changes after the loader is created.
+func (l *FromIoReader) GetVersion(_ context.Context) (string, error) {
+	return l.checksum, nil
+}
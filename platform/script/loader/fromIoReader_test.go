@@ -214,3 +214,16 @@ func TestFromIoReader_GetSourceURL(t *testing.T) {
 func TestFromIoReader_ImplementsLoader(t *testing.T) {
 	var _ Loader = (*FromIoReader)(nil)
 }
+
+func TestFromIoReader_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	loader, err := NewFromIoReader(strings.NewReader(SimpleContent), "test")
+	require.NoError(t, err)
+
+	version, err := loader.GetVersion(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, helpers.SHA256(SimpleContent), version)
+
+	var _ Versioned = (*FromIoReader)(nil)
+}
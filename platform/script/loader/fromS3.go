@@ -0,0 +1,396 @@
+package loader
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3Options contains configuration options for the S3 loader.
+// Use DefaultS3Options() to get the settings from the standard AWS environment variables, then
+// modify as needed.
+type S3Options struct {
+	// Region is the region of the bucket, used to sign requests, and to find the bucket on AWS
+	Region string
+
+	// Endpoint is the URL of an S3-compatible server, such as MinIO, or a test server. Objects
+	// on a custom endpoint are requested with path-style URLs, like {Endpoint}/{bucket}/{key},
+	// which S3-compatible servers expect. When empty, the bucket is requested from AWS.
+	Endpoint string
+
+	// AccessKeyID, SecretAccessKey, and the optional SessionToken are the credentials requests
+	// are signed with, using AWS Signature Version 4. When empty, requests aren't signed, which
+	// works for public buckets.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// VersionID selects a version of the object in a versioned bucket. It can also be set with
+	// a versionId query parameter, like s3://bucket/key?versionId=abc.
+	VersionID string
+
+	// Timeout specifies a time limit for requests
+	Timeout time.Duration
+}
+
+// DefaultS3Options returns options for the S3 loader from the standard AWS environment
+// variables.
+//
+// Default values:
+// - Region: AWS_REGION or AWS_DEFAULT_REGION, or us-east-1 when neither is set
+// - Endpoint: AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL, or AWS when neither is set
+// - AccessKeyID, SecretAccessKey, SessionToken: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// and AWS_SESSION_TOKEN
+// - Timeout: 30 seconds
+func DefaultS3Options() *S3Options {
+	return &S3Options{
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION", "us-east-1"),
+		Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL", ""),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Timeout:         30 * time.Second,
+	}
+}
+
+// firstEnv returns the value of the first environment variable that's set, or fallback
+func firstEnv(first, second, fallback string) string {
+	if value := os.Getenv(first); value != "" {
+		return value
+	}
+	if value := os.Getenv(second); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// WithRegion returns a copy of options with the specified region.
+func (o *S3Options) WithRegion(region string) *S3Options {
+	newOpts := *o
+	newOpts.Region = region
+	return &newOpts
+}
+
+// WithEndpoint returns a copy of options with the specified S3-compatible endpoint.
+func (o *S3Options) WithEndpoint(endpoint string) *S3Options {
+	newOpts := *o
+	newOpts.Endpoint = endpoint
+	return &newOpts
+}
+
+// WithCredentials returns a copy of options with the specified credentials. The session token
+// is only needed for temporary credentials.
+func (o *S3Options) WithCredentials(accessKeyID, secretAccessKey, sessionToken string) *S3Options {
+	newOpts := *o
+	newOpts.AccessKeyID = accessKeyID
+	newOpts.SecretAccessKey = secretAccessKey
+	newOpts.SessionToken = sessionToken
+	return &newOpts
+}
+
+// WithVersionID returns a copy of options with the specified object version.
+func (o *S3Options) WithVersionID(versionID string) *S3Options {
+	newOpts := *o
+	newOpts.VersionID = versionID
+	return &newOpts
+}
+
+// WithTimeout returns a copy of options with the specified timeout.
+func (o *S3Options) WithTimeout(timeout time.Duration) *S3Options {
+	newOpts := *o
+	newOpts.Timeout = timeout
+	return &newOpts
+}
+
+// FromS3 implements a loader for objects in S3 and S3-compatible object storage, addressed with
+// s3://bucket/key URLs.
+type FromS3 struct {
+	bucket    string
+	key       string
+	versionID string
+	sourceURL *url.URL
+	objectURL *url.URL
+	options   *S3Options
+	signer    *s3Signer
+	client    httpRequester
+	now       func() time.Time
+
+	// validators from the last response to GetVersion, used for conditional requests
+	mu      sync.Mutex
+	etag    string
+	version string
+}
+
+// NewFromS3 creates a new S3 loader with the given s3://bucket/key URL, and options from the
+// standard AWS environment variables.
+//
+// Example:
+//
+//	loader, err := loader.NewFromS3("s3://my-scripts/rules/discount.star")
+//	if err != nil {
+//	    return err
+//	}
+//
+// See also NewFromS3WithOptions for more customization options.
+func NewFromS3(rawURL string) (*FromS3, error) {
+	return NewFromS3WithOptions(rawURL, DefaultS3Options())
+}
+
+// NewFromS3WithOptions creates a new S3 loader with the given s3://bucket/key URL and custom
+// options.
+//
+// Examples:
+//
+//	// A specific version of the object
+//	options := loader.DefaultS3Options().WithVersionID("3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY")
+//	loader, err := loader.NewFromS3WithOptions("s3://my-scripts/plugin.wasm", options)
+//
+//	// From a MinIO server
+//	options := loader.DefaultS3Options().
+//	    WithEndpoint("http://localhost:9000").
+//	    WithCredentials("minioadmin", "minioadmin", "")
+//	loader, err := loader.NewFromS3WithOptions("s3://my-scripts/rules/main.risor", options)
+func NewFromS3WithOptions(rawURL string, options *S3Options) (*FromS3, error) {
+	sourceURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse URL: %w", err)
+	}
+	if sourceURL.Scheme != "s3" {
+		return nil, fmt.Errorf("%w: %s", ErrSchemeUnsupported, rawURL)
+	}
+
+	bucket := sourceURL.Host
+	key := strings.TrimPrefix(sourceURL.Path, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 URL has no bucket: %s", rawURL)
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return nil, fmt.Errorf("S3 URL has no object key: %s", rawURL)
+	}
+
+	versionID := options.VersionID
+	if queryVersion := sourceURL.Query().Get("versionId"); queryVersion != "" {
+		if versionID != "" && versionID != queryVersion {
+			return nil, fmt.Errorf(
+				"S3 URL version %q conflicts with option version %q",
+				queryVersion,
+				versionID,
+			)
+		}
+		versionID = queryVersion
+	}
+
+	if options.Region == "" {
+		return nil, errors.New("S3 region is required")
+	}
+	if (options.AccessKeyID == "") != (options.SecretAccessKey == "") {
+		return nil, errors.New("S3 credentials need both an access key ID and a secret access key")
+	}
+
+	objectURL, err := s3ObjectURL(options, bucket, key, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var signer *s3Signer
+	if options.AccessKeyID != "" {
+		signer = &s3Signer{
+			accessKeyID:     options.AccessKeyID,
+			secretAccessKey: options.SecretAccessKey,
+			sessionToken:    options.SessionToken,
+			region:          options.Region,
+		}
+	}
+
+	canonicalURL := &url.URL{Scheme: "s3", Host: bucket, Path: "/" + key}
+	if versionID != "" {
+		canonicalURL.RawQuery = url.Values{"versionId": {versionID}}.Encode()
+	}
+
+	return &FromS3{
+		bucket:    bucket,
+		key:       key,
+		versionID: versionID,
+		sourceURL: canonicalURL,
+		objectURL: objectURL,
+		options:   options,
+		signer:    signer,
+		client:    &http.Client{Timeout: options.Timeout},
+		now:       time.Now,
+	}, nil
+}
+
+// s3ObjectURL returns the HTTP URL of an object. On AWS, buckets are addressed with a virtual
+// host, except for bucket names with dots, which don't match the wildcard TLS certificate.
+func s3ObjectURL(options *S3Options, bucket, key, versionID string) (*url.URL, error) {
+	var objectURL *url.URL
+	switch {
+	case options.Endpoint != "":
+		endpoint, err := url.Parse(options.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse S3 endpoint: %w", err)
+		}
+		if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+			return nil, fmt.Errorf("%w: %s", ErrSchemeUnsupported, options.Endpoint)
+		}
+		prefix := strings.TrimSuffix(endpoint.Path, "/")
+		objectURL = &url.URL{
+			Scheme:  endpoint.Scheme,
+			Host:    endpoint.Host,
+			Path:    prefix + "/" + bucket + "/" + key,
+			RawPath: s3Escape(prefix, false) + "/" + s3Escape(bucket, false) + "/" + s3Escape(key, false),
+		}
+	case strings.Contains(bucket, "."):
+		objectURL = &url.URL{
+			Scheme:  "https",
+			Host:    "s3." + options.Region + ".amazonaws.com",
+			Path:    "/" + bucket + "/" + key,
+			RawPath: "/" + s3Escape(bucket, false) + "/" + s3Escape(key, false),
+		}
+	default:
+		objectURL = &url.URL{
+			Scheme:  "https",
+			Host:    bucket + ".s3." + options.Region + ".amazonaws.com",
+			Path:    "/" + key,
+			RawPath: "/" + s3Escape(key, false),
+		}
+	}
+
+	if versionID != "" {
+		objectURL.RawQuery = "versionId=" + s3Escape(versionID, true)
+	}
+	return objectURL, nil
+}
+
+// GetReader returns a reader for the object.
+// This method is part of the Loader interface and is used internally by
+// the polyscript system to fetch the script content.
+//
+// The returned io.ReadCloser must be closed by the caller when done.
+func (l *FromS3) GetReader() (io.ReadCloser, error) {
+	return l.GetReaderWithContext(context.Background())
+}
+
+// GetReaderWithContext returns a reader for the object with context support.
+// This allows for request cancellation and timeouts via context.
+//
+// The returned io.ReadCloser must be closed by the caller when done.
+func (l *FromS3) GetReaderWithContext(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := l.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer closeBody(resp)
+		return nil, s3ResponseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// GetVersion returns the version of the object. For a specific object version, the version ID
+// is returned without a request, because the content of a version never changes. Otherwise,
+// the object's ETag is checked with a conditional HEAD request, which costs a 304 response when
+// the object hasn't changed.
+func (l *FromS3) GetVersion(ctx context.Context) (string, error) {
+	if l.versionID != "" {
+		return "version-id:" + l.versionID, nil
+	}
+
+	l.mu.Lock()
+	etag, version := l.etag, l.version
+	l.mu.Unlock()
+
+	var headers http.Header
+	if etag != "" {
+		headers = conditionalHeaders(etag, "")
+	}
+
+	resp, err := l.do(ctx, http.MethodHead, headers)
+	if err != nil {
+		return "", err
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode == http.StatusNotModified && version != "" {
+		return version, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", s3ResponseError(resp)
+	}
+
+	etag = resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("%w: S3 response for %s has no ETag", ErrScriptNotAvailable, l.sourceURL)
+	}
+	version = "etag:" + etag
+
+	l.mu.Lock()
+	l.etag, l.version = etag, version
+	l.mu.Unlock()
+
+	return version, nil
+}
+
+// do sends a signed request for the object, with the headers added
+func (l *FromS3) do(ctx context.Context, method string, headers http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, l.objectURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", "go-polyscript/s3-loader")
+	if l.signer != nil {
+		l.signer.sign(req, l.now())
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute S3 request: %w", err)
+	}
+	return resp, nil
+}
+
+// s3Error is the error document in the body of an S3 error response
+type s3Error struct {
+	Code    string
+	Message string
+}
+
+// s3ResponseError returns an error for an S3 error response, with the code and message from
+// its error document when it has one
+func s3ResponseError(resp *http.Response) error {
+	var doc s3Error
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err == nil && xml.Unmarshal(body, &doc) == nil && doc.Code != "" {
+		return fmt.Errorf(
+			"%w: S3 %d %s - %s",
+			ErrScriptNotAvailable,
+			resp.StatusCode,
+			doc.Code,
+			doc.Message,
+		)
+	}
+	return fmt.Errorf("%w: S3 %d - %s", ErrScriptNotAvailable, resp.StatusCode, resp.Status)
+}
+
+// GetSourceURL returns the s3:// URL of the object, with its versionId when there is one.
+// This method is part of the Loader interface and identifies the source location.
+func (l *FromS3) GetSourceURL() *url.URL {
+	return l.sourceURL
+}
+
+// String returns a string representation of the S3 loader.
+// Unlike FromHTTP, it doesn't download the object for a checksum.
+func (l *FromS3) String() string {
+	return fmt.Sprintf("loader.FromS3{URL: %s}", l.sourceURL)
+}
@@ -0,0 +1,428 @@
+package loader
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAccessKeyID     = "AKIDEXAMPLE"
+	testSecretAccessKey = "secret-access-key"
+)
+
+// fakeS3 is a stand-in for a versioned S3 bucket, which checks the signature of each request
+type fakeS3 struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	objects  map[string][]string // path to its versions, oldest first
+	requests []*http.Request
+}
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	t.Helper()
+	s := &fakeS3{objects: make(map[string][]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// put stores a new version of an object, returning its version ID
+func (s *fakeS3) put(bucket, key, content string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := "/" + bucket + "/" + key
+	s.objects[path] = append(s.objects[path], content)
+	return "v" + strconv.Itoa(len(s.objects[path]))
+}
+
+// requestLog returns the requests served
+func (s *fakeS3) requestLog() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// options returns loader options for the fake, with valid credentials
+func (s *fakeS3) options() *S3Options {
+	return DefaultS3Options().
+		WithRegion("us-east-1").
+		WithEndpoint(s.URL).
+		WithCredentials(testAccessKeyID, testSecretAccessKey, "")
+}
+
+func (s *fakeS3) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+
+	if auth := r.Header.Get("Authorization"); auth != "" && !s.validSignature(r, auth) {
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch",
+			"The request signature we calculated does not match the signature you provided.")
+		return
+	}
+
+	versions := s.objects[r.URL.Path]
+	if len(versions) == 0 {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	index := len(versions) - 1
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(versionID, "v"))
+		if err != nil || n < 1 || n > len(versions) {
+			writeS3Error(w, http.StatusNotFound, "NoSuchVersion", "The specified version does not exist.")
+			return
+		}
+		index = n - 1
+	}
+
+	content := versions[index]
+	sum := md5.Sum([]byte(content))
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Amz-Version-Id", "v"+strconv.Itoa(index+1))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		return
+	}
+}
+
+// validSignature signs a copy of the request with the secret, and compares the signatures
+func (s *fakeS3) validSignature(r *http.Request, auth string) bool {
+	signingTime, err := time.Parse(sigV4TimeFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+	signed := r.Clone(r.Context())
+	signed.Header.Del("Authorization")
+	signer := &s3Signer{
+		accessKeyID:     testAccessKeyID,
+		secretAccessKey: testSecretAccessKey,
+		sessionToken:    r.Header.Get("X-Amz-Security-Token"),
+		region:          "us-east-1",
+	}
+	signer.sign(signed, signingTime)
+	return signed.Header.Get("Authorization") == auth
+}
+
+// writeS3Error writes an S3 error document
+func writeS3Error(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if _, err := fmt.Fprintf(w,
+		`<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`,
+		code, message,
+	); err != nil {
+		return
+	}
+}
+
+func TestFromS3_GetReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("signed request", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		server.put("scripts", "rules/my rule+v1.star", SimpleContent)
+
+		ldr, err := NewFromS3WithOptions("s3://scripts/rules/my rule+v1.star", server.options())
+		require.NoError(t, err)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		requests := server.requestLog()
+		require.Len(t, requests, 1)
+		assert.Equal(t, "/scripts/rules/my%20rule%2Bv1.star", requests[0].URL.EscapedPath())
+		assert.True(t, strings.HasPrefix(
+			requests[0].Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential="+testAccessKeyID+"/",
+		))
+	})
+
+	t.Run("session token", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		server.put("scripts", "main.risor", SimpleContent)
+		options := server.options().WithCredentials(testAccessKeyID, testSecretAccessKey, "token")
+
+		ldr, err := NewFromS3WithOptions("s3://scripts/main.risor", options)
+		require.NoError(t, err)
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, "token", server.requestLog()[0].Header.Get("X-Amz-Security-Token"))
+	})
+
+	t.Run("anonymous request", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		server.put("public", "main.risor", SimpleContent)
+		options := server.options().WithCredentials("", "", "")
+
+		ldr, err := NewFromS3WithOptions("s3://public/main.risor", options)
+		require.NoError(t, err)
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Empty(t, server.requestLog()[0].Header.Get("Authorization"))
+	})
+
+	t.Run("version ID", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		first := server.put("scripts", "main.risor", SimpleContent)
+		server.put("scripts", "main.risor", FunctionContent)
+
+		fromURL, err := NewFromS3WithOptions("s3://scripts/main.risor?versionId="+first, server.options())
+		require.NoError(t, err)
+		fromOptions, err := NewFromS3WithOptions(
+			"s3://scripts/main.risor",
+			server.options().WithVersionID(first),
+		)
+		require.NoError(t, err)
+		latest, err := NewFromS3WithOptions("s3://scripts/main.risor", server.options())
+		require.NoError(t, err)
+
+		for _, ldr := range []*FromS3{fromURL, fromOptions} {
+			reader, err := ldr.GetReader()
+			require.NoError(t, err)
+			verifyReaderContent(t, reader, SimpleContent)
+			assert.Equal(t, "s3://scripts/main.risor?versionId=v1", ldr.GetSourceURL().String())
+		}
+		reader, err := latest.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, FunctionContent)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		server.put("scripts", "main.risor", SimpleContent)
+
+		tests := []struct {
+			name    string
+			url     string
+			options *S3Options
+			errMsg  string
+		}{
+			{
+				name:    "missing object",
+				url:     "s3://scripts/missing.risor",
+				options: server.options(),
+				errMsg:  "S3 404 NoSuchKey - The specified key does not exist.",
+			},
+			{
+				name:    "missing version",
+				url:     "s3://scripts/main.risor?versionId=v9",
+				options: server.options(),
+				errMsg:  "S3 404 NoSuchVersion",
+			},
+			{
+				name:    "wrong secret",
+				url:     "s3://scripts/main.risor",
+				options: server.options().WithCredentials(testAccessKeyID, "wrong", ""),
+				errMsg:  "S3 403 SignatureDoesNotMatch",
+			},
+		}
+
+		for _, tc := range tests {
+			ldr, err := NewFromS3WithOptions(tc.url, tc.options)
+			require.NoError(t, err)
+			_, err = ldr.GetReader()
+			require.ErrorIs(t, err, ErrScriptNotAvailable, tc.name)
+			require.ErrorContains(t, err, tc.errMsg, tc.name)
+		}
+	})
+
+	t.Run("unreachable endpoint", func(t *testing.T) {
+		t.Parallel()
+		ldr, err := NewFromS3WithOptions(
+			"s3://scripts/main.risor",
+			DefaultS3Options().WithEndpoint(closedServerURL(t)),
+		)
+		require.NoError(t, err)
+		_, err = ldr.GetReader()
+		require.ErrorContains(t, err, "failed to execute S3 request")
+	})
+}
+
+func TestFromS3_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ETag change detection", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		server.put("scripts", "main.risor", SimpleContent)
+		ldr, err := NewFromS3WithOptions("s3://scripts/main.risor", server.options())
+		require.NoError(t, err)
+
+		version, err := ldr.GetVersion(t.Context())
+		require.NoError(t, err)
+		sum := md5.Sum([]byte(SimpleContent))
+		assert.Equal(t, `etag:"`+hex.EncodeToString(sum[:])+`"`, version)
+
+		// An unchanged object costs a 304 response
+		again, err := ldr.GetVersion(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, version, again)
+		requests := server.requestLog()
+		require.Len(t, requests, 2)
+		assert.Equal(t, http.MethodHead, requests[1].Method)
+		assert.Equal(t, ldr.etag, requests[1].Header.Get("If-None-Match"))
+
+		server.put("scripts", "main.risor", FunctionContent)
+		changed, err := ldr.GetVersion(t.Context())
+		require.NoError(t, err)
+		assert.NotEqual(t, version, changed)
+	})
+
+	t.Run("version ID", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		ldr, err := NewFromS3WithOptions("s3://scripts/main.risor?versionId=v3", server.options())
+		require.NoError(t, err)
+
+		version, err := ldr.GetVersion(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "version-id:v3", version)
+		assert.Empty(t, server.requestLog())
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		ldr, err := NewFromS3WithOptions("s3://scripts/missing.risor", server.options())
+		require.NoError(t, err)
+
+		_, err = ldr.GetVersion(t.Context())
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+		require.ErrorContains(t, err, "S3 404")
+	})
+
+	var _ Versioned = (*FromS3)(nil)
+}
+
+func TestNewFromS3WithOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("object URLs", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name    string
+			url     string
+			options *S3Options
+			want    string
+		}{
+			{
+				name:    "virtual-hosted bucket",
+				url:     "s3://scripts/rules/main.star",
+				options: &S3Options{Region: "eu-west-1"},
+				want:    "https://scripts.s3.eu-west-1.amazonaws.com/rules/main.star",
+			},
+			{
+				name:    "bucket with dots",
+				url:     "s3://scripts.example.com/main.star",
+				options: &S3Options{Region: "us-east-1"},
+				want:    "https://s3.us-east-1.amazonaws.com/scripts.example.com/main.star",
+			},
+			{
+				name:    "custom endpoint with a path",
+				url:     "s3://scripts/plugin.wasm?versionId=a+b",
+				options: &S3Options{Region: "auto", Endpoint: "https://storage.example.com/s3/"},
+				want:    "https://storage.example.com/s3/scripts/plugin.wasm?versionId=a%20b",
+			},
+		}
+
+		for _, tc := range tests {
+			ldr, err := NewFromS3WithOptions(tc.url, tc.options)
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, tc.want, ldr.objectURL.String(), tc.name)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name    string
+			url     string
+			options *S3Options
+			errMsg  string
+		}{
+			{name: "wrong scheme", url: "https://scripts/main.risor", errMsg: "unsupported scheme"},
+			{name: "no bucket", url: "s3:///main.risor", errMsg: "S3 URL has no bucket"},
+			{name: "no key", url: "s3://scripts", errMsg: "S3 URL has no object key"},
+			{name: "folder", url: "s3://scripts/rules/", errMsg: "S3 URL has no object key"},
+			{
+				name:    "conflicting versions",
+				url:     "s3://scripts/main.risor?versionId=v1",
+				options: DefaultS3Options().WithVersionID("v2"),
+				errMsg:  "conflicts with option version",
+			},
+			{
+				name:    "partial credentials",
+				url:     "s3://scripts/main.risor",
+				options: DefaultS3Options().WithCredentials("AKID", "", ""),
+				errMsg:  "S3 credentials need both",
+			},
+			{
+				name:    "no region",
+				url:     "s3://scripts/main.risor",
+				options: DefaultS3Options().WithRegion(""),
+				errMsg:  "S3 region is required",
+			},
+			{
+				name:    "endpoint scheme",
+				url:     "s3://scripts/main.risor",
+				options: DefaultS3Options().WithEndpoint("ftp://storage.example.com"),
+				errMsg:  "unsupported scheme",
+			},
+		}
+
+		for _, tc := range tests {
+			options := tc.options
+			if options == nil {
+				options = DefaultS3Options()
+			}
+			ldr, err := NewFromS3WithOptions(tc.url, options)
+			require.ErrorContains(t, err, tc.errMsg, tc.name)
+			require.Nil(t, ldr, tc.name)
+		}
+	})
+}
+
+func TestDefaultS3Options(t *testing.T) {
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_DEFAULT_REGION", "ap-southeast-2")
+		t.Setenv("AWS_ENDPOINT_URL_S3", "http://localhost:9000")
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+		ldr, err := NewFromS3("s3://scripts/main.risor")
+		require.NoError(t, err)
+		assert.Equal(t, "ap-southeast-2", ldr.options.Region)
+		assert.Equal(t, "http://localhost:9000/scripts/main.risor", ldr.objectURL.String())
+		require.NotNil(t, ldr.signer)
+		assert.Equal(t, "loader.FromS3{URL: s3://scripts/main.risor}", ldr.String())
+	})
+}
@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 type FromString struct {
 	content   string
 	sourceURL *url.URL
+	checksum  string
 }
 
 // NewFromString creates a new loader from string content.
@@ -26,7 +28,8 @@ func NewFromString(content string) (*FromString, error) {
 	}
 
 	// Use a more complete URL with a unique identifier
-	u, err := url.Parse("string://inline/" + helpers.SHA256(content)[:8])
+	checksum := helpers.SHA256(content)
+	u, err := url.Parse("string://inline/" + checksum[:8])
 	if err != nil {
 		return nil, fmt.Errorf("failed to create source URL: %w", err)
 	}
@@ -34,6 +37,7 @@ func NewFromString(content string) (*FromString, error) {
 	return &FromString{
 		content:   content,
 		sourceURL: u,
+		checksum:  checksum,
 	}, nil
 }
 
@@ -68,3 +72,8 @@ func (l *FromString) GetReader() (io.ReadCloser, error) {
 func (l *FromString) GetSourceURL() *url.URL {
 	return l.sourceURL
 }
+
+// GetVersion returns the SHA256 checksum of the content, which never changes.
+func (l *FromString) GetVersion(_ context.Context) (string, error) {
+	return l.checksum, nil
+}
@@ -256,3 +256,22 @@ func TestNewFromStringBase64(t *testing.T) {
 		verifyReaderContent(t, reader, script)
 	})
 }
+
+func TestFromString_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	loader, err := NewFromString(SimpleContent)
+	require.NoError(t, err)
+
+	version, err := loader.GetVersion(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, helpers.SHA256(SimpleContent), version)
+
+	other, err := NewFromString(MultilineContent)
+	require.NoError(t, err)
+	otherVersion, err := other.GetVersion(t.Context())
+	require.NoError(t, err)
+	require.NotEqual(t, version, otherVersion)
+
+	var _ Versioned = (*FromString)(nil)
+}
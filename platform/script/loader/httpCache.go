@@ -0,0 +1,225 @@
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform/script/loader/httpauth"
+)
+
+// HTTPCacheOptions contains configuration options for an HTTPCache.
+type HTTPCacheOptions struct {
+	// Dir is the directory where responses are stored, so they survive a restart. When empty,
+	// responses are only cached in memory.
+	Dir string
+
+	// MaxAge is how long a response is used without checking the server for changes. When zero,
+	// the server is asked on every read, with a conditional request that costs a 304 response
+	// when the script hasn't changed.
+	MaxAge time.Duration
+
+	// MaxStale is how long after MaxAge a cached response can still be used when the server
+	// can't be reached, or responds with a server error. When zero, there's no limit.
+	MaxStale time.Duration
+}
+
+// HTTPCache stores the scripts downloaded by HTTP loaders, in memory and optionally on disk.
+// Cached responses are revalidated with their ETag and Last-Modified headers, and are used when
+// the server is unavailable, so a service can start and recompile its scripts while the script
+// server is down. A cache can be shared by several loaders, and is safe for concurrent use.
+//
+// Responses are cached by URL, headers, and credentials, so loaders that authenticate as
+// different users don't share a cached script. Every authenticator in the httpauth package,
+// and client certificates, identify their credentials. A loader with a custom authenticator
+// that doesn't implement httpauth.Identifier doesn't use the cache.
+type HTTPCache struct {
+	dir      string
+	maxAge   time.Duration
+	maxStale time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*httpCacheEntry
+}
+
+// httpCacheEntry is a cached response, which is also its on-disk format
+type httpCacheEntry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+// NewHTTPCache creates a cache for HTTP loaders, and the cache directory if it's set.
+//
+// Example:
+//
+//	cache, err := loader.NewHTTPCache(loader.HTTPCacheOptions{
+//	    Dir:    "/var/cache/my-service/scripts",
+//	    MaxAge: time.Minute,
+//	})
+//	options := loader.DefaultHTTPOptions().WithCache(cache)
+//	ldr, err := loader.NewFromHTTPWithOptions("https://example.com/script.risor", options)
+func NewHTTPCache(options HTTPCacheOptions) (*HTTPCache, error) {
+	if options.MaxAge < 0 || options.MaxStale < 0 {
+		return nil, errors.New("cache durations cannot be negative")
+	}
+	if options.Dir != "" {
+		if err := os.MkdirAll(options.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+
+	return &HTTPCache{
+		dir:      options.Dir,
+		maxAge:   options.MaxAge,
+		maxStale: options.MaxStale,
+		now:      time.Now,
+		entries:  make(map[string]*httpCacheEntry),
+	}, nil
+}
+
+// get returns the cached response for a key, reading it from disk when it isn't in memory. Errors
+// are logged to the logger of the loader that reads the entry.
+func (c *HTTPCache) get(key string, logger *slog.Logger) *httpCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		return entry
+	}
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Warn("Failed to read cached script", "error", err)
+		}
+		return nil
+	}
+	var entry httpCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		logger.Warn("Ignoring invalid cached script", "error", err)
+		return nil
+	}
+	c.entries[key] = &entry
+	return &entry
+}
+
+// put stores the response for a key. A response that can't be written to disk is still cached
+// in memory.
+func (c *HTTPCache) put(key string, entry *httpCacheEntry, logger *slog.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+	if c.dir == "" {
+		return
+	}
+	if err := c.write(key, entry); err != nil {
+		logger.Warn("Failed to write cached script", "error", err)
+	}
+}
+
+// write saves an entry to disk, replacing the previous file atomically
+func (c *HTTPCache) write(key string, entry *httpCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			err = errors.Join(err, removeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// path returns the file an entry is stored in
+func (c *HTTPCache) path(key string) string {
+	return filepath.Join(c.dir, helpers.SHA256(key)+".json")
+}
+
+// httpCacheKey returns the key of a loader's responses. Loaders without credentials or headers
+// use the URL, and others add a checksum of their credentials and headers, so the credentials
+// aren't held in the cache. It returns false when the loader's credentials can't be told apart
+// from other credentials, such as a custom authenticator that doesn't implement
+// httpauth.Identifier, so its responses mustn't be cached.
+func httpCacheKey(rawURL string, options *HTTPOptions) (string, bool) {
+	var identity strings.Builder
+	switch auth := options.Authenticator.(type) {
+	case nil, *httpauth.NoAuth:
+	case httpauth.Identifier:
+		fmt.Fprintf(&identity, "auth\x00%s\x00", auth.Identity())
+	default:
+		return "", false
+	}
+
+	switch {
+	case options.ClientCertificate != nil:
+		certIdentity := options.ClientCertificate.Identity()
+		if certIdentity == "" {
+			return "", false
+		}
+		fmt.Fprintf(&identity, "cert\x00%s\x00", certIdentity)
+	case options.TLSConfig != nil && options.TLSConfig.GetClientCertificate != nil:
+		return "", false
+	case options.TLSConfig != nil:
+		for _, cert := range options.TLSConfig.Certificates {
+			if len(cert.Certificate) > 0 {
+				fmt.Fprintf(&identity, "cert\x00%s\x00", helpers.SHA256Bytes(cert.Certificate[0]))
+			}
+		}
+	}
+
+	if len(options.Headers) > 0 {
+		identity.WriteString("headers\x00")
+		writeHeaders(&identity, options.Headers)
+	}
+
+	if identity.Len() == 0 {
+		return rawURL, true
+	}
+	return rawURL + " " + helpers.SHA256(identity.String()), true
+}
+
+// writeHeaders writes headers sorted by name, so the same headers always have the same key
+func writeHeaders(b *strings.Builder, headers map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		fmt.Fprintf(b, "%s\x00%s\x00", name, headers[name])
+	}
+}
+
+// isFresh reports whether an entry can be used without asking the server
+func (c *HTTPCache) isFresh(entry *httpCacheEntry) bool {
+	return c.now().Sub(entry.FetchedAt) < c.maxAge
+}
+
+// isUsableStale reports whether an entry can be used when the server is unavailable
+func (c *HTTPCache) isUsableStale(entry *httpCacheEntry) bool {
+	return c.maxStale == 0 || c.now().Sub(entry.FetchedAt) < c.maxAge+c.maxStale
+}
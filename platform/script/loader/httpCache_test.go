@@ -0,0 +1,491 @@
+package loader
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robbyt/go-polyscript/internal/helpers"
+	"github.com/robbyt/go-polyscript/platform/script/loader/httpauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptServer serves a script that can be changed by the test, and records the conditional
+// headers of each request
+type scriptServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	body         string
+	etag         string
+	lastModified string
+	status       int
+	requests     []http.Header
+}
+
+func newScriptServer(t *testing.T, body, etag string) *scriptServer {
+	t.Helper()
+	s := &scriptServer{body: body, etag: etag}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *scriptServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Header.Clone())
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.lastModified != "" && r.Header.Get("If-Modified-Since") == s.lastModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if s.lastModified != "" {
+		w.Header().Set("Last-Modified", s.lastModified)
+	}
+	if _, err := w.Write([]byte(s.body)); err != nil {
+		return
+	}
+}
+
+// update changes the script and its validators
+func (s *scriptServer) update(body, etag, lastModified string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag, s.lastModified = body, etag, lastModified
+}
+
+// setStatus makes the server fail every request with status, or serve normally when it's 0
+func (s *scriptServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// requestCount returns the number of requests served
+func (s *scriptServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// lastRequest returns the headers of the last request
+func (s *scriptServer) lastRequest() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+// newCachedLoader creates an HTTP loader for the server's script, using cache
+func newCachedLoader(t *testing.T, server *scriptServer, cache *HTTPCache) *FromHTTP {
+	t.Helper()
+	ldr, err := NewFromHTTPWithOptions(
+		server.URL+"/script.risor",
+		DefaultHTTPOptions().WithCache(cache),
+	)
+	require.NoError(t, err)
+	return ldr
+}
+
+// failingClient is an HTTP client for a server that can't be reached
+var failingClient = &mockHTTPClient{
+	doFunc: func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	},
+}
+
+func TestHTTPCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("revalidates with ETag", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Empty(t, server.lastRequest().Get("If-None-Match"))
+
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, `"v1"`, server.lastRequest().Get("If-None-Match"))
+
+		server.update(FunctionContent, `"v2"`, "")
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, FunctionContent)
+		assert.Equal(t, 3, server.requestCount())
+	})
+
+	t.Run("revalidates with Last-Modified", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, "")
+		lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat)
+		server.update(SimpleContent, "", lastModified)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+
+		for range 2 {
+			reader, err := ldr.GetReader()
+			require.NoError(t, err)
+			verifyReaderContent(t, reader, SimpleContent)
+		}
+		assert.Equal(t, lastModified, server.lastRequest().Get("If-Modified-Since"))
+	})
+
+	t.Run("fresh responses are not revalidated", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{MaxAge: time.Hour})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+
+		for range 3 {
+			reader, err := ldr.GetReader()
+			require.NoError(t, err)
+			verifyReaderContent(t, reader, SimpleContent)
+		}
+		assert.Equal(t, 1, server.requestCount())
+
+		// Once the response is stale, it's revalidated
+		cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, 2, server.requestCount())
+	})
+
+	t.Run("serves the cached copy when the server is unavailable", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+			server.setStatus(status)
+			reader, err = ldr.GetReader()
+			require.NoError(t, err)
+			verifyReaderContent(t, reader, SimpleContent)
+		}
+
+		// Client errors mean the script is gone, so the cached copy isn't used
+		server.setStatus(http.StatusNotFound)
+		_, err = ldr.GetReader()
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+
+		ldr.client = failingClient
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+	})
+
+	t.Run("max stale", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{
+			MaxAge:   time.Minute,
+			MaxStale: time.Hour,
+		})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		ldr.client = failingClient
+
+		cache.now = func() time.Time { return time.Now().Add(30 * time.Minute) }
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		_, err = ldr.GetReader()
+		require.ErrorContains(t, err, "connection refused")
+	})
+
+	t.Run("no cached copy", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+		ldr.client = failingClient
+
+		_, err = ldr.GetReader()
+		require.ErrorContains(t, err, "failed to execute HTTP request")
+	})
+
+	t.Run("disk cache survives a restart", func(t *testing.T) {
+		t.Parallel()
+		dir := filepath.Join(t.TempDir(), "scripts")
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+
+		cache, err := NewHTTPCache(HTTPCacheOptions{Dir: dir})
+		require.NoError(t, err)
+		reader, err := newCachedLoader(t, server, cache).GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		// A new cache reads the response from disk, and revalidates it
+		restarted, err := NewHTTPCache(HTTPCacheOptions{Dir: dir})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, restarted)
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, `"v1"`, server.lastRequest().Get("If-None-Match"))
+
+		// And starts with the server down
+		offline, err := NewHTTPCache(HTTPCacheOptions{Dir: dir})
+		require.NoError(t, err)
+		ldr = newCachedLoader(t, server, offline)
+		ldr.client = failingClient
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+	})
+
+	t.Run("invalid disk entries are ignored", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		ldr := newCachedLoader(t, server, nil)
+		require.NoError(t, os.WriteFile(
+			filepath.Join(dir, helpers.SHA256(ldr.url)+".json"),
+			[]byte("{not json"),
+			0o600,
+		))
+
+		cache, err := NewHTTPCache(HTTPCacheOptions{Dir: dir})
+		require.NoError(t, err)
+		ldr = newCachedLoader(t, server, cache)
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Empty(t, server.lastRequest().Get("If-None-Match"))
+	})
+
+	t.Run("String uses the cached copy", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+		ldr := newCachedLoader(t, server, cache)
+
+		assert.NotContains(t, ldr.String(), "SHA256")
+		assert.Equal(t, 0, server.requestCount())
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		assert.Contains(t, ldr.String(), "SHA256: "+helpers.SHA256(SimpleContent)[:8])
+		assert.Equal(t, 1, server.requestCount())
+	})
+
+	t.Run("credentials have separate entries", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+
+		newAuthLoader := func(options *HTTPOptions) *FromHTTP {
+			ldr, err := NewFromHTTPWithOptions(server.URL+"/script.risor", options.WithCache(cache))
+			require.NoError(t, err)
+			return ldr
+		}
+		reader, err := newAuthLoader(DefaultHTTPOptions().WithBearerAuth("alice")).GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		// Only a loader with the same credentials and headers reads the cached copy
+		same := newAuthLoader(DefaultHTTPOptions().WithBearerAuth("alice"))
+		same.client = failingClient
+		reader, err = same.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		others := map[string]*HTTPOptions{
+			"no auth":     DefaultHTTPOptions(),
+			"other token": DefaultHTTPOptions().WithBearerAuth("bob"),
+			"basic auth":  DefaultHTTPOptions().WithBasicAuth("alice", "secret"),
+			"headers": func() *HTTPOptions {
+				options := DefaultHTTPOptions().WithBearerAuth("alice")
+				options.Headers = map[string]string{"X-Tenant": "other"}
+				return options
+			}(),
+		}
+		for name, options := range others {
+			ldr := newAuthLoader(options)
+			ldr.client = failingClient
+			_, err := ldr.GetReader()
+			require.ErrorContains(t, err, "failed to execute HTTP request", name)
+		}
+	})
+
+	t.Run("logs to the loader's handler", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{})
+		require.NoError(t, err)
+
+		var logs bytes.Buffer
+		ldr, err := NewFromHTTPWithOptions(
+			server.URL+"/script.risor",
+			DefaultHTTPOptions().WithCache(cache).WithLogHandler(slog.NewTextHandler(&logs, nil)),
+		)
+		require.NoError(t, err)
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+
+		ldr.client = failingClient
+		reader, err = ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Contains(t, logs.String(), "Script server unavailable, using cached copy")
+		assert.Contains(t, logs.String(), "loader=http")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewHTTPCache(HTTPCacheOptions{MaxAge: -time.Second})
+		require.Error(t, err)
+	})
+}
+
+// customAuth is an authenticator that doesn't identify its credentials
+type customAuth struct{ httpauth.NoAuth }
+
+func (*customAuth) Name() string { return "Custom" }
+
+func TestHTTPCacheKey(t *testing.T) {
+	t.Parallel()
+	const rawURL = "https://example.com/script.risor"
+
+	newHMAC := func(keyID, secret string) httpauth.Authenticator {
+		auth, err := httpauth.NewHMACAuth(httpauth.HMACConfig{KeyID: keyID, Secret: []byte(secret)})
+		require.NoError(t, err)
+		return auth
+	}
+	newOAuth2 := func(clientID, scope string) httpauth.Authenticator {
+		auth, err := httpauth.NewClientCredentials(httpauth.ClientCredentialsConfig{
+			TokenURL:     "https://auth.example.com/token",
+			ClientID:     clientID,
+			ClientSecret: "secret",
+			Scopes:       []string{scope},
+		})
+		require.NoError(t, err)
+		return auth
+	}
+	withHeaders := func(headers map[string]string) *HTTPOptions {
+		options := DefaultHTTPOptions()
+		options.Headers = headers
+		return options
+	}
+	withCert := func(der []byte) *HTTPOptions {
+		options := DefaultHTTPOptions()
+		options.TLSConfig = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}}}}
+		return options
+	}
+
+	// Every option identifies different credentials, so no two share a key
+	distinct := map[string]*HTTPOptions{
+		"no auth":           DefaultHTTPOptions(),
+		"bearer alice":      DefaultHTTPOptions().WithBearerAuth("alice"),
+		"bearer bob":        DefaultHTTPOptions().WithBearerAuth("bob"),
+		"basic":             DefaultHTTPOptions().WithBasicAuth("alice", "secret"),
+		"basic password":    DefaultHTTPOptions().WithBasicAuth("alice", "other"),
+		"hmac key 1":        DefaultHTTPOptions().WithAuthenticator(newHMAC("key-1", "secret")),
+		"hmac key 2":        DefaultHTTPOptions().WithAuthenticator(newHMAC("key-2", "secret")),
+		"hmac secret":       DefaultHTTPOptions().WithAuthenticator(newHMAC("key-1", "other")),
+		"oauth2 client a":   DefaultHTTPOptions().WithAuthenticator(newOAuth2("client-a", "read")),
+		"oauth2 client b":   DefaultHTTPOptions().WithAuthenticator(newOAuth2("client-b", "read")),
+		"oauth2 scope":      DefaultHTTPOptions().WithAuthenticator(newOAuth2("client-a", "write")),
+		"headers":           withHeaders(map[string]string{"X-Tenant": "a"}),
+		"other headers":     withHeaders(map[string]string{"X-Tenant": "b"}),
+		"certificate":       withCert([]byte("certificate a")),
+		"other certificate": withCert([]byte("certificate b")),
+	}
+	seen := make(map[string]string)
+	for name, options := range distinct {
+		key, ok := httpCacheKey(rawURL, options)
+		require.True(t, ok, name)
+		require.NotContains(t, seen, key, "%s has the same key as %s", name, seen[key])
+		seen[key] = name
+	}
+
+	t.Run("same credentials share a key", func(t *testing.T) {
+		t.Parallel()
+		first, ok := httpCacheKey(rawURL, DefaultHTTPOptions().WithAuthenticator(newOAuth2("client-a", "read")))
+		require.True(t, ok)
+		second, ok := httpCacheKey(rawURL, DefaultHTTPOptions().WithAuthenticator(newOAuth2("client-a", "read")))
+		require.True(t, ok)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("unidentified credentials aren't cached", func(t *testing.T) {
+		t.Parallel()
+		_, ok := httpCacheKey(rawURL, DefaultHTTPOptions().WithAuthenticator(&customAuth{}))
+		assert.False(t, ok)
+
+		options := DefaultHTTPOptions()
+		options.TLSConfig = &tls.Config{
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return nil, errors.New("not used")
+			},
+		}
+		_, ok = httpCacheKey(rawURL, options)
+		assert.False(t, ok)
+	})
+
+	t.Run("loaders with unidentified credentials read the server", func(t *testing.T) {
+		t.Parallel()
+		server := newScriptServer(t, SimpleContent, `"v1"`)
+		cache, err := NewHTTPCache(HTTPCacheOptions{MaxAge: time.Hour})
+		require.NoError(t, err)
+		ldr, err := NewFromHTTPWithOptions(
+			server.URL+"/script.risor",
+			DefaultHTTPOptions().WithAuthenticator(&customAuth{}).WithCache(cache),
+		)
+		require.NoError(t, err)
+
+		for range 2 {
+			reader, err := ldr.GetReader()
+			require.NoError(t, err)
+			verifyReaderContent(t, reader, SimpleContent)
+		}
+		assert.Equal(t, 2, server.requestCount())
+	})
+}
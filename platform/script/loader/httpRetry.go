@@ -0,0 +1,175 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryOptions configures how the HTTP loader retries failed requests. Network errors, and
+// responses with a 408, 429, or 5xx status, are retried. Other responses, such as 404, are
+// returned without retrying.
+type RetryOptions struct {
+	// MaxAttempts is the number of requests made to each URL, including the first
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. It doubles for each later retry, and
+	// a random jitter of up to half the delay is subtracted, so clients don't retry in step.
+	InitialBackoff time.Duration
+
+	// MaxBackoff limits the delay between retries. A Retry-After header on a 429 or 503
+	// response is used as the delay instead, unless it's longer than MaxBackoff, in which case
+	// the loader fails over to the next mirror.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryOptions returns retry options with sensible defaults.
+//
+// Default values:
+// - MaxAttempts: 3
+// - InitialBackoff: 200 milliseconds
+// - MaxBackoff: 5 seconds
+func DefaultRetryOptions() *RetryOptions {
+	return &RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// validate checks that the options are usable
+func (r *RetryOptions) validate() error {
+	if r.MaxAttempts < 1 {
+		return errors.New("retry MaxAttempts must be at least 1")
+	}
+	if r.InitialBackoff < 0 || r.MaxBackoff < 0 {
+		return errors.New("retry backoff cannot be negative")
+	}
+	return nil
+}
+
+// backoff returns the delay before a retry, where retry counts from 1
+func (r *RetryOptions) backoff(retry int) time.Duration {
+	delay := r.InitialBackoff
+	for i := 1; i < retry && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, r.MaxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	return delay - rand.N(delay/2+1)
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests ||
+		status >= 500
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a 429 or 503 response.
+// The header is either a number of seconds, or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests &&
+		resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the delay, or until the context is done
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do sends a GET request for the script with the headers added, retrying and failing over to
+// the mirrors as configured in the options. Each URL is tried until a response that isn't
+// retryable is returned, or its attempts are used up. When every URL fails, the last retryable
+// response is returned, so the caller reports its status, or the last error when there's none.
+func (l *FromHTTP) do(ctx context.Context, headers http.Header) (*http.Response, error) {
+	attempts := 1
+	if l.options.Retry != nil {
+		attempts = l.options.Retry.MaxAttempts
+	}
+
+	var lastResp *http.Response
+	var lastErr error
+	urls := append([]string{l.url}, l.options.Mirrors...)
+	for _, rawURL := range urls {
+		for attempt := 1; attempt <= attempts; attempt++ {
+			if lastResp != nil {
+				closeBody(lastResp)
+				lastResp = nil
+			}
+
+			start := time.Now()
+			resp, err := l.send(ctx, rawURL, headers)
+			logAttrs := []any{
+				"url", rawURL,
+				"attempt", attempt,
+				"duration", time.Since(start),
+			}
+			if err != nil {
+				l.logger.Warn("HTTP request failed", append(logAttrs, "error", err)...)
+				if ctx.Err() != nil {
+					return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+				}
+				lastErr = fmt.Errorf("failed to execute HTTP request: %w", err)
+			} else if !isRetryableStatus(resp.StatusCode) {
+				l.logger.Debug("HTTP request complete", append(logAttrs, "status", resp.StatusCode)...)
+				return resp, nil
+			} else {
+				l.logger.Warn("HTTP request failed", append(logAttrs, "status", resp.StatusCode)...)
+				lastResp = resp
+			}
+
+			if attempt == attempts {
+				break
+			}
+			delay := l.options.Retry.backoff(attempt)
+			if resp != nil {
+				if requested, ok := retryAfter(resp, time.Now()); ok {
+					if requested > l.options.Retry.MaxBackoff {
+						l.logger.Warn("Retry-After is too long, trying the next URL",
+							"url", rawURL, "retryAfter", requested)
+						break
+					}
+					delay = requested
+				}
+			}
+			if err := l.sleep(ctx, delay); err != nil {
+				if lastResp != nil {
+					closeBody(lastResp)
+				}
+				return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+			}
+		}
+	}
+
+	if lastResp != nil {
+		return lastResp, nil
+	}
+	return nil, lastErr
+}
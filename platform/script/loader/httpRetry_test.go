@@ -0,0 +1,373 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer responds with each status in turn, then serves the script
+type flakyServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	statuses   []int
+	retryAfter string
+	requests   int
+}
+
+func newFlakyServer(t *testing.T, statuses ...int) *flakyServer {
+	t.Helper()
+	s := &flakyServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			if s.retryAfter != "" {
+				w.Header().Set("Retry-After", s.retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		if _, err := w.Write([]byte(SimpleContent)); err != nil {
+			return
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requestCount returns the number of requests served
+func (s *flakyServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// newRetryLoader creates an HTTP loader that records its retry delays instead of sleeping
+func newRetryLoader(
+	t *testing.T,
+	rawURL string,
+	options *HTTPOptions,
+) (*FromHTTP, *[]time.Duration) {
+	t.Helper()
+	ldr, err := NewFromHTTPWithOptions(rawURL, options)
+	require.NoError(t, err)
+
+	var delays []time.Duration
+	ldr.sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return ctx.Err()
+	}
+	return ldr, &delays
+}
+
+// closedServerURL returns the URL of a server that is no longer listening
+func closedServerURL(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestRetryOptions_Backoff(t *testing.T) {
+	t.Parallel()
+
+	retry := &RetryOptions{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	tests := []struct {
+		retry int
+		base  time.Duration
+	}{
+		{retry: 1, base: 100 * time.Millisecond},
+		{retry: 2, base: 200 * time.Millisecond},
+		{retry: 3, base: 400 * time.Millisecond},
+		{retry: 4, base: 800 * time.Millisecond},
+		{retry: 5, base: time.Second},
+		{retry: 50, base: time.Second},
+	}
+
+	for _, tc := range tests {
+		for range 20 {
+			delay := retry.backoff(tc.retry)
+			require.GreaterOrEqual(t, delay, tc.base/2)
+			require.LessOrEqual(t, delay, tc.base)
+		}
+	}
+
+	zero := &RetryOptions{MaxAttempts: 2}
+	require.Zero(t, zero.backoff(1))
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{name: "seconds", status: http.StatusTooManyRequests, header: "3", want: 3 * time.Second, ok: true},
+		{
+			name:   "date",
+			status: http.StatusServiceUnavailable,
+			header: now.Add(time.Minute).Format(http.TimeFormat),
+			want:   time.Minute,
+			ok:     true,
+		},
+		{
+			name:   "past date",
+			status: http.StatusServiceUnavailable,
+			header: now.Add(-time.Minute).Format(http.TimeFormat),
+			ok:     true,
+		},
+		{name: "missing", status: http.StatusTooManyRequests},
+		{name: "invalid", status: http.StatusTooManyRequests, header: "soon"},
+		{name: "other status", status: http.StatusBadGateway, header: "3"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			resp := &http.Response{StatusCode: tc.status, Header: make(http.Header)}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			delay, ok := retryAfter(resp, now)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, delay)
+		})
+	}
+}
+
+func TestFromHTTP_Retry(t *testing.T) {
+	t.Parallel()
+
+	retryOptions := &RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	t.Run("retries transient failures", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, http.StatusBadGateway, http.StatusServiceUnavailable)
+		var logs bytes.Buffer
+		options := DefaultHTTPOptions().
+			WithRetry(retryOptions).
+			WithLogHandler(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		ldr, delays := newRetryLoader(t, server.URL, options)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, 3, server.requestCount())
+		assert.Len(t, *delays, 2)
+
+		output := logs.String()
+		assert.Equal(t, 2, strings.Count(output, "HTTP request failed"))
+		assert.Contains(t, output, "status=502")
+		assert.Contains(t, output, "status=503")
+		assert.Contains(t, output, "HTTP request complete")
+		assert.Contains(t, output, "attempt=3")
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, http.StatusTooManyRequests)
+		server.retryAfter = "1"
+		ldr, delays := newRetryLoader(t, server.URL, DefaultHTTPOptions().WithRetry(retryOptions))
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, []time.Duration{time.Second}, *delays)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, http.StatusNotFound)
+		ldr, delays := newRetryLoader(t, server.URL, DefaultHTTPOptions().WithRetry(retryOptions))
+
+		_, err := ldr.GetReader()
+		require.ErrorIs(t, err, ErrScriptNotAvailable)
+		require.ErrorContains(t, err, "HTTP 404")
+		assert.Equal(t, 1, server.requestCount())
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("reports the last status when attempts are used up", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, 500, 500, 503, 500)
+		ldr, delays := newRetryLoader(t, server.URL, DefaultHTTPOptions().WithRetry(retryOptions))
+
+		_, err := ldr.GetReader()
+		require.ErrorContains(t, err, "HTTP 503")
+		assert.Equal(t, 3, server.requestCount())
+		assert.Len(t, *delays, 2)
+	})
+
+	t.Run("without retry options", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, http.StatusServiceUnavailable)
+		ldr, delays := newRetryLoader(t, server.URL, DefaultHTTPOptions())
+
+		_, err := ldr.GetReader()
+		require.ErrorContains(t, err, "HTTP 503")
+		assert.Equal(t, 1, server.requestCount())
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, 500, 500, 500)
+		ldr, err := NewFromHTTPWithOptions(server.URL, DefaultHTTPOptions().WithRetry(retryOptions))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		ldr.sleep = func(ctx context.Context, delay time.Duration) error {
+			cancel()
+			return sleepContext(ctx, delay)
+		}
+
+		_, err = ldr.GetReaderWithContext(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, server.requestCount())
+	})
+
+	t.Run("GetVersion retries", func(t *testing.T) {
+		t.Parallel()
+		server := newFlakyServer(t, http.StatusServiceUnavailable)
+		ldr, _ := newRetryLoader(t, server.URL, DefaultHTTPOptions().WithRetry(retryOptions))
+
+		version, err := ldr.GetVersion(t.Context())
+		require.NoError(t, err)
+		assert.NotEmpty(t, version)
+		assert.Equal(t, 2, server.requestCount())
+	})
+}
+
+func TestFromHTTP_Mirrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fails over when the primary is unreachable", func(t *testing.T) {
+		t.Parallel()
+		mirror := newFlakyServer(t)
+		options := DefaultHTTPOptions().
+			WithRetry(&RetryOptions{MaxAttempts: 2}).
+			WithMirrors(mirror.URL + "/script.risor")
+		ldr, delays := newRetryLoader(t, closedServerURL(t)+"/script.risor", options)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, 1, mirror.requestCount())
+		assert.Len(t, *delays, 1, "the primary should be retried before failing over")
+		assert.Equal(t, ldr.url, ldr.GetSourceURL().String())
+	})
+
+	t.Run("fails over when Retry-After is too long", func(t *testing.T) {
+		t.Parallel()
+		primary := newFlakyServer(t, http.StatusServiceUnavailable)
+		primary.retryAfter = "3600"
+		mirror := newFlakyServer(t)
+		options := DefaultHTTPOptions().
+			WithRetry(&RetryOptions{MaxAttempts: 3, MaxBackoff: time.Second}).
+			WithMirrors(mirror.URL)
+		ldr, delays := newRetryLoader(t, primary.URL, options)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, 1, primary.requestCount())
+		assert.Equal(t, 1, mirror.requestCount())
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("tries mirrors in order", func(t *testing.T) {
+		t.Parallel()
+		first := newFlakyServer(t, http.StatusBadGateway)
+		second := newFlakyServer(t)
+		options := DefaultHTTPOptions().WithMirrors(first.URL, second.URL)
+		ldr, _ := newRetryLoader(t, closedServerURL(t), options)
+
+		reader, err := ldr.GetReader()
+		require.NoError(t, err)
+		verifyReaderContent(t, reader, SimpleContent)
+		assert.Equal(t, 1, first.requestCount())
+		assert.Equal(t, 1, second.requestCount())
+	})
+
+	t.Run("all URLs unreachable", func(t *testing.T) {
+		t.Parallel()
+		options := DefaultHTTPOptions().WithMirrors(closedServerURL(t))
+		ldr, _ := newRetryLoader(t, closedServerURL(t), options)
+
+		_, err := ldr.GetReader()
+		require.ErrorContains(t, err, "failed to execute HTTP request")
+	})
+}
+
+func TestNewFromHTTPWithOptions_RetryValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options *HTTPOptions
+		errMsg  string
+	}{
+		{
+			name:    "mirror with unsupported scheme",
+			options: DefaultHTTPOptions().WithMirrors("ftp://example.com/script.js"),
+			errMsg:  "unsupported scheme",
+		},
+		{
+			name:    "invalid mirror URL",
+			options: DefaultHTTPOptions().WithMirrors("http://[::1"),
+			errMsg:  "unable to parse mirror URL",
+		},
+		{
+			name:    "no attempts",
+			options: DefaultHTTPOptions().WithRetry(&RetryOptions{}),
+			errMsg:  "MaxAttempts must be at least 1",
+		},
+		{
+			name: "negative backoff",
+			options: DefaultHTTPOptions().WithRetry(&RetryOptions{
+				MaxAttempts:    2,
+				InitialBackoff: -time.Second,
+			}),
+			errMsg: "backoff cannot be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewFromHTTPWithOptions("https://example.com/script.js", tc.options)
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+
+	retry := DefaultRetryOptions()
+	require.NoError(t, retry.validate())
+}
@@ -4,6 +4,7 @@ package httpauth
 import (
 	"context"
 	"net/http"
+	"strings"
 )
 
 // Authenticator defines the interface for HTTP authentication strategies.
@@ -51,3 +52,26 @@ func applyAuthWithContext(
 	reqWithCtx := req.WithContext(ctx)
 	return authFn(reqWithCtx)
 }
+
+// Identifier is implemented by authenticators that can tell their credentials apart, so a cache
+// shared by several loaders doesn't serve a script downloaded with one set of credentials to a
+// loader with another. Identity returns the same string for the same credentials, and different
+// strings for different credentials. It can contain secrets, so callers must hash it before
+// storing it.
+type Identifier interface {
+	Identity() string
+}
+
+// joinIdentity joins the parts of an identity, so that different parts can't run together
+func joinIdentity(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// Invalidator is implemented by authenticators with credentials that can stop working before
+// they're expected to, such as OAuth2 access tokens. When a server rejects a request with a 401
+// status, the HTTP loader calls Invalidate with the rejected request, and sends it again once
+// with fresh credentials.
+type Invalidator interface {
+	// Invalidate discards the credentials the rejected request was sent with.
+	Invalidate(req *http.Request)
+}
@@ -41,3 +41,8 @@ func (b *BasicAuth) AuthenticateWithContext(ctx context.Context, req *http.Reque
 func (b *BasicAuth) Name() string {
 	return "Basic"
 }
+
+// Identity implements Identifier, and identifies the username and password.
+func (b *BasicAuth) Identity() string {
+	return joinIdentity(b.Name(), b.Username, b.Password)
+}
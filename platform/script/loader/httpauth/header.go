@@ -4,6 +4,7 @@ import (
 	"context"
 	"maps"
 	"net/http"
+	"slices"
 )
 
 // HeaderAuth implements authentication via custom HTTP headers.
@@ -50,3 +51,12 @@ func (h *HeaderAuth) AuthenticateWithContext(ctx context.Context, req *http.Requ
 func (h *HeaderAuth) Name() string {
 	return "Header"
 }
+
+// Identity implements Identifier, and identifies the headers and their values.
+func (h *HeaderAuth) Identity() string {
+	parts := []string{h.Name()}
+	for _, name := range slices.Sorted(maps.Keys(h.Headers)) {
+		parts = append(parts, name, h.Headers[name])
+	}
+	return joinIdentity(parts...)
+}
@@ -0,0 +1,146 @@
+package httpauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyBodyHash is the hex encoded SHA256 hash of an empty body
+const emptyBodyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var signingTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+// newTestHMACAuth creates an HMAC authenticator with a fixed clock
+func newTestHMACAuth(t *testing.T, config HMACConfig) *HMACAuth {
+	t.Helper()
+	auth, err := NewHMACAuth(config)
+	require.NoError(t, err)
+	auth.now = func() time.Time { return signingTime }
+	return auth
+}
+
+// sign returns the hex encoded HMAC-SHA256 of message
+func sign(secret, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHMACAuth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default scheme", func(t *testing.T) {
+		t.Parallel()
+		auth := newTestHMACAuth(t, HMACConfig{KeyID: "loader", Secret: []byte("secret")})
+		require.Equal(t, "HMAC", auth.Name())
+
+		req, err := http.NewRequest(
+			http.MethodGet,
+			"https://artifacts.example.com/scripts/main%20v2.risor?version=3&arch=any",
+			nil,
+		)
+		require.NoError(t, err)
+		require.NoError(t, auth.AuthenticateWithContext(t.Context(), req))
+
+		canonical := "GET\n/scripts/main%20v2.risor\narch=any&version=3\n1714979289\n" + emptyBodyHash
+		assert.Equal(t, "1714979289", req.Header.Get("X-Timestamp"))
+		assert.Equal(t, emptyBodyHash, req.Header.Get("X-Content-SHA256"))
+		assert.Equal(t,
+			"HMAC-SHA256 KeyId=loader, Signature="+sign("secret", canonical),
+			req.Header.Get("Authorization"),
+		)
+	})
+
+	t.Run("without a key ID", func(t *testing.T) {
+		t.Parallel()
+		auth := newTestHMACAuth(t, HMACConfig{Secret: []byte("secret")})
+
+		req, err := http.NewRequest(http.MethodGet, "https://artifacts.example.com", nil)
+		require.NoError(t, err)
+		require.NoError(t, auth.Authenticate(req))
+
+		canonical := "GET\n/\n\n1714979289\n" + emptyBodyHash
+		assert.Equal(t, "HMAC-SHA256 Signature="+sign("secret", canonical), req.Header.Get("Authorization"))
+	})
+
+	t.Run("signs the body", func(t *testing.T) {
+		t.Parallel()
+		auth := newTestHMACAuth(t, HMACConfig{Secret: []byte("secret")})
+		body := `{"script":"main.risor"}`
+		sum := sha256.Sum256([]byte(body))
+		bodyHash := hex.EncodeToString(sum[:])
+
+		// A body that can be read again, and one that can only be read once
+		for _, reader := range []io.Reader{strings.NewReader(body), io.MultiReader(strings.NewReader(body))} {
+			req, err := http.NewRequest(http.MethodPost, "https://artifacts.example.com/query", reader)
+			require.NoError(t, err)
+			require.NoError(t, auth.AuthenticateWithContext(t.Context(), req))
+			assert.Equal(t, bodyHash, req.Header.Get("X-Content-SHA256"))
+
+			sent, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(sent))
+		}
+	})
+
+	t.Run("custom scheme", func(t *testing.T) {
+		t.Parallel()
+		auth := newTestHMACAuth(t, HMACConfig{
+			KeyID:           "loader",
+			Secret:          []byte("secret"),
+			Hash:            sha512.New,
+			Algorithm:       "HMAC-SHA512",
+			SignatureHeader: "X-Signature",
+			TimestampHeader: "X-Date",
+			TimestampFormat: "20060102T150405Z",
+			BodyHashHeader:  "X-Body-Hash",
+			Canonicalize: func(req *http.Request, timestamp, bodyHash string) string {
+				return req.Method + " " + req.URL.Host + req.URL.Path + " " + timestamp
+			},
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "https://artifacts.example.com/main.risor", nil)
+		require.NoError(t, err)
+		require.NoError(t, auth.Authenticate(req))
+
+		mac := hmac.New(sha512.New, []byte("secret"))
+		mac.Write([]byte("GET artifacts.example.com/main.risor 20240506T070809Z"))
+		assert.Equal(t, "20240506T070809Z", req.Header.Get("X-Date"))
+		assert.Equal(t, emptyBodyHash, req.Header.Get("X-Body-Hash"))
+		assert.Equal(t,
+			"HMAC-SHA512 KeyId=loader, Signature="+hex.EncodeToString(mac.Sum(nil)),
+			req.Header.Get("X-Signature"),
+		)
+		assert.Empty(t, req.Header.Get("Authorization"))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		t.Parallel()
+		auth := newTestHMACAuth(t, HMACConfig{Secret: []byte("secret")})
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		req, err := http.NewRequest(http.MethodGet, "https://artifacts.example.com", nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, auth.AuthenticateWithContext(ctx, req), context.Canceled)
+		assert.Empty(t, req.Header.Get("Authorization"))
+	})
+
+	t.Run("requires a secret", func(t *testing.T) {
+		t.Parallel()
+		auth, err := NewHMACAuth(HMACConfig{KeyID: "loader"})
+		require.ErrorContains(t, err, "HMAC secret is required")
+		require.Nil(t, auth)
+	})
+}
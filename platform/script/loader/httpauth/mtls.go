@@ -0,0 +1,256 @@
+package httpauth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientCertificateConfig configures a ClientCertificate.
+type ClientCertificateConfig struct {
+	// CertFile and KeyFile are the PEM encoded client certificate, with any intermediates, and
+	// its private key
+	CertFile string
+	KeyFile  string
+
+	// CAFile is a PEM bundle of the certificate authorities trusted to sign the server's
+	// certificate. When empty, the system roots are used.
+	CAFile string
+
+	// LogHandler receives a log record when a changed file can't be loaded, and the previous
+	// certificate or CA bundle is kept. Default is nil (slog.Default() is used).
+	LogHandler slog.Handler
+}
+
+// ClientCertificate implements mutual TLS authentication with a client certificate loaded from
+// files. The files are checked for changes on each TLS handshake, and read again when they've
+// changed, so a long-lived loader keeps working when short-lived certificates are rotated. When a
+// changed file can't be loaded, such as while a rotation is half written, the previous
+// certificate is used until the next handshake. Connections that are already open keep the
+// certificate they were made with.
+//
+// Unlike the other authenticators, a client certificate is applied to the TLS connection rather
+// than to each request, so it's set with HTTPOptions.WithClientCertificate.
+type ClientCertificate struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certStamp [2]fileStamp
+	roots     *x509.CertPool
+	rootStamp fileStamp
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewClientCertificate creates a client certificate authenticator, and loads the files, so a
+// missing or invalid file is reported straight away.
+//
+// Example:
+//
+//	cert, err := httpauth.NewClientCertificate(httpauth.ClientCertificateConfig{
+//	    CertFile: "/var/run/certs/client.crt",
+//	    KeyFile:  "/var/run/certs/client.key",
+//	    CAFile:   "/var/run/certs/ca.crt",
+//	})
+//	options := loader.DefaultHTTPOptions().WithClientCertificate(cert)
+func NewClientCertificate(config ClientCertificateConfig) (*ClientCertificate, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("certificate and key files are required")
+	}
+
+	logger := slog.Default()
+	if config.LogHandler != nil {
+		logger = slog.New(config.LogHandler)
+	}
+
+	c := &ClientCertificate{
+		certFile: config.CertFile,
+		keyFile:  config.KeyFile,
+		caFile:   config.CAFile,
+		logger:   logger.With("auth", "client-certificate"),
+	}
+	if _, err := c.Certificate(); err != nil {
+		return nil, err
+	}
+	if c.caFile != "" {
+		if _, err := c.RootCAs(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// TLSConfig returns a TLS configuration that presents the current client certificate, and
+// trusts the CA bundle when one is configured.
+func (c *ClientCertificate) TLSConfig() *tls.Config {
+	return c.ConfigureTLS(nil)
+}
+
+// ConfigureTLS returns a copy of config that presents the current client certificate. When a
+// CA bundle is configured, the copy's RootCAs are the bundle as it is now, and DialTLSContext
+// picks up a rotated bundle. The rest of config, such as the minimum version or the server
+// name, is kept. When config is nil, the copy starts from an empty configuration with a
+// minimum version of TLS 1.2.
+func (c *ClientCertificate) ConfigureTLS(config *tls.Config) *tls.Config {
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		config = config.Clone()
+	}
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return c.Certificate()
+	}
+	if roots, err := c.RootCAs(); err == nil && roots != nil {
+		config.RootCAs = roots
+	}
+	return config
+}
+
+// DialTLSContext returns a function for http.Transport.DialTLSContext, which dials with dial,
+// and makes a TLS connection with a copy of config. RootCAs can't change after a config is in
+// use, so each connection's copy gets the CA bundle as it is at that handshake. The server's
+// certificate is verified by crypto/tls against the host that was dialed, including IP
+// addresses, unless config sets a ServerName.
+func (c *ClientCertificate) DialTLSContext(
+	config *tls.Config,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		connConfig := config.Clone()
+		roots, err := c.RootCAs()
+		if err != nil {
+			return nil, err
+		}
+		if roots != nil {
+			connConfig.RootCAs = roots
+		}
+		if connConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			connConfig.ServerName = host
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, connConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			if closeErr := conn.Close(); closeErr != nil {
+				err = errors.Join(err, closeErr)
+			}
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// Identity identifies the client by the subject and issuer of its certificate, which stay the
+// same when the certificate is rotated. It's empty when the certificate can't be parsed.
+func (c *ClientCertificate) Identity() string {
+	cert, err := c.Certificate()
+	if err != nil || len(cert.Certificate) == 0 {
+		return ""
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return ""
+	}
+	return joinIdentity("ClientCertificate", string(leaf.RawSubject), string(leaf.RawIssuer))
+}
+
+// Certificate returns the client certificate, reading the files again when they've changed.
+func (c *ClientCertificate) Certificate() (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	certStamp, certErr := stat(c.certFile)
+	keyStamp, keyErr := stat(c.keyFile)
+	stamp := [2]fileStamp{certStamp, keyStamp}
+	// A file that's missing during a rotation is loaded once it's back
+	changed := certErr == nil && keyErr == nil && stamp != c.certStamp
+	if c.cert != nil && !changed {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		err = fmt.Errorf("failed to load client certificate: %w", err)
+		if c.cert == nil {
+			return nil, err
+		}
+		c.logger.Warn("Using the previous client certificate", "error", err)
+		return c.cert, nil
+	}
+	c.cert = &cert
+	c.certStamp = stamp
+	return c.cert, nil
+}
+
+// RootCAs returns the certificate authorities from the CA bundle, reading it again when it has
+// changed, or nil when there's no CA bundle.
+func (c *ClientCertificate) RootCAs() (*x509.CertPool, error) {
+	if c.caFile == "" {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stamp, statErr := stat(c.caFile)
+	changed := statErr == nil && stamp != c.rootStamp
+	if c.roots != nil && !changed {
+		return c.roots, nil
+	}
+
+	roots, err := loadCertPool(c.caFile)
+	if err != nil {
+		if c.roots == nil {
+			return nil, err
+		}
+		c.logger.Warn("Using the previous CA bundle", "error", err)
+		return c.roots, nil
+	}
+	c.roots = roots
+	c.rootStamp = stamp
+	return c.roots, nil
+}
+
+// loadCertPool reads a PEM bundle of certificates
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle: %s", path)
+	}
+	return pool, nil
+}
+
+// stat returns the current version of a file
+func stat(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
@@ -0,0 +1,384 @@
+package httpauth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA is a certificate authority for test certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue creates a certificate signed by the CA, returning the PEM encoded certificate and key.
+// Server certificates are valid for the hosts, or localhost and 127.0.0.1 when there are none.
+func (ca *testCA) issue(t *testing.T, name string, server bool, hosts ...string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		if len(hosts) == 0 {
+			hosts = []string{"localhost", "127.0.0.1"}
+		}
+		for _, host := range hosts {
+			if ip := net.ParseIP(host); ip != nil {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			} else {
+				template.DNSNames = append(template.DNSNames, host)
+			}
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// certFiles are the files of a client certificate, which the tests rotate
+type certFiles struct {
+	cert, key, ca string
+	version       int
+}
+
+func newCertFiles(t *testing.T) *certFiles {
+	t.Helper()
+	dir := t.TempDir()
+	return &certFiles{
+		cert: filepath.Join(dir, "client.crt"),
+		key:  filepath.Join(dir, "client.key"),
+		ca:   filepath.Join(dir, "ca.crt"),
+	}
+}
+
+// write replaces a file, with a modification time that's newer than the previous version
+func (f *certFiles) write(t *testing.T, path string, data []byte) {
+	t.Helper()
+	f.version++
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	modTime := time.Now().Add(time.Duration(f.version) * time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// writeCert replaces the client certificate and key with a new certificate from the CA
+func (f *certFiles) writeCert(t *testing.T, ca *testCA, name string) {
+	t.Helper()
+	cert, key := ca.issue(t, name, false)
+	f.write(t, f.cert, cert)
+	f.write(t, f.key, key)
+}
+
+// leafName returns the common name of a certificate's leaf
+func leafName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestClientCertificate_Reload(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t, "client CA")
+	files := newCertFiles(t)
+	files.writeCert(t, ca, "client-1")
+
+	var logs bytes.Buffer
+	auth, err := NewClientCertificate(ClientCertificateConfig{
+		CertFile:   files.cert,
+		KeyFile:    files.key,
+		LogHandler: slog.NewTextHandler(&logs, nil),
+	})
+	require.NoError(t, err)
+
+	cert, err := auth.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, "client-1", leafName(t, cert))
+
+	roots, err := auth.RootCAs()
+	require.NoError(t, err)
+	require.Nil(t, roots)
+
+	// A certificate for the same subject keeps the identity
+	identity := auth.Identity()
+	require.NotEmpty(t, identity)
+	files.writeCert(t, ca, "client-1")
+	_, err = auth.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, identity, auth.Identity())
+
+	// The rotated certificate is loaded
+	files.writeCert(t, ca, "client-2")
+	cert, err = auth.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, "client-2", leafName(t, cert))
+	require.NotEqual(t, identity, auth.Identity())
+
+	// A certificate that doesn't match the key yet keeps the previous one
+	newCert, newKey := ca.issue(t, "client-3", false)
+	files.write(t, files.cert, newCert)
+	cert, err = auth.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, "client-2", leafName(t, cert))
+	require.Contains(t, logs.String(), "Using the previous client certificate")
+
+	// Until the key is written
+	files.write(t, files.key, newKey)
+	cert, err = auth.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, "client-3", leafName(t, cert))
+
+	// And missing files keep the previous one
+	require.NoError(t, os.Remove(files.key))
+	cert, err = auth.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, "client-3", leafName(t, cert))
+}
+
+func TestClientCertificate_MutualTLS(t *testing.T) {
+	t.Parallel()
+
+	clientCA := newTestCA(t, "client CA")
+	serverCA := newTestCA(t, "server CA")
+	rotatedServerCA := newTestCA(t, "rotated server CA")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+
+	var serverCert atomic.Pointer[tls.Certificate]
+	setServerCert := func(ca *testCA, hosts ...string) {
+		cert, err := tls.X509KeyPair(ca.issue(t, "server", true, hosts...))
+		require.NoError(t, err)
+		serverCert.Store(&cert)
+	}
+	setServerCert(serverCA)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+		assert.NoError(t, err)
+	}))
+	// Clients dial an IP address, which isn't sent with SNI, so the server's certificate is set
+	// for each connection rather than chosen by GetCertificate
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+				Certificates: []tls.Certificate{*serverCert.Load()},
+			}, nil
+		},
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	files := newCertFiles(t)
+	files.writeCert(t, clientCA, "client-1")
+	files.write(t, files.ca, serverCA.pem)
+
+	auth, err := NewClientCertificate(ClientCertificateConfig{
+		CertFile: files.cert,
+		KeyFile:  files.key,
+		CAFile:   files.ca,
+	})
+	require.NoError(t, err)
+
+	// Every request makes a new connection, so each one has a handshake
+	client := &http.Client{Transport: &http.Transport{
+		DialTLSContext:    auth.DialTLSContext(auth.TLSConfig(), (&net.Dialer{}).DialContext),
+		DisableKeepAlives: true,
+	}}
+	get := func() (string, error) {
+		// The server is dialed by IP address, so its certificate is verified against the IP
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return "", err
+		}
+		defer func() { assert.NoError(t, resp.Body.Close()) }()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	name, err := get()
+	require.NoError(t, err)
+	require.Equal(t, "client-1", name)
+
+	files.writeCert(t, clientCA, "client-2")
+	name, err = get()
+	require.NoError(t, err)
+	require.Equal(t, "client-2", name)
+
+	// A server certificate from a CA that isn't trusted yet is rejected
+	setServerCert(rotatedServerCA)
+	_, err = get()
+	require.ErrorContains(t, err, "certificate signed by unknown authority")
+
+	// Until the CA bundle is updated
+	files.write(t, files.ca, append(append([]byte{}, serverCA.pem...), rotatedServerCA.pem...))
+	name, err = get()
+	require.NoError(t, err)
+	require.Equal(t, "client-2", name)
+
+	// A certificate from a trusted CA is rejected when it's for another host
+	setServerCert(serverCA, "scripts.example.com", "10.0.0.1")
+	_, err = get()
+	require.ErrorContains(t, err, "certificate is valid for 10.0.0.1, not 127.0.0.1")
+}
+
+func TestClientCertificate_ConfigureTLS(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t, "client CA")
+	files := newCertFiles(t)
+	files.writeCert(t, ca, "client")
+	files.write(t, files.ca, ca.pem)
+
+	auth, err := NewClientCertificate(ClientCertificateConfig{
+		CertFile: files.cert,
+		KeyFile:  files.key,
+		CAFile:   files.ca,
+	})
+	require.NoError(t, err)
+
+	t.Run("nil config", func(t *testing.T) {
+		t.Parallel()
+		config := auth.ConfigureTLS(nil)
+		require.NotNil(t, config.GetClientCertificate)
+		assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	})
+
+	t.Run("existing config is copied", func(t *testing.T) {
+		t.Parallel()
+		base := &tls.Config{
+			MinVersion: tls.VersionTLS13,
+			ServerName: "scripts.internal",
+			NextProtos: []string{"http/1.1"},
+		}
+		config := auth.ConfigureTLS(base)
+
+		assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+		assert.Equal(t, "scripts.internal", config.ServerName)
+		assert.Equal(t, []string{"http/1.1"}, config.NextProtos)
+		cert, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, "client", leafName(t, cert))
+
+		assert.Nil(t, base.GetClientCertificate, "the existing config is unchanged")
+		assert.Nil(t, base.VerifyConnection)
+	})
+}
+
+func TestNewClientCertificate_Errors(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t, "client CA")
+	files := newCertFiles(t)
+	files.writeCert(t, ca, "client")
+	dir := t.TempDir()
+	emptyCA := filepath.Join(dir, "empty.crt")
+	require.NoError(t, os.WriteFile(emptyCA, []byte("no certificates"), 0o600))
+
+	tests := []struct {
+		name   string
+		config ClientCertificateConfig
+		errMsg string
+	}{
+		{
+			name:   "missing key file",
+			config: ClientCertificateConfig{CertFile: files.cert},
+			errMsg: "certificate and key files are required",
+		},
+		{
+			name:   "unreadable certificate",
+			config: ClientCertificateConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: files.key},
+			errMsg: "failed to load client certificate",
+		},
+		{
+			name:   "mismatched key",
+			config: ClientCertificateConfig{CertFile: files.cert, KeyFile: files.cert},
+			errMsg: "failed to load client certificate",
+		},
+		{
+			name: "missing CA bundle",
+			config: ClientCertificateConfig{
+				CertFile: files.cert,
+				KeyFile:  files.key,
+				CAFile:   filepath.Join(dir, "missing.crt"),
+			},
+			errMsg: "failed to read CA bundle",
+		},
+		{
+			name:   "empty CA bundle",
+			config: ClientCertificateConfig{CertFile: files.cert, KeyFile: files.key, CAFile: emptyCA},
+			errMsg: "no certificates found in CA bundle",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			auth, err := NewClientCertificate(tc.config)
+			require.ErrorContains(t, err, tc.errMsg)
+			require.Nil(t, auth)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package httpauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientCredentialsConfig configures a ClientCredentials authenticator.
+type ClientCredentialsConfig struct {
+	// TokenURL is the token endpoint of the identity provider
+	TokenURL string
+
+	// ClientID and ClientSecret identify the client, and are sent to the token endpoint with
+	// HTTP Basic authentication, as described in RFC 6749 section 2.3.1
+	ClientID     string
+	ClientSecret string
+
+	// Scopes are requested for the access token. When empty, no scope is requested.
+	Scopes []string
+
+	// EndpointParams are additional parameters for the token request, such as an audience
+	EndpointParams url.Values
+
+	// ExpiryDelta is how long before its expiry a token is replaced, so it doesn't expire
+	// while a request is in flight. Default is 30 seconds when zero.
+	ExpiryDelta time.Duration
+
+	// Client makes the token requests. Default is a client with a 30 second timeout when nil.
+	Client *http.Client
+}
+
+// ClientCredentials implements the OAuth2 client credentials flow from RFC 6749 section 4.4.
+// It fetches an access token from the token endpoint, caches it until shortly before it
+// expires, and sets it as a Bearer token in the Authorization header. When several requests need
+// a new token at the same time, only one token request is made. It's safe for concurrent use.
+type ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	params       url.Values
+	expiryDelta  time.Duration
+	client       *http.Client
+	now          func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	pending   *tokenFetch
+}
+
+// tokenFetch is a token request shared by the callers waiting for it
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// tokenResponse is the token endpoint's response, as described in RFC 6749 section 5
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`      //nolint:tagliatelle // OAuth2 wire format
+	TokenType        string `json:"token_type"`        //nolint:tagliatelle // OAuth2 wire format
+	ExpiresIn        int64  `json:"expires_in"`        //nolint:tagliatelle // OAuth2 wire format
+	Error            string `json:"error"`             //nolint:tagliatelle // OAuth2 wire format
+	ErrorDescription string `json:"error_description"` //nolint:tagliatelle // OAuth2 wire format
+}
+
+// NewClientCredentials creates an authenticator for the OAuth2 client credentials flow.
+//
+// Example:
+//
+//	auth, err := httpauth.NewClientCredentials(httpauth.ClientCredentialsConfig{
+//	    TokenURL:     "https://idp.example.com/oauth2/token",
+//	    ClientID:     "script-loader",
+//	    ClientSecret: os.Getenv("SCRIPT_LOADER_SECRET"),
+//	    Scopes:       []string{"scripts.read"},
+//	})
+//	options := loader.DefaultHTTPOptions().WithAuthenticator(auth)
+func NewClientCredentials(config ClientCredentialsConfig) (*ClientCredentials, error) {
+	tokenURL, err := url.Parse(config.TokenURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse token URL: %w", err)
+	}
+	if tokenURL.Scheme != "http" && tokenURL.Scheme != "https" {
+		return nil, fmt.Errorf("token URL must be http or https: %s", config.TokenURL)
+	}
+	if config.ClientID == "" {
+		return nil, errors.New("client ID is required")
+	}
+	if config.ExpiryDelta < 0 {
+		return nil, errors.New("expiry delta cannot be negative")
+	}
+
+	params := url.Values{}
+	for key, values := range config.EndpointParams {
+		params[key] = append([]string(nil), values...)
+	}
+	params.Set("grant_type", "client_credentials")
+	if len(config.Scopes) > 0 {
+		params.Set("scope", strings.Join(config.Scopes, " "))
+	}
+
+	expiryDelta := config.ExpiryDelta
+	if expiryDelta == 0 {
+		expiryDelta = 30 * time.Second
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &ClientCredentials{
+		tokenURL:     config.TokenURL,
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		params:       params,
+		expiryDelta:  expiryDelta,
+		client:       client,
+		now:          time.Now,
+	}, nil
+}
+
+// Authenticate sets the access token as a Bearer token in the Authorization header, fetching a
+// new token with the request's context when the cached one is missing or about to expire.
+func (c *ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := c.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// AuthenticateWithContext applies OAuth2 authentication with context support.
+// This respects context cancellation while applying authentication, and while waiting for a token.
+func (c *ClientCredentials) AuthenticateWithContext(ctx context.Context, req *http.Request) error {
+	return applyAuthWithContext(ctx, req, c.Authenticate)
+}
+
+// Name returns the name of the authentication method.
+func (c *ClientCredentials) Name() string {
+	return "OAuth2ClientCredentials"
+}
+
+// Identity implements Identifier, and identifies the token endpoint, the client, and the token
+// request's parameters, such as its scopes. The access token isn't part of it, because it
+// changes while the client stays the same.
+func (c *ClientCredentials) Identity() string {
+	return joinIdentity(c.Name(), c.tokenURL, c.clientID, c.clientSecret, c.params.Encode())
+}
+
+// Invalidate discards the cached token when it's the one the rejected request was sent with, so
+// the next request fetches a new one. A token that was already replaced by another request is
+// kept, so concurrent rejections only cause one token request.
+func (c *ClientCredentials) Invalidate(req *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && req.Header.Get("Authorization") == "Bearer "+c.token {
+		c.token = ""
+		c.refreshAt = time.Time{}
+	}
+}
+
+// Token returns a valid access token, from the cache or from the token endpoint. Callers that
+// need a new token while another caller is fetching one wait for its result.
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+	for {
+		c.mu.Lock()
+		if c.token != "" && (c.refreshAt.IsZero() || c.now().Before(c.refreshAt)) {
+			token := c.token
+			c.mu.Unlock()
+			return token, nil
+		}
+
+		fetch := c.pending
+		if fetch == nil {
+			fetch = &tokenFetch{done: make(chan struct{})}
+			c.pending = fetch
+			c.mu.Unlock()
+			c.fetch(ctx, fetch)
+			return fetch.token, fetch.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-fetch.done:
+		}
+		// The fetch was cancelled by its caller's context, so try again with this one
+		if fetch.err != nil && ctx.Err() == nil &&
+			(errors.Is(fetch.err, context.Canceled) || errors.Is(fetch.err, context.DeadlineExceeded)) {
+			continue
+		}
+		return fetch.token, fetch.err
+	}
+}
+
+// fetch requests a token, caches it when the request succeeds, and wakes the waiting callers
+func (c *ClientCredentials) fetch(ctx context.Context, fetch *tokenFetch) {
+	start := c.now()
+	token, expiresIn, err := c.requestToken(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = nil
+	fetch.token, fetch.err = token, err
+	if err == nil {
+		c.token = token
+		c.refreshAt = time.Time{}
+		if expiresIn > 0 {
+			// A short-lived token is used for at least half its lifetime
+			c.refreshAt = start.Add(expiresIn - min(c.expiryDelta, expiresIn/2))
+		}
+	}
+	close(fetch.done)
+}
+
+// requestToken sends a token request to the token endpoint
+func (c *ClientCredentials) requestToken(ctx context.Context) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.tokenURL,
+		strings.NewReader(c.params.Encode()),
+	)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Default().Debug("Failed to close token response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var token tokenResponse
+	decodeErr := json.Unmarshal(body, &token)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil && token.Error != "" {
+			if token.ErrorDescription != "" {
+				return "", 0, fmt.Errorf("token request failed: HTTP %d: %s: %s",
+					resp.StatusCode, token.Error, token.ErrorDescription)
+			}
+			return "", 0, fmt.Errorf("token request failed: HTTP %d: %s", resp.StatusCode, token.Error)
+		}
+		return "", 0, fmt.Errorf("token request failed: HTTP %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return "", 0, fmt.Errorf("failed to decode token response: %w", decodeErr)
+	}
+	if token.AccessToken == "" {
+		return "", 0, errors.New("token response has no access token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type: %s", token.TokenType)
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,326 @@
+package httpauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenServer creates a token endpoint that issues numbered tokens, which expire after
+// expiresIn seconds
+func newTokenServer(t *testing.T, expiresIn int, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := requests.Add(1)
+
+		// The credentials are form encoded before they're sent with Basic authentication
+		id, secret, ok := r.BasicAuth()
+		if r.Method != http.MethodPost || !ok || id != "client" || secret != url.QueryEscape("s3cret&") ||
+			r.PostFormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string]string{
+				"error":             "invalid_client",
+				"error_description": "unknown client",
+			}))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", count),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+			"scope":        r.PostFormValue("scope"),
+		}))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClientCredentials creates an authenticator for the token server
+func newTestClientCredentials(t *testing.T, tokenURL string) *ClientCredentials {
+	t.Helper()
+	auth, err := NewClientCredentials(ClientCredentialsConfig{
+		TokenURL:     tokenURL,
+		ClientID:     "client",
+		ClientSecret: "s3cret&",
+		Scopes:       []string{"scripts.read", "scripts.list"},
+	})
+	require.NoError(t, err)
+	return auth
+}
+
+// authorization authenticates a new request, and returns its Authorization header
+func authorization(t *testing.T, auth Authenticator) string {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://localhost/test", nil)
+	require.NoError(t, err)
+	require.NoError(t, auth.AuthenticateWithContext(t.Context(), req))
+	return req.Header.Get("Authorization")
+}
+
+func TestClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	t.Run("caches the token", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := newTokenServer(t, 3600, &requests)
+		auth := newTestClientCredentials(t, server.URL)
+		require.Equal(t, "OAuth2ClientCredentials", auth.Name())
+
+		for range 3 {
+			require.Equal(t, "Bearer token-1", authorization(t, auth))
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("sends scopes and endpoint params", func(t *testing.T) {
+		t.Parallel()
+		var form url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseForm())
+			form = r.PostForm
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"access_token": "abc"}))
+		}))
+		t.Cleanup(server.Close)
+
+		auth, err := NewClientCredentials(ClientCredentialsConfig{
+			TokenURL:       server.URL,
+			ClientID:       "client",
+			Scopes:         []string{"a", "b"},
+			EndpointParams: map[string][]string{"audience": {"scripts"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Bearer abc", authorization(t, auth))
+		assert.Equal(t, "client_credentials", form.Get("grant_type"))
+		assert.Equal(t, "a b", form.Get("scope"))
+		assert.Equal(t, "scripts", form.Get("audience"))
+	})
+
+	t.Run("refreshes before expiry", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := newTokenServer(t, 3600, &requests)
+		auth := newTestClientCredentials(t, server.URL)
+
+		require.Equal(t, "Bearer token-1", authorization(t, auth))
+
+		// Still valid, but within the expiry delta
+		auth.now = func() time.Time { return time.Now().Add(3600*time.Second - 10*time.Second) }
+		require.Equal(t, "Bearer token-2", authorization(t, auth))
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("short-lived tokens are used for half their lifetime", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := newTokenServer(t, 20, &requests)
+		auth := newTestClientCredentials(t, server.URL)
+
+		require.Equal(t, "Bearer token-1", authorization(t, auth))
+		require.Equal(t, "Bearer token-1", authorization(t, auth))
+
+		auth.now = func() time.Time { return time.Now().Add(11 * time.Second) }
+		require.Equal(t, "Bearer token-2", authorization(t, auth))
+	})
+
+	t.Run("concurrent requests share one token request", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			<-release
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "shared",
+				"expires_in":   3600,
+			}))
+		}))
+		t.Cleanup(server.Close)
+		auth := newTestClientCredentials(t, server.URL)
+
+		var wg sync.WaitGroup
+		headers := make([]string, 20)
+		for i := range headers {
+			wg.Go(func() {
+				headers[i] = authorization(t, auth)
+			})
+		}
+		require.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		for _, header := range headers {
+			assert.Equal(t, "Bearer shared", header)
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := newTokenServer(t, 3600, &requests)
+		auth := newTestClientCredentials(t, server.URL)
+		var _ Invalidator = auth
+
+		rejected, err := http.NewRequest(http.MethodGet, "http://localhost/test", nil)
+		require.NoError(t, err)
+		require.NoError(t, auth.Authenticate(rejected))
+		require.Equal(t, "Bearer token-1", rejected.Header.Get("Authorization"))
+
+		auth.Invalidate(rejected)
+		require.Equal(t, "Bearer token-2", authorization(t, auth))
+
+		// A request sent with an older token doesn't discard the new one
+		auth.Invalidate(rejected)
+		require.Equal(t, "Bearer token-2", authorization(t, auth))
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := newTokenServer(t, 3600, &requests)
+		auth := newTestClientCredentials(t, server.URL)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/test", nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, auth.AuthenticateWithContext(ctx, req), context.Canceled)
+		assert.Empty(t, req.Header.Get("Authorization"))
+		assert.Equal(t, int32(0), requests.Load())
+	})
+}
+
+func TestClientCredentials_TokenErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		errMsg string
+	}{
+		{
+			name:   "OAuth2 error",
+			status: http.StatusBadRequest,
+			body:   `{"error":"invalid_scope","error_description":"scope is not allowed"}`,
+			errMsg: "token request failed: HTTP 400: invalid_scope: scope is not allowed",
+		},
+		{
+			name:   "server error",
+			status: http.StatusBadGateway,
+			body:   "<html>bad gateway</html>",
+			errMsg: "token request failed: HTTP 502",
+		},
+		{
+			name:   "invalid JSON",
+			status: http.StatusOK,
+			body:   "not json",
+			errMsg: "failed to decode token response",
+		},
+		{
+			name:   "missing access token",
+			status: http.StatusOK,
+			body:   `{"token_type":"Bearer"}`,
+			errMsg: "token response has no access token",
+		},
+		{
+			name:   "unsupported token type",
+			status: http.StatusOK,
+			body:   `{"access_token":"abc","token_type":"mac"}`,
+			errMsg: "unsupported token type: mac",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, err := w.Write([]byte(tc.body))
+				assert.NoError(t, err)
+			}))
+			t.Cleanup(server.Close)
+			auth := newTestClientCredentials(t, server.URL)
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/test", nil)
+			require.NoError(t, err)
+			err = auth.Authenticate(req)
+			require.ErrorContains(t, err, tc.errMsg)
+			assert.Empty(t, req.Header.Get("Authorization"))
+		})
+	}
+
+	t.Run("invalid client", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := newTokenServer(t, 3600, &requests)
+		auth, err := NewClientCredentials(ClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "client",
+			ClientSecret: "wrong",
+		})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/test", nil)
+		require.NoError(t, err)
+		require.ErrorContains(t, auth.Authenticate(req), "HTTP 401: invalid_client: unknown client")
+	})
+}
+
+func TestNewClientCredentials_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config ClientCredentialsConfig
+		errMsg string
+	}{
+		{
+			name:   "invalid token URL",
+			config: ClientCredentialsConfig{TokenURL: "http://[::1", ClientID: "client"},
+			errMsg: "unable to parse token URL",
+		},
+		{
+			name:   "unsupported scheme",
+			config: ClientCredentialsConfig{TokenURL: "ftp://idp.example.com/token", ClientID: "client"},
+			errMsg: "token URL must be http or https",
+		},
+		{
+			name:   "missing client ID",
+			config: ClientCredentialsConfig{TokenURL: "https://idp.example.com/token"},
+			errMsg: "client ID is required",
+		},
+		{
+			name: "negative expiry delta",
+			config: ClientCredentialsConfig{
+				TokenURL:    "https://idp.example.com/token",
+				ClientID:    "client",
+				ExpiryDelta: -time.Second,
+			},
+			errMsg: "expiry delta cannot be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			auth, err := NewClientCredentials(tc.config)
+			require.ErrorContains(t, err, tc.errMsg)
+			require.Nil(t, auth)
+		})
+	}
+}
@@ -3,21 +3,26 @@ package loader
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
 )
 
 // InferLoader determines the appropriate loader for the given input.
 //
 // For string inputs, it applies the following checks in order:
-//  1. URI scheme detection (http/https -> HTTP, file -> Disk)
+//  1. URI scheme detection (http/https -> HTTP, s3 -> S3, file -> Disk)
 //  2. File path format detection (absolute paths, known extensions)
 //  3. Base64 validation (attempts decode)
 //  4. String fallback
 //
 // Other input types:
 //   - []byte: FromBytes loader
+//   - fs.File: FromIoReader loader, named after the file
 //   - io.Reader: FromIoReader loader
 //   - Loader: returned unchanged
 //
@@ -28,6 +33,8 @@ func InferLoader(input any) (Loader, error) {
 		return inferFromString(v)
 	case []byte:
 		return NewFromBytes(v)
+	case fs.File:
+		return newFromFSFile(v)
 	case io.Reader:
 		return NewFromIoReader(v, "inferred")
 	case Loader:
@@ -50,6 +57,8 @@ func inferFromString(input string) (Loader, error) {
 		switch parsed.Scheme {
 		case "http", "https":
 			return NewFromHTTP(input)
+		case "s3":
+			return NewFromS3(input)
 		case "file":
 			path := parsed.Path
 			if !filepath.IsAbs(path) {
@@ -82,6 +91,10 @@ func inferFromString(input string) (Loader, error) {
 	return NewFromStringBase64(input)
 }
 
+// knownExtensions are recognized as script files even when no engine has registered them, so
+// inferring a loader doesn't depend on which engine packages are imported
+var knownExtensions = []string{".wasm", ".risor", ".star", ".starlark"}
+
 // isValidFilePath checks if a string looks like a valid file path format.
 func isValidFilePath(s string) bool {
 	// Don't consider strings with newlines/carriage returns as file paths
@@ -89,10 +102,9 @@ func isValidFilePath(s string) bool {
 		return false
 	}
 
-	// Check for specific script file extensions (case insensitive)
-	validExtensions := []string{".wasm", ".risor", ".star", ".starlark"}
+	// Check for script file extensions, known or registered by the engines (case insensitive)
 	lower := strings.ToLower(s)
-	for _, ext := range validExtensions {
+	for _, ext := range slices.Concat(knownExtensions, machineTypes.Extensions()) {
 		if strings.HasSuffix(lower, ext) {
 			return true
 		}
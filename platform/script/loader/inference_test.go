@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	machineTypes "github.com/robbyt/go-polyscript/engines/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,6 +35,11 @@ func TestInferLoader(t *testing.T) {
 				input:        "https://example.com/script.risor",
 				expectedType: (*FromHTTP)(nil),
 			},
+			{
+				name:         "S3 URL",
+				input:        "s3://scripts/plugins/script.wasm",
+				expectedType: (*FromS3)(nil),
+			},
 			{
 				name:         "file URL",
 				input:        "file:///path/to/script.star",
@@ -162,6 +168,27 @@ func TestInferLoader(t *testing.T) {
 		assert.IsType(t, (*FromIoReader)(nil), result)
 	})
 
+	t.Run("file inputs", func(t *testing.T) {
+		for _, name := range []string{"rule.risor", "my rule.risor", "100%.risor"} {
+			t.Run(name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), name)
+				require.NoError(t, os.WriteFile(path, []byte(`print("hello")`), 0o600))
+				file, err := os.Open(path)
+				require.NoError(t, err)
+				t.Cleanup(func() { assert.NoError(t, file.Close()) })
+
+				result, err := InferLoader(file)
+				require.NoError(t, err)
+				require.IsType(t, (*FromIoReader)(nil), result)
+				assert.Equal(t, name, result.GetSourceURL().Host)
+
+				reader, err := result.GetReader()
+				require.NoError(t, err)
+				verifyReaderContent(t, reader, `print("hello")`)
+			})
+		}
+	})
+
 	t.Run("existing loader input", func(t *testing.T) {
 		originalLoader, err := NewFromString("test content")
 		require.NoError(t, err)
@@ -216,6 +243,11 @@ func TestInferFromString(t *testing.T) {
 				input:        "https://api.example.com/script.risor",
 				expectedType: (*FromHTTP)(nil),
 			},
+			{
+				name:         "s3 scheme",
+				input:        "s3://scripts/rules/test.star?versionId=abc",
+				expectedType: (*FromS3)(nil),
+			},
 			{
 				name:         "file scheme",
 				input:        "file:///usr/local/scripts/test.star",
@@ -662,6 +694,35 @@ func TestInferLoader_AmbiguousContentDetection(t *testing.T) {
 	}
 }
 
+// TestInferLoader_RegisteredExtensions tests that extensions registered by other engines are
+// recognized as file paths.
+func TestInferLoader_RegisteredExtensions(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, machineTypes.Register("inference-test", ".inftest"))
+
+	result, err := InferLoader("rules/policy.inftest")
+	require.NoError(t, err)
+	assert.IsType(t, (*FromDisk)(nil), result)
+
+	result, err = InferLoader("rules/policy.unregistered")
+	require.NoError(t, err)
+	assert.IsType(t, (*FromString)(nil), result)
+}
+
+// TestInferLoader_KnownExtensions tests that the extensions of the original engines are
+// recognized as file paths without importing any engine.
+func TestInferLoader_KnownExtensions(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"rule.star", "rules/rule.starlark", "rule.risor", "plugin.wasm"} {
+		require.NotContains(t, machineTypes.Extensions(), filepath.Ext(path))
+		result, err := InferLoader(path)
+		require.NoError(t, err)
+		assert.IsType(t, (*FromDisk)(nil), result, path)
+	}
+}
+
 // TestInferLoader_URLParsingEdgeCases tests graceful handling of URL parsing failures.
 func TestInferLoader_URLParsingEdgeCases(t *testing.T) {
 	t.Parallel()
@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"context"
 	"io"
 	"net/url"
 )
@@ -10,3 +11,19 @@ type Loader interface {
 	GetReader() (io.ReadCloser, error)
 	GetSourceURL() *url.URL
 }
+
+// Versioned is an optional interface for loaders that can tell when their content changes,
+// without the caller reading and hashing the whole script.
+//
+// Versions are opaque strings, and are only meaningful when compared to an earlier version
+// from the same loader: a different version means the content has changed.
+type Versioned interface {
+	GetVersion(ctx context.Context) (string, error)
+}
+
+// Wrapper is an optional interface for loaders that wrap another loader, such as Verifying.
+// Wrap returns a loader that applies the same rules to a different loader, so a caller that
+// replaces the wrapped loader, like a reload from a new path, keeps those rules.
+type Wrapper interface {
+	Wrap(ldr Loader) (Loader, error)
+}
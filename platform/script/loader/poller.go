@@ -0,0 +1,167 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robbyt/go-polyscript/internal/helpers"
+)
+
+// ChangeEvent is emitted by a Poller when the version of a loader's content changes.
+type ChangeEvent struct {
+	// Loader is the loader whose content changed.
+	Loader Loader
+
+	// PreviousVersion is the version before the change.
+	PreviousVersion string
+
+	// Version is the new version of the content.
+	Version string
+
+	// DetectedAt is when the change was detected.
+	DetectedAt time.Time
+}
+
+// Poller checks a versioned loader on an interval, and emits a ChangeEvent when the content
+// changes. Services can use the events to recompile a script only when it actually changed.
+//
+// Example:
+//
+//	poller, err := loader.NewPoller(ldr, 30*time.Second, logger.Handler())
+//	events, err := poller.Watch(ctx)
+//	for range events {
+//	    if err := evaluator.Reload(""); err != nil {
+//	        logger.Error("Reload failed", "error", err)
+//	    }
+//	}
+type Poller struct {
+	loader    Loader
+	versioned Versioned
+	interval  time.Duration
+	logger    *slog.Logger
+
+	// mu serializes checks, and guards the last version seen
+	mu      sync.Mutex
+	version string
+}
+
+// NewPoller creates a poller for the loader, which must implement Versioned.
+func NewPoller(ldr Loader, interval time.Duration, handler slog.Handler) (*Poller, error) {
+	if ldr == nil {
+		return nil, fmt.Errorf("loader is nil")
+	}
+
+	versioned, ok := ldr.(Versioned)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotVersioned, ldr)
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("poll interval must be greater than zero")
+	}
+
+	_, logger := helpers.SetupLogger(handler, "loader", "Poller")
+	if sourceURL := ldr.GetSourceURL(); sourceURL != nil {
+		logger = logger.With("source", sourceURL.String())
+	}
+
+	return &Poller{
+		loader:    ldr,
+		versioned: versioned,
+		interval:  interval,
+		logger:    logger,
+	}, nil
+}
+
+// Version returns the last version seen by the poller.
+func (p *Poller) Version() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.version
+}
+
+// Check gets the current version from the loader, and reports whether it differs from the last
+// version seen. The first check records the version without reporting a change.
+func (p *Poller) Check(ctx context.Context) (ChangeEvent, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	version, err := p.versioned.GetVersion(ctx)
+	if err != nil {
+		return ChangeEvent{}, false, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	previous := p.version
+	p.version = version
+	if previous == "" || previous == version {
+		return ChangeEvent{}, false, nil
+	}
+
+	return ChangeEvent{
+		Loader:          p.loader,
+		PreviousVersion: previous,
+		Version:         version,
+		DetectedAt:      time.Now(),
+	}, true, nil
+}
+
+// Watch records the current version, then checks for changes on every interval until the
+// context is done, when the returned channel is closed. Errors from later checks are logged,
+// and the previous version is kept, so a temporarily unavailable source isn't reported as a
+// change. A consumer that falls behind only misses intermediate versions; the event for the
+// latest change is always delivered.
+func (p *Poller) Watch(ctx context.Context) (<-chan ChangeEvent, error) {
+	if p.Version() == "" {
+		if _, _, err := p.Check(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	events := make(chan ChangeEvent, 1)
+	go p.run(ctx, events)
+	return events, nil
+}
+
+// run polls the loader until the context is done
+func (p *Poller) run(ctx context.Context, events chan ChangeEvent) {
+	defer close(events)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		event, changed, err := p.Check(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.logger.WarnContext(ctx, "Failed to check for changes", "error", err)
+			}
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		p.logger.InfoContext(ctx, "Content changed", "version", event.Version)
+
+		// Replace an event the consumer hasn't received yet, so it only sees the latest change
+		select {
+		case events <- event:
+		default:
+			select {
+			case stale := <-events:
+				event.PreviousVersion = stale.PreviousVersion
+			default:
+			}
+			events <- event
+		}
+	}
+}
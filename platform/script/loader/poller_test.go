@@ -0,0 +1,168 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// versionedLoader is a loader with a version that can be changed by tests
+type versionedLoader struct {
+	mu      sync.Mutex
+	version string
+	err     error
+}
+
+func (l *versionedLoader) GetReader() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(SimpleContent)), nil
+}
+
+func (l *versionedLoader) GetSourceURL() *url.URL {
+	return &url.URL{Scheme: "test", Path: "/versioned"}
+}
+
+func (l *versionedLoader) GetVersion(_ context.Context) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.version, l.err
+}
+
+func (l *versionedLoader) set(version string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.version, l.err = version, err
+}
+
+func TestNewPoller(t *testing.T) {
+	t.Parallel()
+
+	t.Run("versioned loader", func(t *testing.T) {
+		poller, err := NewPoller(&versionedLoader{version: "v1"}, time.Second, nil)
+		require.NoError(t, err)
+		require.NotNil(t, poller)
+	})
+
+	t.Run("loader without versioning", func(t *testing.T) {
+		_, err := NewPoller(NewMockLoaderWithContent([]byte(SimpleContent)), time.Second, nil)
+		require.ErrorIs(t, err, ErrNotVersioned)
+	})
+
+	t.Run("nil loader", func(t *testing.T) {
+		_, err := NewPoller(nil, time.Second, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		_, err := NewPoller(&versionedLoader{version: "v1"}, 0, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestPoller_Check(t *testing.T) {
+	t.Parallel()
+
+	ldr := &versionedLoader{version: "v1"}
+	poller, err := NewPoller(ldr, time.Second, nil)
+	require.NoError(t, err)
+
+	// the first check only records the version
+	_, changed, err := poller.Check(t.Context())
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Equal(t, "v1", poller.Version())
+
+	_, changed, err = poller.Check(t.Context())
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	ldr.set("v2", nil)
+	event, changed, err := poller.Check(t.Context())
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "v1", event.PreviousVersion)
+	require.Equal(t, "v2", event.Version)
+	require.Equal(t, ldr, event.Loader)
+	require.False(t, event.DetectedAt.IsZero())
+
+	ldr.set("", errors.New("unavailable"))
+	_, changed, err = poller.Check(t.Context())
+	require.Error(t, err)
+	require.False(t, changed)
+	require.Equal(t, "v2", poller.Version())
+}
+
+func TestPoller_Watch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("emits changes", func(t *testing.T) {
+		ldr := &versionedLoader{version: "v1"}
+		poller, err := NewPoller(ldr, 5*time.Millisecond, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		events, err := poller.Watch(ctx)
+		require.NoError(t, err)
+
+		ldr.set("", errors.New("temporarily unavailable"))
+		time.Sleep(20 * time.Millisecond)
+		ldr.set("v2", nil)
+
+		select {
+		case event := <-events:
+			require.Equal(t, "v1", event.PreviousVersion)
+			require.Equal(t, "v2", event.Version)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for change event")
+		}
+
+		cancel()
+		require.Eventually(t, func() bool {
+			_, open := <-events
+			return !open
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("coalesces unread changes", func(t *testing.T) {
+		ldr := &versionedLoader{version: "v1"}
+		poller, err := NewPoller(ldr, time.Hour, nil)
+		require.NoError(t, err)
+
+		events := make(chan ChangeEvent, 1)
+		_, _, err = poller.Check(t.Context())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		poller.interval = 5 * time.Millisecond
+		go poller.run(ctx, events)
+
+		ldr.set("v2", nil)
+		require.Eventually(t, func() bool { return len(events) == 1 }, time.Second, 5*time.Millisecond)
+		ldr.set("v3", nil)
+		require.Eventually(t, func() bool { return poller.Version() == "v3" }, time.Second, 5*time.Millisecond)
+		// give the poller time to replace the unread event
+		time.Sleep(20 * time.Millisecond)
+
+		event := <-events
+		require.Equal(t, "v1", event.PreviousVersion)
+		require.Equal(t, "v3", event.Version)
+	})
+
+	t.Run("initial check fails", func(t *testing.T) {
+		poller, err := NewPoller(&versionedLoader{err: errors.New("unavailable")}, time.Second, nil)
+		require.NoError(t, err)
+
+		events, err := poller.Watch(t.Context())
+		require.Error(t, err)
+		require.Nil(t, events)
+	})
+}
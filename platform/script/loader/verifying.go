@@ -0,0 +1,267 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// SignatureExtension is appended to a script's path or URL to find its detached signature
+const SignatureExtension = ".sig"
+
+// VerifyOptions contains the rules a verifying loader checks content against. Content is
+// accepted when it matches one of the pinned digests, or when its detached signature is valid
+// for one of the trusted keys.
+type VerifyOptions struct {
+	// Digests are the SHA256 digests of approved content, as hex strings, optionally prefixed
+	// with "sha256:"
+	Digests []string
+
+	// TrustedKeys are the ed25519 public keys that approved content can be signed with
+	TrustedKeys []ed25519.PublicKey
+
+	// Signature loads the detached signature, which is 64 bytes, either raw or base64 encoded.
+	// When nil, the signature is loaded from next to the script: its path or URL with
+	// SignatureExtension appended. This works for the FromDisk, FromFS, FromHTTP, and FromS3
+	// loaders.
+	Signature Loader
+}
+
+// Verifying wraps a loader, and only returns content that passes verification. Content is
+// checked every time it's read, so a script that is changed at its source, such as an HTTP
+// server, is rejected before it can be compiled.
+type Verifying struct {
+	loader    Loader
+	digests   []string
+	keys      []ed25519.PublicKey
+	signature Loader
+
+	// customSignature is VerifyOptions.Signature, which is nil when the signature is loaded
+	// from next to the script
+	customSignature Loader
+}
+
+// NewPinned creates a loader that only accepts content matching one of the SHA256 digests.
+// Several digests allow a script to be updated without a window where neither version loads.
+//
+// Example:
+//
+//	httpLoader, err := loader.NewFromHTTP("https://example.com/rules/discount.risor")
+//	ldr, err := loader.NewPinned(httpLoader, "9f86d081884c7d65...")
+func NewPinned(ldr Loader, digests ...string) (*Verifying, error) {
+	return NewVerifying(ldr, &VerifyOptions{Digests: digests})
+}
+
+// NewSigned creates a loader that only accepts content with a valid detached ed25519 signature
+// from one of the trusted keys. The signature is loaded from next to the script, e.g.
+// "https://example.com/rules/discount.risor.sig".
+func NewSigned(ldr Loader, trustedKeys ...ed25519.PublicKey) (*Verifying, error) {
+	return NewVerifying(ldr, &VerifyOptions{TrustedKeys: trustedKeys})
+}
+
+// NewVerifying creates a loader that only returns the content of ldr when it passes the checks
+// in options. At least one digest or trusted key is required.
+func NewVerifying(ldr Loader, options *VerifyOptions) (*Verifying, error) {
+	if ldr == nil {
+		return nil, fmt.Errorf("%w: loader is nil", ErrScriptNotAvailable)
+	}
+	if options == nil || (len(options.Digests) == 0 && len(options.TrustedKeys) == 0) {
+		return nil, errors.New("at least one digest or trusted key is required")
+	}
+
+	digests := make([]string, 0, len(options.Digests))
+	for _, digest := range options.Digests {
+		normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(digest), "sha256:"))
+		decoded, err := hex.DecodeString(normalized)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA256 digest %q", digest)
+		}
+		digests = append(digests, normalized)
+	}
+
+	for i, key := range options.TrustedKeys {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf(
+				"trusted key %d has %d bytes, expected %d",
+				i,
+				len(key),
+				ed25519.PublicKeySize,
+			)
+		}
+	}
+
+	signature := options.Signature
+	if signature == nil && len(options.TrustedKeys) > 0 {
+		var err error
+		signature, err = signatureLoader(ldr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Verifying{
+		loader:    ldr,
+		digests:   digests,
+		keys:      slices.Clone(options.TrustedKeys),
+		signature: signature,
+
+		customSignature: options.Signature,
+	}, nil
+}
+
+// Wrap implements Wrapper, and returns a loader that verifies the content of ldr with the same
+// digests and trusted keys. The signature is loaded from next to the new script, unless
+// VerifyOptions.Signature was set, in which case that signature is used.
+func (l *Verifying) Wrap(ldr Loader) (Loader, error) {
+	return NewVerifying(ldr, &VerifyOptions{
+		Digests:     l.digests,
+		TrustedKeys: l.keys,
+		Signature:   l.customSignature,
+	})
+}
+
+// signatureLoader returns a loader for the detached signature next to a script
+func signatureLoader(ldr Loader) (Loader, error) {
+	switch l := ldr.(type) {
+	case *FromDisk:
+		return NewFromDisk(l.sourceURL.Path + SignatureExtension)
+	case *FromFS:
+		return &FromFS{
+			fsys:      l.fsys,
+			path:      l.path + SignatureExtension,
+			sourceURL: &url.URL{Scheme: "fs", Path: "/" + l.path + SignatureExtension},
+		}, nil
+	case *FromHTTP:
+		// Each mirror serves its own copy of the signature, next to its copy of the script
+		mirrors := make([]string, 0, len(l.options.Mirrors))
+		for _, mirror := range l.options.Mirrors {
+			mirrorURL, err := url.Parse(mirror)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse mirror URL: %w", err)
+			}
+			mirrors = append(mirrors, signatureURL(mirrorURL).String())
+		}
+		return NewFromHTTPWithOptions(
+			signatureURL(l.sourceURL).String(),
+			l.options.WithMirrors(mirrors...),
+		)
+	case *FromS3:
+		// The signature is a separate object, so a version ID of the script doesn't apply
+		sigURL := &url.URL{Scheme: "s3", Host: l.bucket, Path: "/" + l.key + SignatureExtension}
+		return NewFromS3WithOptions(sigURL.String(), l.options.WithVersionID(""))
+	default:
+		return nil, fmt.Errorf(
+			"no detached signature location for %T, set VerifyOptions.Signature",
+			ldr,
+		)
+	}
+}
+
+// signatureURL returns the URL of the detached signature next to the script at scriptURL
+func signatureURL(scriptURL *url.URL) *url.URL {
+	sigURL := *scriptURL
+	sigURL.Path += SignatureExtension
+	sigURL.RawPath = ""
+	return &sigURL
+}
+
+func (l *Verifying) String() string {
+	return fmt.Sprintf("loader.Verifying{Loader: %v}", l.loader)
+}
+
+// GetReader reads the content from the wrapped loader, and returns it only when it passes
+// verification. Otherwise the error wraps ErrVerificationFailed.
+func (l *Verifying) GetReader() (io.ReadCloser, error) {
+	content, err := readAll(l.loader)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.verify(content); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// verify checks the content against the pinned digests, then the signature
+func (l *Verifying) verify(content []byte) error {
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	if slices.Contains(l.digests, digest) {
+		return nil
+	}
+	if len(l.keys) == 0 {
+		return fmt.Errorf("%w: digest sha256:%s is not pinned", ErrVerificationFailed, digest)
+	}
+
+	signature, err := l.readSignature()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+	}
+	for _, key := range l.keys {
+		if ed25519.Verify(key, content, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"%w: signature is not valid for any trusted key (digest sha256:%s)",
+		ErrVerificationFailed,
+		digest,
+	)
+}
+
+// readSignature loads the detached signature, decoding it from base64 when it isn't raw
+func (l *Verifying) readSignature() ([]byte, error) {
+	signature, err := readAll(l.signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signature: %w", err)
+	}
+	if len(signature) == ed25519.SignatureSize {
+		return signature, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil || len(decoded) != ed25519.SignatureSize {
+		return nil, errors.New("signature is not a raw or base64 encoded ed25519 signature")
+	}
+	return decoded, nil
+}
+
+// GetSourceURL returns the source URL of the wrapped loader.
+func (l *Verifying) GetSourceURL() *url.URL {
+	return l.loader.GetSourceURL()
+}
+
+// GetVersion returns the version of the wrapped loader, when it's versioned. The version
+// doesn't say whether the content is approved: that's checked when it's read.
+func (l *Verifying) GetVersion(ctx context.Context) (string, error) {
+	versioned, ok := l.loader.(Versioned)
+	if !ok {
+		return "", fmt.Errorf("%w: %T", ErrNotVersioned, l.loader)
+	}
+	return versioned.GetVersion(ctx)
+}
+
+// readAll reads the whole content of a loader
+func readAll(ldr Loader) ([]byte, error) {
+	reader, err := ldr.GetReader()
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(reader)
+	if closeErr := reader.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,435 @@
+package loader
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const verifiedScript = `print("approved")`
+
+// newTestKey creates an ed25519 key pair for signing test scripts
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return pub, priv
+}
+
+// readVerified reads the content of a verifying loader
+func readVerified(t *testing.T, ldr Loader) (string, error) {
+	t.Helper()
+	reader, err := ldr.GetReader()
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	return string(content), nil
+}
+
+func TestNewPinned(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte(verifiedScript))
+	digest := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name    string
+		digests []string
+		wantErr bool
+	}{
+		{name: "matching digest", digests: []string{digest}},
+		{name: "prefixed upper case digest", digests: []string{"sha256:" + strings.ToUpper(digest)}},
+		{name: "one of several digests", digests: []string{strings.Repeat("a", 64), digest}},
+		{name: "different digest", digests: []string{strings.Repeat("a", 64)}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ldr, err := NewFromBytes([]byte(verifiedScript))
+			require.NoError(t, err)
+			pinned, err := NewPinned(ldr, tc.digests...)
+			require.NoError(t, err)
+			assert.Equal(t, ldr.GetSourceURL(), pinned.GetSourceURL())
+
+			content, err := readVerified(t, pinned)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrVerificationFailed)
+				require.ErrorContains(t, err, "sha256:"+digest+" is not pinned")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, verifiedScript, content)
+		})
+	}
+}
+
+func TestNewSigned(t *testing.T) {
+	t.Parallel()
+
+	pub, priv := newTestKey(t)
+	otherPub, otherPriv := newTestKey(t)
+	signature := ed25519.Sign(priv, []byte(verifiedScript))
+
+	t.Run("fs signatures", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name      string
+			content   string
+			signature []byte
+			keys      []ed25519.PublicKey
+			errMsg    string
+		}{
+			{
+				name:      "raw signature",
+				content:   verifiedScript,
+				signature: signature,
+				keys:      []ed25519.PublicKey{pub},
+			},
+			{
+				name:      "base64 signature",
+				content:   verifiedScript,
+				signature: []byte(base64.StdEncoding.EncodeToString(signature) + "\n"),
+				keys:      []ed25519.PublicKey{pub},
+			},
+			{
+				name:      "one of several keys",
+				content:   verifiedScript,
+				signature: ed25519.Sign(otherPriv, []byte(verifiedScript)),
+				keys:      []ed25519.PublicKey{pub, otherPub},
+			},
+			{
+				name:      "untrusted key",
+				content:   verifiedScript,
+				signature: ed25519.Sign(otherPriv, []byte(verifiedScript)),
+				keys:      []ed25519.PublicKey{pub},
+				errMsg:    "signature is not valid for any trusted key",
+			},
+			{
+				name:      "tampered content",
+				content:   verifiedScript + "\nprint('injected')",
+				signature: signature,
+				keys:      []ed25519.PublicKey{pub},
+				errMsg:    "signature is not valid for any trusted key",
+			},
+			{
+				name:    "missing signature",
+				content: verifiedScript,
+				keys:    []ed25519.PublicKey{pub},
+				errMsg:  "failed to load signature",
+			},
+			{
+				name:      "malformed signature",
+				content:   verifiedScript,
+				signature: []byte("not a signature"),
+				keys:      []ed25519.PublicKey{pub},
+				errMsg:    "not a raw or base64 encoded ed25519 signature",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+				fsys := fstest.MapFS{"rules/main.star": {Data: []byte(tc.content)}}
+				if tc.signature != nil {
+					fsys["rules/main.star.sig"] = &fstest.MapFile{Data: tc.signature}
+				}
+
+				ldr, err := NewFromFS(fsys, "rules/main.star")
+				require.NoError(t, err)
+				signed, err := NewSigned(ldr, tc.keys...)
+				require.NoError(t, err)
+
+				content, err := readVerified(t, signed)
+				if tc.errMsg != "" {
+					require.ErrorIs(t, err, ErrVerificationFailed)
+					require.ErrorContains(t, err, tc.errMsg)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tc.content, content)
+			})
+		}
+	})
+
+	t.Run("disk signature", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "main.risor")
+		require.NoError(t, os.WriteFile(path, []byte(verifiedScript), 0o600))
+		require.NoError(t, os.WriteFile(path+SignatureExtension, signature, 0o600))
+
+		ldr, err := NewFromDisk(path)
+		require.NoError(t, err)
+		signed, err := NewSigned(ldr, pub)
+		require.NoError(t, err)
+
+		content, err := readVerified(t, signed)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedScript, content)
+
+		// The content is verified each time it's read
+		require.NoError(t, os.WriteFile(path, []byte("print('changed')"), 0o600))
+		_, err = readVerified(t, signed)
+		require.ErrorIs(t, err, ErrVerificationFailed)
+	})
+
+	t.Run("HTTP signature", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch r.URL.Path {
+			case "/rules/main.risor":
+				_, err := w.Write([]byte(verifiedScript))
+				assert.NoError(t, err)
+			case "/rules/main.risor.sig":
+				_, err := w.Write([]byte(base64.StdEncoding.EncodeToString(signature)))
+				assert.NoError(t, err)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		ldr, err := NewFromHTTPWithOptions(
+			server.URL+"/rules/main.risor?v=2",
+			DefaultHTTPOptions().WithBearerAuth("token"),
+		)
+		require.NoError(t, err)
+		signed, err := NewSigned(ldr, pub)
+		require.NoError(t, err)
+
+		content, err := readVerified(t, signed)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedScript, content)
+	})
+
+	t.Run("HTTP signature from a mirror", func(t *testing.T) {
+		t.Parallel()
+		primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(primary.Close)
+		mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/copy/main.risor":
+				_, err := w.Write([]byte(verifiedScript))
+				assert.NoError(t, err)
+			case "/copy/main.risor.sig":
+				_, err := w.Write(signature)
+				assert.NoError(t, err)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(mirror.Close)
+
+		ldr, err := NewFromHTTPWithOptions(
+			primary.URL+"/rules/main.risor",
+			DefaultHTTPOptions().WithMirrors(mirror.URL+"/copy/main.risor"),
+		)
+		require.NoError(t, err)
+		signed, err := NewSigned(ldr, pub)
+		require.NoError(t, err)
+
+		content, err := readVerified(t, signed)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedScript, content)
+	})
+
+	t.Run("S3 signature", func(t *testing.T) {
+		t.Parallel()
+		server := newFakeS3(t)
+		version := server.put("scripts", "rules/main.risor", verifiedScript)
+		server.put("scripts", "rules/main.risor", "print('unreviewed')")
+		server.put("scripts", "rules/main.risor.sig", string(signature))
+
+		// The signature object is loaded without the script's version ID
+		ldr, err := NewFromS3WithOptions(
+			"s3://scripts/rules/main.risor?versionId="+version,
+			server.options(),
+		)
+		require.NoError(t, err)
+		signed, err := NewSigned(ldr, pub)
+		require.NoError(t, err)
+
+		content, err := readVerified(t, signed)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedScript, content)
+	})
+
+	t.Run("custom signature loader", func(t *testing.T) {
+		t.Parallel()
+		ldr, err := NewFromBytes([]byte(verifiedScript))
+		require.NoError(t, err)
+		sigLoader, err := NewFromBytes(signature)
+		require.NoError(t, err)
+
+		verifying, err := NewVerifying(ldr, &VerifyOptions{
+			TrustedKeys: []ed25519.PublicKey{pub},
+			Signature:   sigLoader,
+		})
+		require.NoError(t, err)
+
+		content, err := readVerified(t, verifying)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedScript, content)
+	})
+}
+
+func TestNewVerifying_Errors(t *testing.T) {
+	t.Parallel()
+
+	pub, _ := newTestKey(t)
+	ldr, err := NewFromBytes([]byte(verifiedScript))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ldr     Loader
+		options *VerifyOptions
+		errMsg  string
+	}{
+		{
+			name:    "nil loader",
+			options: &VerifyOptions{Digests: []string{strings.Repeat("a", 64)}},
+			errMsg:  "loader is nil",
+		},
+		{name: "nil options", ldr: ldr, errMsg: "at least one digest or trusted key"},
+		{
+			name:    "empty options",
+			ldr:     ldr,
+			options: &VerifyOptions{},
+			errMsg:  "at least one digest or trusted key",
+		},
+		{
+			name:    "short digest",
+			ldr:     ldr,
+			options: &VerifyOptions{Digests: []string{"abc123"}},
+			errMsg:  `invalid SHA256 digest "abc123"`,
+		},
+		{
+			name:    "invalid key",
+			ldr:     ldr,
+			options: &VerifyOptions{TrustedKeys: []ed25519.PublicKey{pub[:16]}},
+			errMsg:  "trusted key 0 has 16 bytes",
+		},
+		{
+			name:    "no signature location",
+			ldr:     ldr,
+			options: &VerifyOptions{TrustedKeys: []ed25519.PublicKey{pub}},
+			errMsg:  "no detached signature location for *loader.FromBytes",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			verifying, err := NewVerifying(tc.ldr, tc.options)
+			require.ErrorContains(t, err, tc.errMsg)
+			require.Nil(t, verifying)
+		})
+	}
+}
+
+func TestVerifying_Wrap(t *testing.T) {
+	t.Parallel()
+	pub, priv := newTestKey(t)
+
+	// writeSigned writes a script to dir, and a signature for it when signed is true
+	writeSigned := func(t *testing.T, dir, content string, signed bool) Loader {
+		t.Helper()
+		path := filepath.Join(dir, "main.risor")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		if signed {
+			signature := ed25519.Sign(priv, []byte(content))
+			require.NoError(t, os.WriteFile(path+SignatureExtension, signature, 0o600))
+		}
+		ldr, err := NewFromDisk(path)
+		require.NoError(t, err)
+		return ldr
+	}
+
+	signed, err := NewSigned(writeSigned(t, t.TempDir(), verifiedScript, true), pub)
+	require.NoError(t, err)
+	var wrapper Wrapper = signed
+
+	t.Run("signature next to the new script", func(t *testing.T) {
+		t.Parallel()
+		wrapped, err := wrapper.Wrap(writeSigned(t, t.TempDir(), "print('v2')", true))
+		require.NoError(t, err)
+
+		content, err := readVerified(t, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, "print('v2')", content)
+	})
+
+	t.Run("unsigned new script", func(t *testing.T) {
+		t.Parallel()
+		wrapped, err := wrapper.Wrap(writeSigned(t, t.TempDir(), "print('v2')", false))
+		require.NoError(t, err)
+
+		_, err = readVerified(t, wrapped)
+		require.ErrorIs(t, err, ErrVerificationFailed)
+	})
+
+	t.Run("pinned digests", func(t *testing.T) {
+		t.Parallel()
+		sum := sha256.Sum256([]byte(verifiedScript))
+		pinned, err := NewPinned(writeSigned(t, t.TempDir(), verifiedScript, false), hex.EncodeToString(sum[:]))
+		require.NoError(t, err)
+
+		wrapped, err := pinned.Wrap(writeSigned(t, t.TempDir(), verifiedScript, false))
+		require.NoError(t, err)
+		_, err = readVerified(t, wrapped)
+		require.NoError(t, err)
+
+		wrapped, err = pinned.Wrap(writeSigned(t, t.TempDir(), "print('v2')", true))
+		require.NoError(t, err)
+		_, err = readVerified(t, wrapped)
+		require.ErrorIs(t, err, ErrVerificationFailed)
+	})
+}
+
+func TestVerifying_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	digest := strings.Repeat("a", 64)
+
+	ldr, err := NewFromBytes([]byte(verifiedScript))
+	require.NoError(t, err)
+	pinned, err := NewPinned(ldr, digest)
+	require.NoError(t, err)
+
+	version, err := pinned.GetVersion(t.Context())
+	require.NoError(t, err)
+	want, err := ldr.GetVersion(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, version)
+	assert.Contains(t, pinned.String(), "loader.Verifying{Loader: loader.FromBytes")
+
+	mockLoader := NewMockLoaderWithContent([]byte(verifiedScript))
+	pinnedMock, err := NewPinned(mockLoader, digest)
+	require.NoError(t, err)
+	_, err = pinnedMock.GetVersion(t.Context())
+	require.ErrorIs(t, err, ErrNotVersioned)
+}
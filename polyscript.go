@@ -1,7 +1,11 @@
 // Package polyscript provides a unified interface for executing scripts in different language runtimes.
 //
 // This package supports these "engine" types:
+//   - CEL: Common Expression Language, for fast side-effect free expressions
+//   - Expr: expr-lang expressions, for lightweight data transformations
 //   - Extism: WebAssembly modules
+//   - JavaScript: ECMAScript 5.1+ scripts, run by goja
+//   - Lua: Lua 5.1 scripts, run by gopher-lua
 //   - Risor: Risor scripting language
 //   - Starlark: Starlark configuration language
 //
@@ -14,32 +18,236 @@
 package polyscript
 
 import (
+	"fmt"
 	"log/slog"
 
+	celMachine "github.com/robbyt/go-polyscript/engines/cel"
+	celCompiler "github.com/robbyt/go-polyscript/engines/cel/compiler"
+	exprMachine "github.com/robbyt/go-polyscript/engines/expr"
+	exprCompiler "github.com/robbyt/go-polyscript/engines/expr/compiler"
 	extismMachine "github.com/robbyt/go-polyscript/engines/extism"
+	extismCompiler "github.com/robbyt/go-polyscript/engines/extism/compiler"
+	javascriptMachine "github.com/robbyt/go-polyscript/engines/javascript"
+	javascriptCompiler "github.com/robbyt/go-polyscript/engines/javascript/compiler"
+	luaMachine "github.com/robbyt/go-polyscript/engines/lua"
+	luaCompiler "github.com/robbyt/go-polyscript/engines/lua/compiler"
+	"github.com/robbyt/go-polyscript/engines/registry"
 	risorMachine "github.com/robbyt/go-polyscript/engines/risor"
+	risorCompiler "github.com/robbyt/go-polyscript/engines/risor/compiler"
 	starlarkMachine "github.com/robbyt/go-polyscript/engines/starlark"
+	starlarkCompiler "github.com/robbyt/go-polyscript/engines/starlark/compiler"
 	"github.com/robbyt/go-polyscript/platform"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
 	"github.com/robbyt/go-polyscript/platform/script/loader"
 )
 
+// FromCELFile creates a CEL evaluator from a .cel file containing a single expression.
+// Compiler options can declare typed variables, or require an output type.
+//
+// Example:
+//
+//	be, err := FromCELFile("path/to/policy.cel", slog.Default().Handler(),
+//		celCompiler.WithOutputType(cel.BoolType))
+//	result, err := be.Eval(context.Background())
+func FromCELFile(
+	filePath string,
+	logHandler slog.Handler,
+	opts ...celCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return celMachine.FromCELLoader(logHandler, l, opts...)
+}
+
+// FromCELFileWithData creates a CEL evaluator with both static and dynamic data capabilities.
+// To add runtime data, use the AddDataToContext method on the evaluator to add data to the context.
+//
+// Example:
+//
+//	staticData := map[string]any{"allowed": []any{"admin", "owner"}}
+//	be, err := FromCELFileWithData("path/to/policy.cel", staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"role": "admin"}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromCELFileWithData(
+	filePath string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...celCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return celMachine.FromCELLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromCELString creates a CEL evaluator from an expression string.
+//
+// Example:
+//
+//	expression := `ctx.request.Method == "GET"`
+//	be, err := FromCELString(expression, slog.Default().Handler())
+//	result, err := be.Eval(context.Background())
+func FromCELString(
+	content string,
+	logHandler slog.Handler,
+	opts ...celCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return celMachine.FromCELLoader(logHandler, l, opts...)
+}
+
+// FromCELStringWithData creates a CEL evaluator with both static and dynamic data capabilities.
+// To add runtime data, use the AddDataToContext method on the evaluator to add data to the context.
+//
+// Example:
+//
+//	expression := `ctx.role in ctx.allowed`
+//	staticData := map[string]any{"allowed": []any{"admin", "owner"}}
+//	be, err := FromCELStringWithData(expression, staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"role": "admin"}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromCELStringWithData(
+	script string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...celCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(script)
+	if err != nil {
+		return nil, err
+	}
+
+	return celMachine.FromCELLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromExprFile creates an expr evaluator from a .expr file containing a single expression.
+// Compiler options can declare typed variables, or add expr options such as custom functions.
+//
+// Example:
+//
+//	be, err := FromExprFile("path/to/pricing.expr", slog.Default().Handler(),
+//		exprCompiler.WithVariable("price", 0.0))
+//	result, err := be.Eval(context.Background())
+func FromExprFile(
+	filePath string,
+	logHandler slog.Handler,
+	opts ...exprCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return exprMachine.FromExprLoader(logHandler, l, opts...)
+}
+
+// FromExprFileWithData creates an expr evaluator with both static and dynamic data capabilities.
+// To add runtime data, use the AddDataToContext method on the evaluator to add data to the context.
+//
+// Example:
+//
+//	staticData := map[string]any{"discount": 0.1}
+//	be, err := FromExprFileWithData("path/to/pricing.expr", staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"price": 20.0}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromExprFileWithData(
+	filePath string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...exprCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return exprMachine.FromExprLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromExprString creates an expr evaluator from an expression string.
+//
+// Example:
+//
+//	expression := `ctx.price * ctx.quantity`
+//	be, err := FromExprString(expression, slog.Default().Handler())
+//	result, err := be.Eval(context.Background())
+func FromExprString(
+	content string,
+	logHandler slog.Handler,
+	opts ...exprCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return exprMachine.FromExprLoader(logHandler, l, opts...)
+}
+
+// FromExprStringWithData creates an expr evaluator with both static and dynamic data capabilities.
+// To add runtime data, use the AddDataToContext method on the evaluator to add data to the context.
+//
+// Example:
+//
+//	expression := `ctx.price * (1 - ctx.discount)`
+//	staticData := map[string]any{"discount": 0.1}
+//	be, err := FromExprStringWithData(expression, staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"price": 20.0}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromExprStringWithData(
+	script string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...exprCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(script)
+	if err != nil {
+		return nil, err
+	}
+
+	return exprMachine.FromExprLoaderWithData(logHandler, l, staticData, opts...)
+}
+
 // FromExtismFile creates an Extism evaluator from a WASM file.
+// Compiler options configure the plugin manifest, such as resource limits, static config
+// values, and the hosts the module can send HTTP requests to.
 //
 // Example:
 //
-//	be, err := FromExtismFile("path/to/module.wasm", slog.Default().Handler(), "process")
+//	be, err := FromExtismFile("path/to/module.wasm", slog.Default().Handler(), "process",
+//		extismCompiler.WithLimits(platform.Limits{MaxMemoryPages: 256}),
+//		extismCompiler.WithConfig(map[string]string{"region": "eu-west-1"}),
+//		extismCompiler.WithAllowedHosts("api.example.com"))
 //	result, err := be.Eval(context.Background())
 func FromExtismFile(
 	filePath string,
 	logHandler slog.Handler,
 	entryPoint string,
+	opts ...extismCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromDisk(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	return extismMachine.FromExtismLoader(logHandler, l, entryPoint)
+	return extismMachine.FromExtismLoader(logHandler, l, entryPoint, opts...)
 }
 
 // FromExtismFileWithData creates an Extism evaluator with both static and dynamic data capabilities.
@@ -58,13 +266,14 @@ func FromExtismFileWithData(
 	staticData map[string]any,
 	logHandler slog.Handler,
 	entryPoint string,
+	opts ...extismCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromDisk(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	return extismMachine.FromExtismLoaderWithData(logHandler, l, staticData, entryPoint)
+	return extismMachine.FromExtismLoaderWithData(logHandler, l, staticData, entryPoint, opts...)
 }
 
 // FromExtismBytes creates an Extism evaluator from WASM bytecode.
@@ -77,13 +286,14 @@ func FromExtismBytes(
 	wasmBytes []byte,
 	logHandler slog.Handler,
 	entryPoint string,
+	opts ...extismCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromBytes(wasmBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return extismMachine.FromExtismLoader(logHandler, l, entryPoint)
+	return extismMachine.FromExtismLoader(logHandler, l, entryPoint, opts...)
 }
 
 // FromExtismBytesWithData creates an Extism evaluator from WASM bytecode with both static and dynamic data capabilities.
@@ -103,31 +313,221 @@ func FromExtismBytesWithData(
 	staticData map[string]any,
 	logHandler slog.Handler,
 	entryPoint string,
+	opts ...extismCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromBytes(wasmBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return extismMachine.FromExtismLoaderWithData(logHandler, l, staticData, entryPoint)
+	return extismMachine.FromExtismLoaderWithData(logHandler, l, staticData, entryPoint, opts...)
+}
+
+// FromJavaScriptFile creates a JavaScript evaluator from a .js file.
+// Compiler options can enable strict mode, or set evaluation options such as a default timeout.
+//
+// Example:
+//
+//	be, err := FromJavaScriptFile("path/to/script.js", slog.Default().Handler())
+//	result, err := be.Eval(context.Background())
+func FromJavaScriptFile(
+	filePath string,
+	logHandler slog.Handler,
+	opts ...javascriptCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return javascriptMachine.FromJavaScriptLoader(logHandler, l, opts...)
+}
+
+// FromJavaScriptFileWithData creates a JavaScript evaluator with both static and dynamic data
+// capabilities. To add runtime data, use the AddDataToContext method on the evaluator to add data
+// to the context.
+//
+// Example:
+//
+//	staticData := map[string]any{"config": map[string]any{"greeting": "Hello"}}
+//	be, err := FromJavaScriptFileWithData("path/to/script.js", staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"name": "World"}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromJavaScriptFileWithData(
+	filePath string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...javascriptCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return javascriptMachine.FromJavaScriptLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromJavaScriptString creates a JavaScript evaluator from a script string.
+//
+// Example:
+//
+//	script := `"Hello, " + ctx.name`
+//	be, err := FromJavaScriptString(script, slog.Default().Handler())
+//	result, err := be.Eval(context.Background())
+func FromJavaScriptString(
+	content string,
+	logHandler slog.Handler,
+	opts ...javascriptCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return javascriptMachine.FromJavaScriptLoader(logHandler, l, opts...)
+}
+
+// FromJavaScriptStringWithData creates a JavaScript evaluator with both static and dynamic data
+// capabilities. To add runtime data, use the AddDataToContext method on the evaluator to add data
+// to the context.
+//
+// Example:
+//
+//	script := `ctx.config.greeting + ", " + ctx.name`
+//	staticData := map[string]any{"config": map[string]any{"greeting": "Hello"}}
+//	be, err := FromJavaScriptStringWithData(script, staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"name": "World"}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromJavaScriptStringWithData(
+	script string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...javascriptCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(script)
+	if err != nil {
+		return nil, err
+	}
+
+	return javascriptMachine.FromJavaScriptLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromLuaFile creates a Lua evaluator from a .lua file.
+// Compiler options can set evaluation options, such as a default timeout.
+//
+// Example:
+//
+//	be, err := FromLuaFile("path/to/script.lua", slog.Default().Handler())
+//	result, err := be.Eval(context.Background())
+func FromLuaFile(
+	filePath string,
+	logHandler slog.Handler,
+	opts ...luaCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return luaMachine.FromLuaLoader(logHandler, l, opts...)
+}
+
+// FromLuaFileWithData creates a Lua evaluator with both static and dynamic data
+// capabilities. To add runtime data, use the AddDataToContext method on the evaluator to add data
+// to the context.
+//
+// Example:
+//
+//	staticData := map[string]any{"config": map[string]any{"greeting": "Hello"}}
+//	be, err := FromLuaFileWithData("path/to/script.lua", staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"name": "World"}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromLuaFileWithData(
+	filePath string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...luaCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromDisk(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return luaMachine.FromLuaLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromLuaString creates a Lua evaluator from a script string.
+//
+// Example:
+//
+//	script := `return "Hello, " .. ctx.name`
+//	be, err := FromLuaString(script, slog.Default().Handler())
+//	result, err := be.Eval(context.Background())
+func FromLuaString(
+	content string,
+	logHandler slog.Handler,
+	opts ...luaCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return luaMachine.FromLuaLoader(logHandler, l, opts...)
+}
+
+// FromLuaStringWithData creates a Lua evaluator with both static and dynamic data
+// capabilities. To add runtime data, use the AddDataToContext method on the evaluator to add data
+// to the context.
+//
+// Example:
+//
+//	script := `return ctx.config.greeting .. ", " .. ctx.name`
+//	staticData := map[string]any{"config": map[string]any{"greeting": "Hello"}}
+//	be, err := FromLuaStringWithData(script, staticData, slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"name": "World"}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromLuaStringWithData(
+	script string,
+	staticData map[string]any,
+	logHandler slog.Handler,
+	opts ...luaCompiler.FunctionalOption,
+) (platform.Evaluator, error) {
+	l, err := loader.NewFromString(script)
+	if err != nil {
+		return nil, err
+	}
+
+	return luaMachine.FromLuaLoaderWithData(logHandler, l, staticData, opts...)
 }
 
 // FromRisorFile creates a Risor evaluator from a .risor file.
+// Compiler options can set resource limits, such as the maximum number of VM instructions.
 //
 // Example:
 //
-//	be, err := FromRisorFile("path/to/script.risor", slog.Default().Handler())
+//	be, err := FromRisorFile("path/to/script.risor", slog.Default().Handler(),
+//		risorCompiler.WithLimits(platform.Limits{MaxSteps: 100_000}))
 //	result, err := be.Eval(context.Background())
 func FromRisorFile(
 	filePath string,
 	logHandler slog.Handler,
+	opts ...risorCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromDisk(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	return risorMachine.FromRisorLoader(logHandler, l)
+	return risorMachine.FromRisorLoader(logHandler, l, opts...)
 }
 
 // FromRisorFileWithData creates a Risor evaluator with both static and dynamic data capabilities.
@@ -145,13 +545,14 @@ func FromRisorFileWithData(
 	filePath string,
 	staticData map[string]any,
 	logHandler slog.Handler,
+	opts ...risorCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromDisk(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	return risorMachine.FromRisorLoaderWithData(logHandler, l, staticData)
+	return risorMachine.FromRisorLoaderWithData(logHandler, l, staticData, opts...)
 }
 
 // FromRisorString creates a Risor evaluator from a script string.
@@ -164,13 +565,14 @@ func FromRisorFileWithData(
 func FromRisorString(
 	content string,
 	logHandler slog.Handler,
+	opts ...risorCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromString(content)
 	if err != nil {
 		return nil, err
 	}
 
-	return risorMachine.FromRisorLoader(logHandler, l)
+	return risorMachine.FromRisorLoader(logHandler, l, opts...)
 }
 
 // FromRisorStringWithData creates a Risor evaluator with both static and dynamic data capabilities.
@@ -189,31 +591,35 @@ func FromRisorStringWithData(
 	script string,
 	staticData map[string]any,
 	logHandler slog.Handler,
+	opts ...risorCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromString(script)
 	if err != nil {
 		return nil, err
 	}
 
-	return risorMachine.FromRisorLoaderWithData(logHandler, l, staticData)
+	return risorMachine.FromRisorLoaderWithData(logHandler, l, staticData, opts...)
 }
 
 // FromStarlarkFile creates a Starlark evaluator from a .star file.
+// Compiler options can set resource limits, such as the maximum number of execution steps.
 //
 // Example:
 //
-//	be, err := FromStarlarkFile("path/to/script.star", slog.Default().Handler())
+//	be, err := FromStarlarkFile("path/to/script.star", slog.Default().Handler(),
+//		starlarkCompiler.WithLimits(platform.Limits{MaxSteps: 100_000}))
 //	result, err := be.Eval(context.Background())
 func FromStarlarkFile(
 	filePath string,
 	logHandler slog.Handler,
+	opts ...starlarkCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromDisk(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	return starlarkMachine.FromStarlarkLoader(logHandler, l)
+	return starlarkMachine.FromStarlarkLoader(logHandler, l, opts...)
 }
 
 // FromStarlarkFileWithData creates a Starlark evaluator with both static and dynamic data capabilities.
@@ -231,13 +637,14 @@ func FromStarlarkFileWithData(
 	filePath string,
 	staticData map[string]any,
 	logHandler slog.Handler,
+	opts ...starlarkCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromDisk(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	return starlarkMachine.FromStarlarkLoaderWithData(logHandler, l, staticData)
+	return starlarkMachine.FromStarlarkLoaderWithData(logHandler, l, staticData, opts...)
 }
 
 // FromStarlarkString creates a Starlark evaluator from a script string.
@@ -250,13 +657,14 @@ func FromStarlarkFileWithData(
 func FromStarlarkString(
 	content string,
 	logHandler slog.Handler,
+	opts ...starlarkCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromString(content)
 	if err != nil {
 		return nil, err
 	}
 
-	return starlarkMachine.FromStarlarkLoader(logHandler, l)
+	return starlarkMachine.FromStarlarkLoader(logHandler, l, opts...)
 }
 
 // FromStarlarkStringWithData creates a Starlark evaluator with both static and dynamic data
@@ -276,11 +684,49 @@ func FromStarlarkStringWithData(
 	script string,
 	staticData map[string]any,
 	logHandler slog.Handler,
+	opts ...starlarkCompiler.FunctionalOption,
 ) (platform.Evaluator, error) {
 	l, err := loader.NewFromString(script)
 	if err != nil {
 		return nil, err
 	}
 
-	return starlarkMachine.FromStarlarkLoaderWithData(logHandler, l, staticData)
+	return starlarkMachine.FromStarlarkLoaderWithData(logHandler, l, staticData, opts...)
+}
+
+// FromBundleFile creates an evaluator from a script bundle: a tar.gz or zip archive holding the
+// scripts, and a manifest naming the engine, entry point, and default static data. See the
+// bundle package for the format.
+//
+// Example:
+//
+//	be, err := FromBundleFile("path/to/discount.tar.gz", slog.Default().Handler())
+//
+//	runtimeData := map[string]any{"order": order}
+//	ctx, err = be.AddDataToContext(context.Background(), runtimeData)
+//	result, err := be.Eval(ctx)
+func FromBundleFile(filePath string, logHandler slog.Handler) (platform.Evaluator, error) {
+	b, err := bundle.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromBundle(b, logHandler)
+}
+
+// FromBundle creates an evaluator from a script bundle that has already been read. The entry
+// point is compiled with the manifest's static data by the engine registered for the manifest's
+// engine, and Risor and Starlark scripts can import other scripts from the bundle. When the
+// bundle has an input schema, each evaluation checks the runtime data against it first.
+func FromBundle(b *bundle.Bundle, logHandler slog.Handler) (platform.Evaluator, error) {
+	if b == nil {
+		return nil, fmt.Errorf("bundle is nil")
+	}
+
+	evaluator, err := registry.NewBundleEvaluator(logHandler, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.WrapEvaluator(evaluator), nil
 }
@@ -1,6 +1,8 @@
 package polyscript_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,12 +13,23 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/cel-go/cel"
 	"github.com/robbyt/go-polyscript"
+	celCompiler "github.com/robbyt/go-polyscript/engines/cel/compiler"
+	exprCompiler "github.com/robbyt/go-polyscript/engines/expr/compiler"
+	extismCompiler "github.com/robbyt/go-polyscript/engines/extism/compiler"
+	"github.com/robbyt/go-polyscript/engines/extism/wasmdata"
+	javascriptCompiler "github.com/robbyt/go-polyscript/engines/javascript/compiler"
+	luaCompiler "github.com/robbyt/go-polyscript/engines/lua/compiler"
 	"github.com/robbyt/go-polyscript/engines/mocks"
+	risorCompiler "github.com/robbyt/go-polyscript/engines/risor/compiler"
+	starlarkCompiler "github.com/robbyt/go-polyscript/engines/starlark/compiler"
 	"github.com/robbyt/go-polyscript/engines/types"
 	"github.com/robbyt/go-polyscript/platform"
 	"github.com/robbyt/go-polyscript/platform/data"
+	"github.com/robbyt/go-polyscript/platform/script/bundle"
 	"github.com/robbyt/go-polyscript/platform/script/loader"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -88,6 +101,16 @@ func prepareAndEval(
 }
 
 // Test machine-specific evaluator creators
+// withoutOptions adapts a constructor that accepts compiler options to the signature used by
+// the table tests
+func withoutOptions[O any](
+	fn func(string, slog.Handler, ...O) (platform.Evaluator, error),
+) func(string, slog.Handler) (platform.Evaluator, error) {
+	return func(content string, handler slog.Handler) (platform.Evaluator, error) {
+		return fn(content, handler)
+	}
+}
+
 func TestMachineEvaluators(t *testing.T) {
 	t.Parallel()
 
@@ -100,14 +123,26 @@ func TestMachineEvaluators(t *testing.T) {
 		{
 			name:        "FromStarlarkString",
 			content:     `print("Hello, World!")`,
-			machineType: types.Starlark,
-			creator:     polyscript.FromStarlarkString,
+			machineType: starlarkCompiler.MachineType,
+			creator:     withoutOptions(polyscript.FromStarlarkString),
 		},
 		{
 			name:        "FromRisorString",
 			content:     `"Hello, World!"`,
-			machineType: types.Risor,
-			creator:     polyscript.FromRisorString,
+			machineType: risorCompiler.MachineType,
+			creator:     withoutOptions(polyscript.FromRisorString),
+		},
+		{
+			name:        "FromJavaScriptString",
+			content:     `"Hello, World!"`,
+			machineType: javascriptCompiler.MachineType,
+			creator:     withoutOptions(polyscript.FromJavaScriptString),
+		},
+		{
+			name:        "FromLuaString",
+			content:     `return "Hello, World!"`,
+			machineType: luaCompiler.MachineType,
+			creator:     withoutOptions(polyscript.FromLuaString),
 		},
 	}
 
@@ -135,28 +170,56 @@ func TestFromStringLoaders(t *testing.T) {
 		{
 			name:        "FromStarlarkString - Valid",
 			content:     `print("Hello, World!")`,
-			creator:     polyscript.FromStarlarkString,
+			creator:     withoutOptions(polyscript.FromStarlarkString),
 			logHandler:  nil,
 			expectError: false,
 		},
 		{
 			name:        "FromRisorString - Valid",
 			content:     `"Hello, World!"`,
-			creator:     polyscript.FromRisorString,
+			creator:     withoutOptions(polyscript.FromRisorString),
+			logHandler:  nil,
+			expectError: false,
+		},
+		{
+			name:        "FromJavaScriptString - Valid",
+			content:     `"Hello, World!"`,
+			creator:     withoutOptions(polyscript.FromJavaScriptString),
+			logHandler:  nil,
+			expectError: false,
+		},
+		{
+			name:        "FromLuaString - Valid",
+			content:     `return "Hello, World!"`,
+			creator:     withoutOptions(polyscript.FromLuaString),
 			logHandler:  nil,
 			expectError: false,
 		},
 		{
 			name:        "FromStarlarkString - Empty",
 			content:     "",
-			creator:     polyscript.FromStarlarkString,
+			creator:     withoutOptions(polyscript.FromStarlarkString),
 			logHandler:  nil,
 			expectError: true,
 		},
 		{
 			name:        "FromRisorString - Empty",
 			content:     "",
-			creator:     polyscript.FromRisorString,
+			creator:     withoutOptions(polyscript.FromRisorString),
+			logHandler:  nil,
+			expectError: true,
+		},
+		{
+			name:        "FromJavaScriptString - Empty",
+			content:     "",
+			creator:     withoutOptions(polyscript.FromJavaScriptString),
+			logHandler:  nil,
+			expectError: true,
+		},
+		{
+			name:        "FromLuaString - Empty",
+			content:     "",
+			creator:     withoutOptions(polyscript.FromLuaString),
 			logHandler:  nil,
 			expectError: true,
 		},
@@ -178,6 +241,206 @@ func TestFromStringLoaders(t *testing.T) {
 	}
 }
 
+func TestFromStringWithLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Starlark step limit", func(t *testing.T) {
+		content := `
+def spin():
+    total = 0
+    for i in range(100000):
+        total += i
+    return total
+
+_ = spin()
+`
+		evaluator, err := polyscript.FromStarlarkString(
+			content,
+			nil,
+			starlarkCompiler.WithLimits(platform.Limits{MaxSteps: 1000}),
+		)
+		require.NoError(t, err)
+
+		_, err = evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "too many steps")
+	})
+
+	t.Run("Risor step limit", func(t *testing.T) {
+		content := `
+			function sum(n) {
+				if (n == 0) { return 0 }
+				return n + sum(n - 1)
+			}
+			sum(200)
+		`
+		evaluator, err := polyscript.FromRisorString(
+			content,
+			nil,
+			risorCompiler.WithLimits(platform.Limits{MaxSteps: 100}),
+		)
+		require.NoError(t, err)
+
+		_, err = evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "step limit exceeded")
+	})
+
+	t.Run("invalid limits", func(t *testing.T) {
+		_, err := polyscript.FromStarlarkString(
+			`_ = 1`,
+			nil,
+			starlarkCompiler.WithLimits(platform.Limits{MaxStackDepth: -1}),
+		)
+		require.Error(t, err)
+	})
+}
+
+func TestFromStringWithDefaultTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Risor", func(t *testing.T) {
+		content := `
+			function fib(n) {
+				if (n < 2) { return n }
+				return fib(n - 1) + fib(n - 2)
+			}
+			fib(40)
+		`
+		evaluator, err := polyscript.FromRisorString(
+			content,
+			nil,
+			risorCompiler.WithEvalOptions(platform.WithDefaultTimeout(50*time.Millisecond)),
+		)
+		require.NoError(t, err)
+
+		_, err = evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("JavaScript", func(t *testing.T) {
+		evaluator, err := polyscript.FromJavaScriptString(
+			`while (true) {}`,
+			nil,
+			javascriptCompiler.WithEvalOptions(platform.WithDefaultTimeout(50*time.Millisecond)),
+		)
+		require.NoError(t, err)
+
+		_, err = evaluator.Eval(t.Context())
+		require.Error(t, err)
+		require.ErrorIs(t, err, platform.ErrTimeout)
+	})
+
+	t.Run("caller deadline is kept", func(t *testing.T) {
+		evaluator, err := polyscript.FromRisorString(
+			`1 + 1`,
+			nil,
+			risorCompiler.WithEvalOptions(platform.WithDefaultTimeout(time.Nanosecond)),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+		defer cancel()
+		result, err := evaluator.Eval(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.Interface())
+	})
+}
+
+func TestFromExtismBytesWithManifestOptions(t *testing.T) {
+	t.Parallel()
+
+	evaluator, err := polyscript.FromExtismBytes(
+		wasmdata.ConfigModule,
+		nil,
+		wasmdata.EntrypointGetGreeting,
+		extismCompiler.WithConfig(map[string]string{"greeting": "Hello from config"}),
+		extismCompiler.WithAllowedHosts("api.example.com"),
+		extismCompiler.WithTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	response, err := evaluator.Eval(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "Hello from config", response.Interface())
+}
+
+func TestFromCELString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		evaluator, err := polyscript.FromCELString(`1 + 1 == 2`, nil)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, true, result.Interface())
+	})
+
+	t.Run("with output type", func(t *testing.T) {
+		_, err := polyscript.FromCELString(`"text"`, nil, celCompiler.WithOutputType(cel.BoolType))
+		require.Error(t, err)
+	})
+
+	t.Run("with data", func(t *testing.T) {
+		evaluator, err := polyscript.FromCELStringWithData(
+			`count > 1`,
+			map[string]any{"count": 2},
+			nil,
+			celCompiler.WithVariable("count", cel.IntType),
+		)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, true, result.Interface())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := polyscript.FromCELString("", nil)
+		require.Error(t, err)
+	})
+}
+
+func TestFromExprString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		evaluator, err := polyscript.FromExprString(`{"total": 2 * 3}`, nil)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"total": 6}, result.Interface())
+	})
+
+	t.Run("undeclared variable", func(t *testing.T) {
+		_, err := polyscript.FromExprString(`price * 2`, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("with data", func(t *testing.T) {
+		evaluator, err := polyscript.FromExprStringWithData(
+			`price * (1 - ctx.discount)`,
+			map[string]any{"price": 20.0, "discount": 0.25},
+			nil,
+			exprCompiler.WithVariable("price", 0.0),
+		)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 15.0, result.Interface())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := polyscript.FromExprString("", nil)
+		require.Error(t, err)
+	})
+}
+
 func TestFromFileLoaders(t *testing.T) {
 	t.Parallel()
 
@@ -246,6 +509,106 @@ _ = result`
 		require.NoError(t, err)
 		require.NotNil(t, evaluator)
 	})
+
+	t.Run("FromJavaScriptFile - Valid", func(t *testing.T) {
+		jsPath := filepath.Join(tmpDir, "test.js")
+		jsContent := `({ message: "Hello from JavaScript!" })`
+		require.NoError(t, os.WriteFile(jsPath, []byte(jsContent), 0o644))
+
+		evaluator, err := polyscript.FromJavaScriptFile(jsPath, nil)
+		require.NoError(t, err)
+		require.NotNil(t, evaluator)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"message": "Hello from JavaScript!"}, result.Interface())
+	})
+
+	t.Run("FromJavaScriptFile - Invalid Path", func(t *testing.T) {
+		_, err := polyscript.FromJavaScriptFile("non-existent-file.js", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("FromJavaScriptFileWithData - Valid", func(t *testing.T) {
+		jsPath := filepath.Join(tmpDir, "greet.js")
+		require.NoError(t, os.WriteFile(jsPath, []byte(`"Hello, " + ctx.name`), 0o644))
+
+		staticData := map[string]any{"name": "static"}
+		evaluator, err := polyscript.FromJavaScriptFileWithData(jsPath, staticData, nil)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, "Hello, static", result.Interface())
+	})
+
+	t.Run("FromCELFile - Valid", func(t *testing.T) {
+		celPath := filepath.Join(tmpDir, "policy.cel")
+		require.NoError(t, os.WriteFile(celPath, []byte(`ctx.role in ctx.allowed`), 0o644))
+
+		staticData := map[string]any{"role": "admin", "allowed": []any{"admin"}}
+		evaluator, err := polyscript.FromCELFileWithData(celPath, staticData, nil)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, true, result.Interface())
+	})
+
+	t.Run("FromCELFile - Invalid Path", func(t *testing.T) {
+		_, err := polyscript.FromCELFile("non-existent-file.cel", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("FromExprFile - Valid", func(t *testing.T) {
+		exprPath := filepath.Join(tmpDir, "pricing.expr")
+		require.NoError(t, os.WriteFile(exprPath, []byte(`ctx.price * ctx.quantity`), 0o644))
+
+		staticData := map[string]any{"price": 2, "quantity": 3}
+		evaluator, err := polyscript.FromExprFileWithData(exprPath, staticData, nil)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 6, result.Interface())
+	})
+
+	t.Run("FromExprFile - Invalid Path", func(t *testing.T) {
+		_, err := polyscript.FromExprFile("non-existent-file.expr", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("FromLuaFile - Valid", func(t *testing.T) {
+		luaPath := filepath.Join(tmpDir, "test.lua")
+		luaContent := `return { message = "Hello from Lua!" }`
+		require.NoError(t, os.WriteFile(luaPath, []byte(luaContent), 0o644))
+
+		evaluator, err := polyscript.FromLuaFile(luaPath, nil)
+		require.NoError(t, err)
+		require.NotNil(t, evaluator)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"message": "Hello from Lua!"}, result.Interface())
+	})
+
+	t.Run("FromLuaFile - Invalid Path", func(t *testing.T) {
+		_, err := polyscript.FromLuaFile("non-existent-file.lua", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("FromLuaFileWithData - Valid", func(t *testing.T) {
+		luaPath := filepath.Join(tmpDir, "greet.lua")
+		require.NoError(t, os.WriteFile(luaPath, []byte(`return "Hello, " .. ctx.name`), 0o644))
+
+		staticData := map[string]any{"name": "static"}
+		evaluator, err := polyscript.FromLuaFileWithData(luaPath, staticData, nil)
+		require.NoError(t, err)
+
+		result, err := evaluator.Eval(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, "Hello, static", result.Interface())
+	})
 }
 
 func TestDataProviders(t *testing.T) {
@@ -646,3 +1009,110 @@ func TestFromStringLoader(t *testing.T) {
 		require.NotNil(t, l.GetSourceURL())
 	})
 }
+
+// buildBundle creates a zip archive holding the files, in the bundle format
+func buildBundle(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestFromBundle(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Risor bundle with an import and input schema", func(t *testing.T) {
+		t.Parallel()
+		archive := buildBundle(t, map[string]string{
+			"manifest.json": `{
+				"name": "discount",
+				"engine": "risor",
+				"entryPoint": "main.risor",
+				"staticData": {"rate": 0.25},
+				"inputSchema": "schema.json"
+			}`,
+			"main.risor": `
+let pricing = import("lib/pricing.risor")
+pricing.discount(ctx["amount"], ctx["rate"])
+`,
+			"lib/pricing.risor": `function discount(amount, rate) { return amount * rate }`,
+			"schema.json":       `{"type": "object", "required": ["amount"]}`,
+		})
+		path := filepath.Join(t.TempDir(), "discount.zip")
+		require.NoError(t, os.WriteFile(path, archive, 0o600))
+
+		evaluator, err := polyscript.FromBundleFile(path, nil)
+		require.NoError(t, err)
+
+		ctx, err := evaluator.AddDataToContext(t.Context(), map[string]any{"amount": 100.0})
+		require.NoError(t, err)
+		result, err := evaluator.Eval(ctx)
+		require.NoError(t, err)
+		assert.InDelta(t, 25.0, result.Interface(), 0.0001)
+
+		_, err = evaluator.Eval(t.Context())
+		require.ErrorIs(t, err, bundle.ErrInvalidInput)
+	})
+
+	t.Run("Starlark bundle with a load", func(t *testing.T) {
+		t.Parallel()
+		b, err := bundle.FromBytes(buildBundle(t, map[string]string{
+			"manifest.json": `{
+				"engine": "starlark",
+				"entryPoint": "main.star",
+				"staticData": {"greeting": "Hello"}
+			}`,
+			"main.star": `
+load("lib/format.star", "format")
+_ = format(ctx["greeting"], ctx["name"])
+`,
+			"lib/format.star": `
+def format(greeting, name):
+    return greeting + ", " + name
+`,
+		}))
+		require.NoError(t, err)
+
+		evaluator, err := polyscript.FromBundle(b, nil)
+		require.NoError(t, err)
+
+		ctx, err := evaluator.AddDataToContext(t.Context(), map[string]any{"name": "World"})
+		require.NoError(t, err)
+		result, err := evaluator.Eval(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, World", result.Interface())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := polyscript.FromBundle(nil, nil)
+		require.Error(t, err)
+
+		_, err = polyscript.FromBundleFile(filepath.Join(t.TempDir(), "missing.zip"), nil)
+		require.Error(t, err)
+
+		b, err := bundle.FromBytes(buildBundle(t, map[string]string{
+			"manifest.json": `{"engine": "starlark", "entryPoint": "main.star"}`,
+			"main.star":     `_ = undefined_name`,
+		}))
+		require.NoError(t, err)
+		_, err = polyscript.FromBundle(b, nil)
+		require.Error(t, err)
+
+		b, err = bundle.FromBytes(buildBundle(t, map[string]string{
+			"manifest.json": `{"engine": "extism", "entryPoint": "main.wasm"}`,
+			"main.wasm":     "",
+		}))
+		require.NoError(t, err)
+		_, err = polyscript.FromBundle(b, nil)
+		require.ErrorIs(t, err, bundle.ErrInvalidBundle)
+		require.ErrorContains(t, err, "must name a function")
+	})
+}